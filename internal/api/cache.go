@@ -0,0 +1,37 @@
+package api
+
+import (
+	"time"
+
+	"github.com/salmonumbrella/controld-cli/internal/cache"
+)
+
+// ResponseCacheName is the cache store holding API responses.
+const ResponseCacheName = "responses"
+
+// diskCache adapts a cache.Store to controld.ResponseCache so responses are
+// shared between CLI invocations. Cache errors are ignored: a failed read is a
+// miss and a failed write only costs a later request. With bypass set every
+// read misses, but fresh responses are still stored and mutations still
+// invalidate entries, so the cache never goes stale because of it.
+type diskCache struct {
+	store  *cache.Store
+	bypass bool
+}
+
+func (c diskCache) Get(key string, maxAge time.Duration) ([]byte, bool) {
+	if c.bypass {
+		return nil, false
+	}
+	var body []byte
+	ok, err := c.store.Get(key, maxAge, &body)
+	return body, ok && err == nil
+}
+
+func (c diskCache) Set(key string, body []byte) {
+	_ = c.store.Set(key, body)
+}
+
+func (c diskCache) DeleteFunc(match func(key string) bool) {
+	_ = c.store.DeleteFunc(match)
+}
@@ -3,8 +3,11 @@ package api
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"os"
+	"strings"
 
+	"github.com/salmonumbrella/controld-cli/internal/cache"
 	"github.com/salmonumbrella/controld-cli/internal/config"
 	"github.com/salmonumbrella/controld-cli/internal/controld"
 	"github.com/salmonumbrella/controld-cli/internal/debug"
@@ -14,23 +17,77 @@ import (
 type ClientConfig struct {
 	Token   string
 	Account string
+	// BaseURL overrides the API base URL. When empty, CONTROLD_BASE_URL is
+	// used if set.
+	BaseURL string
+	// NoCache bypasses the on-disk response cache: responses are always
+	// fetched, then stored for later commands.
+	NoCache bool
+	// Options are applied after the options derived from the config.
+	Options []controld.Option
 }
 
 func NewClient(ctx context.Context, cfg ClientConfig) (*controld.API, error) {
-	token := resolveToken(cfg)
+	token := ResolveToken(cfg)
 	if token == "" {
 		return nil, fmt.Errorf("no API token found. Set %s or run: controld auth login", config.EnvToken)
 	}
 
 	opts := []controld.Option{}
-	if debug.IsDebug(ctx) {
-		opts = append(opts, controld.Debug(true))
+	if level := debug.LevelFromContext(ctx); level != debug.LevelOff {
+		opts = append(opts,
+			controld.DebugDump(dumpLevel(level)),
+			controld.UsingLogger(slog.NewLogLogger(slog.Default().Handler(), slog.LevelDebug)),
+		)
 	}
+	if store, err := cache.Open(ResponseCacheName); err == nil {
+		opts = append(opts, controld.UsingResponseCache(diskCache{store: store, bypass: cfg.NoCache}))
+	}
+	if keys := redactKeys(); len(keys) > 0 {
+		opts = append(opts, controld.RedactKeys(keys...))
+	}
+	if baseURL := resolveBaseURL(cfg); baseURL != "" {
+		opts = append(opts, controld.BaseURL(baseURL))
+	}
+	opts = append(opts, cfg.Options...)
 
 	return controld.New(token, opts...)
 }
 
-func resolveToken(cfg ClientConfig) string {
+func dumpLevel(level debug.Level) controld.DumpLevel {
+	switch level {
+	case debug.LevelHeaders:
+		return controld.DumpHeaders
+	case debug.LevelBody:
+		return controld.DumpBody
+	default:
+		return controld.DumpAll
+	}
+}
+
+// redactKeys returns the extra JSON keys to mask in debug output, from a
+// comma-separated CONTROLD_REDACT_KEYS.
+func redactKeys() []string {
+	var keys []string
+	for _, k := range strings.Split(os.Getenv(config.EnvRedactKeys), ",") {
+		if k = strings.TrimSpace(k); k != "" {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+func resolveBaseURL(cfg ClientConfig) string {
+	if cfg.BaseURL != "" {
+		return cfg.BaseURL
+	}
+	return os.Getenv(config.EnvBaseURL)
+}
+
+// ResolveToken returns the API token NewClient uses: the token in cfg, then the
+// environment, then the keyring entry for cfg.Account, then the only keyring
+// entry. It returns "" when none is found.
+func ResolveToken(cfg ClientConfig) string {
 	// 1. Explicit token flag
 	if cfg.Token != "" {
 		return cfg.Token
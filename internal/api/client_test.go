@@ -15,7 +15,7 @@ func TestResolveToken(t *testing.T) {
 			Token: "flag-token",
 		}
 
-		assert.Equal(t, "flag-token", resolveToken(cfg))
+		assert.Equal(t, "flag-token", ResolveToken(cfg))
 	})
 
 	t.Run("env var used when no flag", func(t *testing.T) {
@@ -23,7 +23,7 @@ func TestResolveToken(t *testing.T) {
 
 		cfg := ClientConfig{}
 
-		assert.Equal(t, "env-token", resolveToken(cfg))
+		assert.Equal(t, "env-token", ResolveToken(cfg))
 	})
 
 	t.Run("flag takes precedence over keyring", func(t *testing.T) {
@@ -32,7 +32,7 @@ func TestResolveToken(t *testing.T) {
 			Token: "flag-token",
 		}
 
-		assert.Equal(t, "flag-token", resolveToken(cfg))
+		assert.Equal(t, "flag-token", ResolveToken(cfg))
 	})
 
 	t.Run("env var takes precedence over keyring", func(t *testing.T) {
@@ -41,7 +41,7 @@ func TestResolveToken(t *testing.T) {
 		// Even if keyring has a token, env should win
 		cfg := ClientConfig{}
 
-		assert.Equal(t, "env-token", resolveToken(cfg))
+		assert.Equal(t, "env-token", ResolveToken(cfg))
 	})
 }
 
@@ -251,23 +251,25 @@ func (s *SetupServer) handleSetup(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func (s *SetupServer) validateCredentials(ctx context.Context, apiToken string) error {
+// validateCredentials checks apiToken against the API and returns the user it
+// belongs to.
+func (s *SetupServer) validateCredentials(ctx context.Context, apiToken string) (controld.User, error) {
 	if apiToken == "" {
-		return fmt.Errorf("API token is required")
+		return controld.User{}, fmt.Errorf("API token is required")
 	}
 
 	client, err := controld.New(apiToken)
 	if err != nil {
-		return fmt.Errorf("failed to create client: %v", err)
+		return controld.User{}, fmt.Errorf("failed to create client: %v", err)
 	}
 
-	// Test the connection by listing profiles
-	_, err = client.ListProfiles(ctx)
+	// Test the connection by fetching the account
+	user, err := client.ListUser(ctx)
 	if err != nil {
-		return fmt.Errorf("connection failed: %v", err)
+		return controld.User{}, fmt.Errorf("connection failed: %v", err)
 	}
 
-	return nil
+	return user, nil
 }
 
 func (s *SetupServer) handleValidate(w http.ResponseWriter, r *http.Request) {
@@ -322,7 +324,7 @@ func (s *SetupServer) handleValidate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := s.validateCredentials(r.Context(), req.APIToken); err != nil {
+	if _, err := s.validateCredentials(r.Context(), req.APIToken); err != nil {
 		writeJSON(w, http.StatusOK, map[string]any{
 			"success": false,
 			"error":   err.Error(),
@@ -388,7 +390,8 @@ func (s *SetupServer) handleSubmit(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := s.validateCredentials(r.Context(), req.APIToken); err != nil {
+	user, err := s.validateCredentials(r.Context(), req.APIToken)
+	if err != nil {
 		writeJSON(w, http.StatusOK, map[string]any{
 			"success": false,
 			"error":   err.Error(),
@@ -397,7 +400,12 @@ func (s *SetupServer) handleSubmit(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Save to keychain
-	err := s.store.Set(req.AccountName, req.APIToken)
+	err = s.store.Save(secrets.Credentials{
+		Name:      req.AccountName,
+		Token:     req.APIToken,
+		Email:     user.Email,
+		AccountPK: user.PK,
+	})
 	if err != nil {
 		slog.Error("failed to save credentials", "error", err)
 		writeJSON(w, http.StatusOK, map[string]any{
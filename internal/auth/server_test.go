@@ -34,6 +34,10 @@ func (m *mockStore) Set(name, token string) error {
 	return nil
 }
 
+func (m *mockStore) Save(creds secrets.Credentials) error {
+	return m.Set(creds.Name, creds.Token)
+}
+
 func (m *mockStore) Get(name string) (secrets.Credentials, error) {
 	if m.getErr != nil {
 		return secrets.Credentials{}, m.getErr
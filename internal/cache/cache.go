@@ -0,0 +1,149 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/salmonumbrella/controld-cli/internal/config"
+)
+
+// Store is a small file-backed cache of JSON values. Each key is stored in its
+// own file so concurrent CLI invocations never corrupt each other's entries.
+type Store struct {
+	dir string
+	now func() time.Time
+}
+
+type entry struct {
+	Key      string          `json:"key,omitempty"`
+	StoredAt time.Time       `json:"stored_at"`
+	Value    json.RawMessage `json:"value"`
+}
+
+// Open returns the store named name under the CLI config directory.
+func Open(name string) (*Store, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config directory: %w", err)
+	}
+	return New(filepath.Join(dir, "cache", name)), nil
+}
+
+// New returns a store rooted at dir.
+func New(dir string) *Store {
+	return &Store{dir: dir, now: time.Now}
+}
+
+// Get decodes the value stored under key into v. It reports false when there
+// is no entry or the entry is older than maxAge.
+func (s *Store) Get(key string, maxAge time.Duration, v any) (bool, error) {
+	data, err := os.ReadFile(s.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		// A corrupt entry is treated as a miss and overwritten on the next Set.
+		return false, nil
+	}
+	if s.now().Sub(e.StoredAt) > maxAge {
+		return false, nil
+	}
+	if err := json.Unmarshal(e.Value, v); err != nil {
+		return false, nil
+	}
+	return true, nil
+}
+
+// Set stores v under key.
+func (s *Store) Set(key string, v any) error {
+	value, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(entry{Key: key, StoredAt: s.now().UTC(), Value: value})
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return err
+	}
+
+	// Write to a temporary file and rename so readers never see a partial entry.
+	tmp, err := os.CreateTemp(s.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path(key))
+}
+
+// Delete removes the entry stored under key.
+func (s *Store) Delete(key string) error {
+	err := os.Remove(s.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// DeleteFunc removes every entry whose key match reports true for. Entries
+// written before keys were recorded are left alone.
+func (s *Store) DeleteFunc(match func(key string) bool) error {
+	files, err := os.ReadDir(s.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, f := range files {
+		if f.IsDir() || filepath.Ext(f.Name()) != ".json" {
+			continue
+		}
+		name := filepath.Join(s.dir, f.Name())
+		data, err := os.ReadFile(name)
+		if err != nil {
+			continue
+		}
+		var e entry
+		if err := json.Unmarshal(data, &e); err != nil || e.Key == "" || !match(e.Key) {
+			continue
+		}
+		if err := os.Remove(name); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Clear removes every entry in the store.
+func (s *Store) Clear() error {
+	return os.RemoveAll(s.dir)
+}
+
+func (s *Store) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:16])+".json")
+}
@@ -0,0 +1,121 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestStore(t *testing.T) (*Store, *time.Time) {
+	t.Helper()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	s := New(filepath.Join(t.TempDir(), "cache"))
+	s.now = func() time.Time { return now }
+	return s, &now
+}
+
+func TestGetMissing(t *testing.T) {
+	s, _ := newTestStore(t)
+
+	var v []string
+	ok, err := s.Get("missing", time.Minute, &v)
+
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestSetAndGet(t *testing.T) {
+	s, _ := newTestStore(t)
+
+	require.NoError(t, s.Set("key", []string{"a", "b"}))
+
+	var v []string
+	ok, err := s.Get("key", time.Minute, &v)
+
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"a", "b"}, v)
+}
+
+func TestGetExpired(t *testing.T) {
+	s, now := newTestStore(t)
+	require.NoError(t, s.Set("key", "value"))
+
+	*now = now.Add(2 * time.Minute)
+
+	var v string
+	ok, err := s.Get("key", time.Minute, &v)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// A longer max age still accepts the stale entry.
+	ok, err = s.Get("key", time.Hour, &v)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "value", v)
+}
+
+func TestGetCorruptEntry(t *testing.T) {
+	s, _ := newTestStore(t)
+	require.NoError(t, os.MkdirAll(s.dir, 0700))
+	require.NoError(t, os.WriteFile(s.path("key"), []byte("{"), 0600))
+
+	var v string
+	ok, err := s.Get("key", time.Minute, &v)
+
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestDeleteAndClear(t *testing.T) {
+	s, _ := newTestStore(t)
+	require.NoError(t, s.Set("a", 1))
+	require.NoError(t, s.Set("b", 2))
+
+	require.NoError(t, s.Delete("a"))
+	require.NoError(t, s.Delete("a"))
+
+	var v int
+	ok, _ := s.Get("a", time.Minute, &v)
+	assert.False(t, ok)
+	ok, _ = s.Get("b", time.Minute, &v)
+	assert.True(t, ok)
+
+	require.NoError(t, s.Clear())
+	ok, _ = s.Get("b", time.Minute, &v)
+	assert.False(t, ok)
+}
+
+func TestKeysDoNotCollide(t *testing.T) {
+	s, _ := newTestStore(t)
+
+	assert.NotEqual(t, s.path("profiles"), s.path("devices"))
+	assert.Equal(t, s.path("profiles"), s.path("profiles"))
+}
+
+func TestDeleteFunc(t *testing.T) {
+	s, _ := newTestStore(t)
+	require.NoError(t, s.Set("acct /profiles", 1))
+	require.NoError(t, s.Set("acct /profiles/p1/services", 2))
+	require.NoError(t, s.Set("acct /devices", 3))
+
+	require.NoError(t, s.DeleteFunc(func(key string) bool {
+		return strings.HasPrefix(key, "acct /profiles")
+	}))
+
+	var v int
+	ok, _ := s.Get("acct /profiles", time.Minute, &v)
+	assert.False(t, ok)
+	ok, _ = s.Get("acct /profiles/p1/services", time.Minute, &v)
+	assert.False(t, ok)
+	ok, _ = s.Get("acct /devices", time.Minute, &v)
+	assert.True(t, ok)
+
+	// Deleting from a store that was never written is not an error.
+	assert.NoError(t, New(filepath.Join(t.TempDir(), "none")).DeleteFunc(func(string) bool { return true }))
+}
@@ -1,9 +1,14 @@
 package cmd
 
 import (
+	"bufio"
 	"fmt"
+	"io"
 	"net"
+	"net/netip"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -20,77 +25,187 @@ func newAccessCmd() *cobra.Command {
 	cmd.AddCommand(newAccessListCmd())
 	cmd.AddCommand(newAccessAddCmd())
 	cmd.AddCommand(newAccessDeleteCmd())
+	cmd.AddCommand(newAccessPruneCmd())
 	return cmd
 }
 
 func newAccessListCmd() *cobra.Command {
 	return &cobra.Command{
-		Use:   "list <device-id>",
-		Short: "List known IPs for a device",
-		Args:  cobra.ExactArgs(1),
+		Use:               "list <device-id>",
+		Short:             "List known IPs for a device",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeArgs(completeDevices),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			client, err := getClient(cmd.Context())
 			if err != nil {
 				return err
 			}
 
-			ips, err := client.ListKnownIPs(cmd.Context(), controld.ListKnownIPsParams{
-				DeviceID: args[0],
-			})
+			deviceID, err := resolveDeviceID(cmd.Context(), client, args[0])
 			if err != nil {
 				return err
 			}
 
-			if outfmt.IsJSON(cmd.Context()) {
-				return outfmt.WriteJSON(os.Stdout, ips)
+			ips, err := client.ListKnownIPs(cmd.Context(), controld.ListKnownIPsParams{
+				DeviceID: deviceID,
+			})
+			if err != nil {
+				return err
 			}
 
-			if len(ips) == 0 {
-				fmt.Println("No known IPs")
-				return nil
-			}
+			return outfmt.Write(cmd.Context(), os.Stdout, ips, knownIPTable(ips))
+		},
+	}
+}
 
-			tw := outfmt.NewTabWriter(os.Stdout)
-			_, _ = fmt.Fprintln(tw, "IP\tCOUNTRY\tCITY\tISP")
-			for _, ip := range ips {
-				_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", ip.IP, ip.Country, ip.City, ip.ISP)
-			}
-			return tw.Flush()
+func knownIPTable(ips []controld.KnownIP) *outfmt.Table {
+	t := &outfmt.Table{
+		Columns: []outfmt.Column{
+			{Name: "ip"},
+			{Name: "country"},
+			{Name: "city"},
+			{Name: "isp"},
+			{Name: "asn"},
+			{Name: "last_seen"},
 		},
+		Empty: "No known IPs",
+	}
+	for _, ip := range ips {
+		t.AddRow(ip.IP.String(), ip.Country, ip.City, ip.ISP, formatASN(ip), ip.Ts.Format("2006-01-02"))
 	}
+	return t
 }
 
+// maxCIDRAddresses caps how many addresses a single CIDR may expand to.
+const maxCIDRAddresses = 256
+
+// parseIPs parses IP addresses and CIDR ranges. CIDRs are expanded to their
+// individual addresses because the API only accepts single IPs. Duplicates are
+// dropped.
 func parseIPs(args []string) ([]net.IP, error) {
 	var ips []net.IP
+	seen := make(map[netip.Addr]bool)
+	add := func(addr netip.Addr) {
+		if !seen[addr] {
+			seen[addr] = true
+			ips = append(ips, net.IP(addr.AsSlice()))
+		}
+	}
+
 	for _, ipStr := range args {
-		ip := net.ParseIP(ipStr)
-		if ip == nil {
+		if strings.Contains(ipStr, "/") {
+			prefix, err := netip.ParsePrefix(ipStr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid CIDR: %s", ipStr)
+			}
+			prefix = prefix.Masked()
+			if prefix.Addr().BitLen()-prefix.Bits() > 8 {
+				return nil, fmt.Errorf("CIDR %s is too large: at most %d addresses are allowed", ipStr, maxCIDRAddresses)
+			}
+			for addr := prefix.Addr(); prefix.Contains(addr); addr = addr.Next() {
+				add(addr)
+			}
+			continue
+		}
+
+		addr, err := netip.ParseAddr(ipStr)
+		if err != nil {
 			return nil, fmt.Errorf("invalid IP: %s", ipStr)
 		}
-		ips = append(ips, ip)
+		add(addr.Unmap())
 	}
 	return ips, nil
 }
 
+// readIPFile reads IPs and CIDRs from path, one per line. Blank lines and
+// lines starting with # are ignored. A path of "-" reads from stdin.
+func readIPFile(path string) ([]string, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = f.Close() }()
+		r = f
+	}
+
+	var out []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		if line == "" {
+			continue
+		}
+		out = append(out, strings.Fields(line)...)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func newAccessAddCmd() *cobra.Command {
-	return &cobra.Command{
-		Use:   "add <device-id> <ip>...",
+	var fromFile string
+	var current bool
+
+	cmd := &cobra.Command{
+		Use:   "add <device-id> [<ip|cidr>...]",
 		Short: "Add known IPs to a device",
-		Args:  cobra.MinimumNArgs(2),
+		Long: `Add known IPs to a device.
+
+IPs may be given as single addresses or CIDR ranges of up to 256 addresses.
+Use --from-file to read them from a file (one per line, # comments allowed,
+"-" for stdin) and --current to add the IP you are connecting from.`,
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: completeArgs(completeDevices),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			u := ui.FromContext(cmd.Context())
+
+			refs := args[1:]
+			if fromFile != "" {
+				lines, err := readIPFile(fromFile)
+				if err != nil {
+					return fmt.Errorf("failed to read %s: %w", fromFile, err)
+				}
+				refs = append(refs, lines...)
+			}
+			if len(refs) == 0 && !current {
+				return fmt.Errorf("no IPs given: pass IPs or CIDRs, --from-file or --current")
+			}
+
+			ips, err := parseIPs(refs)
+			if err != nil {
+				return err
+			}
+
 			client, err := getClient(cmd.Context())
 			if err != nil {
 				return err
 			}
 
-			ips, err := parseIPs(args[1:])
+			if current {
+				ip, err := client.ListIP(cmd.Context())
+				if err != nil {
+					return fmt.Errorf("failed to detect current IP: %w", err)
+				}
+				extra, err := parseIPs([]string{ip.IP.String()})
+				if err != nil {
+					return err
+				}
+				ips = append(ips, extra...)
+			}
+
+			deviceID, err := resolveDeviceID(cmd.Context(), client, args[0])
 			if err != nil {
 				return err
 			}
 
 			_, err = client.LearnNewIPs(cmd.Context(), controld.LearnNewIPsParams{
-				DeviceID: args[0],
+				DeviceID: deviceID,
 				IPs:      ips,
 			})
 			if err != nil {
@@ -101,13 +216,18 @@ func newAccessAddCmd() *cobra.Command {
 			return nil
 		},
 	}
+
+	cmd.Flags().StringVar(&fromFile, "from-file", "", "Read IPs and CIDRs from a file, one per line (- for stdin)")
+	cmd.Flags().BoolVar(&current, "current", false, "Add the IP this machine is connecting from")
+	return cmd
 }
 
 func newAccessDeleteCmd() *cobra.Command {
 	return &cobra.Command{
-		Use:   "delete <device-id> <ip>...",
-		Short: "Delete known IPs from a device",
-		Args:  cobra.MinimumNArgs(2),
+		Use:               "delete <device-id> <ip|cidr>...",
+		Short:             "Delete known IPs from a device",
+		Args:              cobra.MinimumNArgs(2),
+		ValidArgsFunction: completeArgs(completeDevices),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			u := ui.FromContext(cmd.Context())
 			client, err := getClient(cmd.Context())
@@ -120,8 +240,13 @@ func newAccessDeleteCmd() *cobra.Command {
 				return err
 			}
 
+			device, err := resolveDeviceStrict(cmd.Context(), client, args[0])
+			if err != nil {
+				return err
+			}
+
 			_, err = client.DeleteLearnedIPs(cmd.Context(), controld.DeleteLearnedIPsParams{
-				DeviceID: args[0],
+				DeviceID: device.DeviceID,
 				IPs:      ips,
 			})
 			if err != nil {
@@ -133,3 +258,187 @@ func newAccessDeleteCmd() *cobra.Command {
 		},
 	}
 }
+
+// countryFilter matches known IPs by country. A leading "!" negates the list,
+// so "!US,CA" matches every IP outside the US and Canada.
+type countryFilter struct {
+	negate    bool
+	countries []string
+}
+
+func parseCountryFilter(s string) (*countryFilter, error) {
+	f := &countryFilter{}
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "!") {
+		f.negate = true
+		s = s[1:]
+	}
+	for _, c := range strings.Split(s, ",") {
+		if c = strings.TrimSpace(c); c != "" {
+			f.countries = append(f.countries, c)
+		}
+	}
+	if len(f.countries) == 0 {
+		return nil, fmt.Errorf("invalid country filter: no countries given")
+	}
+	return f, nil
+}
+
+func (f *countryFilter) match(country string) bool {
+	for _, c := range f.countries {
+		if strings.EqualFold(c, country) {
+			return !f.negate
+		}
+	}
+	return f.negate
+}
+
+// selectPrunable returns the known IPs last seen before cutoff (when set) and
+// matching countries (when set). IPs with no last-seen time are never old
+// enough to prune.
+func selectPrunable(ips []controld.KnownIP, cutoff time.Time, countries *countryFilter) []controld.KnownIP {
+	var out []controld.KnownIP
+	for _, ip := range ips {
+		if !cutoff.IsZero() && (ip.Ts.IsZero() || !ip.Ts.Before(cutoff)) {
+			continue
+		}
+		if countries != nil && !countries.match(ip.Country) {
+			continue
+		}
+		out = append(out, ip)
+	}
+	return out
+}
+
+func newAccessPruneCmd() *cobra.Command {
+	var olderThan string
+	var country string
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "prune <device-id>",
+		Short: "Delete stale or unexpected known IPs",
+		Long: `Delete stale or unexpected known IPs from a device.
+
+--older-than selects IPs last seen before the given age (e.g. 30d, 12h).
+IPs with no last-seen time are kept.
+--country selects IPs by country; prefix the list with ! to select IPs
+outside it (e.g. --country '!US,CA'). When both are given an IP must match
+both to be pruned.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeArgs(completeDevices),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			u := ui.FromContext(cmd.Context())
+
+			if olderThan == "" && country == "" {
+				return fmt.Errorf("nothing to prune: pass --older-than and/or --country")
+			}
+
+			var cutoff time.Time
+			if olderThan != "" {
+				d, err := parseDuration(olderThan)
+				if err != nil {
+					return err
+				}
+				cutoff = time.Now().Add(-d)
+			}
+
+			var countries *countryFilter
+			if country != "" {
+				var err error
+				countries, err = parseCountryFilter(country)
+				if err != nil {
+					return err
+				}
+			}
+
+			client, err := getClient(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			device, err := resolveDeviceStrict(cmd.Context(), client, args[0])
+			if err != nil {
+				return err
+			}
+			deviceID := device.DeviceID
+
+			known, err := client.ListKnownIPs(cmd.Context(), controld.ListKnownIPsParams{
+				DeviceID: deviceID,
+			})
+			if err != nil {
+				return err
+			}
+
+			prune := selectPrunable(known, cutoff, countries)
+
+			if len(prune) == 0 {
+				if !outfmt.IsText(cmd.Context()) {
+					return outfmt.Write(cmd.Context(), os.Stdout, []controld.KnownIP{}, knownIPTable(nil))
+				}
+				u.Info("No known IPs match")
+				return nil
+			}
+
+			// The text preview goes to stderr, next to the confirmation prompt.
+			if outfmt.IsText(cmd.Context()) {
+				if err := outfmt.Write(cmd.Context(), os.Stderr, prune, knownIPTable(prune)); err != nil {
+					return err
+				}
+			}
+
+			if dryRun {
+				if !outfmt.IsText(cmd.Context()) {
+					return outfmt.Write(cmd.Context(), os.Stdout, prune, knownIPTable(prune))
+				}
+				u.Info(fmt.Sprintf("Would delete %d IP(s)", len(prune)))
+				return nil
+			}
+
+			if !outfmt.GetYes(cmd.Context()) {
+				_, _ = fmt.Fprintf(os.Stderr, "Delete %d IP(s)? [y/N]: ", len(prune))
+				var confirm string
+				_, _ = fmt.Scanln(&confirm)
+				if confirm != "y" && confirm != "Y" {
+					_, _ = fmt.Fprintln(os.Stderr, "Cancelled")
+					return nil
+				}
+			}
+
+			ips := make([]net.IP, len(prune))
+			for i, ip := range prune {
+				ips[i] = ip.IP
+			}
+
+			_, err = client.DeleteLearnedIPs(cmd.Context(), controld.DeleteLearnedIPsParams{
+				DeviceID: device.DeviceID,
+				IPs:      ips,
+			})
+			if err != nil {
+				return err
+			}
+
+			if !outfmt.IsText(cmd.Context()) {
+				return outfmt.Write(cmd.Context(), os.Stdout, prune, knownIPTable(prune))
+			}
+
+			u.Success(fmt.Sprintf("Deleted %d IP(s)", len(prune)))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&olderThan, "older-than", "", "Prune IPs last seen longer ago than this (e.g. 30d)")
+	cmd.Flags().StringVar(&country, "country", "", "Prune IPs from these countries, or outside them with a leading ! (e.g. '!US')")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be deleted without deleting")
+	return cmd
+}
+
+func formatASN(ip controld.KnownIP) string {
+	if ip.Asn == 0 {
+		return ip.AsName
+	}
+	if ip.AsName == "" {
+		return fmt.Sprintf("AS%d", ip.Asn)
+	}
+	return fmt.Sprintf("AS%d %s", ip.Asn, ip.AsName)
+}
@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/salmonumbrella/controld-cli/internal/controld"
+)
+
+func TestAccessAddListDelete(t *testing.T) {
+	srv := newTestServer(t)
+	device := srv.AddDevice("Laptop", srv.AddProfile("Home").PK)
+
+	_, err := runCmd(t, "access", "add", "laptop", "10.0.0.0/31", "192.0.2.1")
+	require.NoError(t, err)
+	assert.Len(t, srv.KnownIPs(device.DeviceID), 3)
+
+	out, err := runCmd(t, "access", "list", "laptop")
+	require.NoError(t, err)
+	assert.Contains(t, out, "10.0.0.1")
+	assert.Contains(t, out, "192.0.2.1")
+
+	_, err = runCmd(t, "--yes", "access", "delete", "laptop", "10.0.0.0/31")
+	require.NoError(t, err)
+	ips := srv.KnownIPs(device.DeviceID)
+	require.Len(t, ips, 1)
+	assert.Equal(t, "192.0.2.1", ips[0].IP.String())
+}
+
+func TestAccessPrune(t *testing.T) {
+	srv := newTestServer(t)
+	device := srv.AddDevice("Laptop", srv.AddProfile("Home").PK)
+	now := time.Now()
+	srv.AddKnownIP(device.DeviceID, controld.KnownIP{IP: net.ParseIP("1.1.1.1"), Country: "US", Ts: controld.UnixTime{Time: now.AddDate(0, 0, -90)}})
+	srv.AddKnownIP(device.DeviceID, controld.KnownIP{IP: net.ParseIP("2.2.2.2"), Country: "CA", Ts: controld.UnixTime{Time: now}})
+
+	_, err := runCmd(t, "access", "prune", "laptop", "--older-than", "30d", "--dry-run")
+	require.NoError(t, err)
+	assert.Len(t, srv.KnownIPs(device.DeviceID), 2)
+
+	_, err = runCmd(t, "--yes", "access", "prune", "laptop", "--older-than", "30d")
+	require.NoError(t, err)
+	ips := srv.KnownIPs(device.DeviceID)
+	require.Len(t, ips, 1)
+	assert.Equal(t, "2.2.2.2", ips[0].IP.String())
+}
+
+func TestParseIPs(t *testing.T) {
+	t.Run("single addresses", func(t *testing.T) {
+		ips, err := parseIPs([]string{"1.2.3.4", "2001:db8::1"})
+
+		require.NoError(t, err)
+		require.Len(t, ips, 2)
+		assert.Equal(t, "1.2.3.4", ips[0].String())
+		assert.Equal(t, "2001:db8::1", ips[1].String())
+	})
+
+	t.Run("expands CIDR", func(t *testing.T) {
+		ips, err := parseIPs([]string{"10.0.0.5/30"})
+
+		require.NoError(t, err)
+		var got []string
+		for _, ip := range ips {
+			got = append(got, ip.String())
+		}
+		assert.Equal(t, []string{"10.0.0.4", "10.0.0.5", "10.0.0.6", "10.0.0.7"}, got)
+	})
+
+	t.Run("allows /24", func(t *testing.T) {
+		ips, err := parseIPs([]string{"192.168.1.0/24"})
+
+		require.NoError(t, err)
+		assert.Len(t, ips, 256)
+	})
+
+	t.Run("rejects large CIDR", func(t *testing.T) {
+		_, err := parseIPs([]string{"10.0.0.0/16"})
+
+		assert.ErrorContains(t, err, "too large")
+	})
+
+	t.Run("drops duplicates", func(t *testing.T) {
+		ips, err := parseIPs([]string{"10.0.0.1", "10.0.0.0/31", "10.0.0.1"})
+
+		require.NoError(t, err)
+		assert.Len(t, ips, 2)
+	})
+
+	t.Run("rejects invalid input", func(t *testing.T) {
+		_, err := parseIPs([]string{"not-an-ip"})
+		assert.ErrorContains(t, err, "invalid IP")
+
+		_, err = parseIPs([]string{"10.0.0.0/99"})
+		assert.ErrorContains(t, err, "invalid CIDR")
+	})
+}
+
+func TestReadIPFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ips.txt")
+	content := "# office\n1.2.3.4\n\n10.0.0.0/30  # vpn\n5.6.7.8 9.9.9.9\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+
+	lines, err := readIPFile(path)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1.2.3.4", "10.0.0.0/30", "5.6.7.8", "9.9.9.9"}, lines)
+}
+
+func TestParseCountryFilter(t *testing.T) {
+	f, err := parseCountryFilter("US, ca")
+	require.NoError(t, err)
+	assert.True(t, f.match("US"))
+	assert.True(t, f.match("CA"))
+	assert.False(t, f.match("DE"))
+
+	f, err = parseCountryFilter("!US")
+	require.NoError(t, err)
+	assert.False(t, f.match("us"))
+	assert.True(t, f.match("DE"))
+
+	_, err = parseCountryFilter("!")
+	assert.Error(t, err)
+}
+
+func TestSelectPrunable(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	known := []controld.KnownIP{
+		{IP: net.ParseIP("1.1.1.1"), Country: "US", Ts: controld.UnixTime{Time: now.AddDate(0, 0, -60)}},
+		{IP: net.ParseIP("2.2.2.2"), Country: "DE", Ts: controld.UnixTime{Time: now.AddDate(0, 0, -60)}},
+		{IP: net.ParseIP("3.3.3.3"), Country: "DE", Ts: controld.UnixTime{Time: now.AddDate(0, 0, -1)}},
+		{IP: net.ParseIP("4.4.4.4"), Country: "DE"},
+	}
+	notUS, err := parseCountryFilter("!US")
+	require.NoError(t, err)
+
+	ipsOf := func(ips []controld.KnownIP) []string {
+		var out []string
+		for _, ip := range ips {
+			out = append(out, ip.IP.String())
+		}
+		return out
+	}
+
+	cutoff := now.AddDate(0, 0, -30)
+	assert.Equal(t, []string{"1.1.1.1", "2.2.2.2"}, ipsOf(selectPrunable(known, cutoff, nil)))
+	assert.Equal(t, []string{"2.2.2.2", "3.3.3.3", "4.4.4.4"}, ipsOf(selectPrunable(known, time.Time{}, notUS)))
+	assert.Equal(t, []string{"2.2.2.2"}, ipsOf(selectPrunable(known, cutoff, notUS)))
+}
@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+
+	"github.com/salmonumbrella/controld-cli/internal/controld"
+	"github.com/salmonumbrella/controld-cli/internal/outfmt"
+	"github.com/salmonumbrella/controld-cli/internal/secrets"
+	"github.com/salmonumbrella/controld-cli/internal/ui"
+)
+
+// maxParallelAccounts bounds how many accounts are queried at once.
+const maxParallelAccounts = 8
+
+// accountsFlags select the keyring accounts a read command fans out to.
+type accountsFlags struct {
+	all   bool
+	names []string
+}
+
+func (f *accountsFlags) register(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&f.all, "all-accounts", false, "Run for every account in the keyring")
+	cmd.Flags().StringSliceVar(&f.names, "accounts", nil, "Run for these keyring accounts (e.g. work,home)")
+	cmd.MarkFlagsMutuallyExclusive("all-accounts", "accounts")
+	_ = cmd.RegisterFlagCompletionFunc("accounts", completeAccountNames)
+}
+
+func (f *accountsFlags) enabled() bool {
+	return f.all || len(f.names) > 0
+}
+
+// accountResult is the outcome of a command for one account.
+type accountResult[T any] struct {
+	Account string `json:"account"`
+	Result  *T     `json:"result,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// forEachAccount runs fetch for every selected account concurrently and
+// writes the results: text, csv and tsv get one table with an ACCOUNT column
+// first, other formats a list with each account's result or error. Errors are
+// reported per account without stopping the others, and make the command
+// fail once every account has been written. fetch takes the client first so
+// method expressions such as (*controld.API).ListDevices can be passed.
+func forEachAccount[T any](cmd *cobra.Command, f *accountsFlags, fetch func(*controld.API, context.Context) (T, error), table func(T) *outfmt.Table) error {
+	ctx := cmd.Context()
+	if flags.Token != "" {
+		return fmt.Errorf("--token cannot be used with --all-accounts or --accounts")
+	}
+	creds, err := selectAccounts(f)
+	if err != nil {
+		return err
+	}
+
+	results := make([]accountResult[T], len(creds))
+	errs := make([]error, len(creds))
+	sem := make(chan struct{}, maxParallelAccounts)
+	var wg sync.WaitGroup
+	for i, c := range creds {
+		results[i].Account = c.Name
+		if c.Token == "" {
+			errs[i] = fmt.Errorf("account not found in keyring")
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			client, err := newClient(ctx, c.Token, c.Name, flags.NoCache)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			result, err := fetch(client, ctx)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			results[i].Result = &result
+		}()
+	}
+	wg.Wait()
+
+	var zero T
+	t := accountTable(table(zero))
+	failed := 0
+	for i, r := range results {
+		if errs[i] != nil {
+			results[i].Error = errs[i].Error()
+			failed++
+			continue
+		}
+		// The rows are not the items of results, so --fields paths are
+		// looked up in the item behind each row.
+		rows := table(*r.Result).Rows
+		items := outfmt.Elements(*r.Result)
+		for j, row := range rows {
+			t.AddRow(append([]string{r.Account}, row...)...)
+			var item any
+			if len(items) == len(rows) {
+				item = items[j]
+			}
+			t.Items = append(t.Items, item)
+		}
+	}
+
+	if err := outfmt.Write(ctx, os.Stdout, results, t); err != nil {
+		return err
+	}
+	if failed == 0 {
+		return nil
+	}
+	u := ui.FromContext(ctx)
+	for i, err := range errs {
+		if err != nil {
+			u.Error(fmt.Sprintf("%s: %v", creds[i].Name, err))
+		}
+	}
+	return fmt.Errorf("%d of %d accounts failed", failed, len(creds))
+}
+
+// accountTable returns an empty table with t's columns after an account
+// column. Detail tables become regular ones with a row per account.
+func accountTable(t *outfmt.Table) *outfmt.Table {
+	return &outfmt.Table{
+		Columns: append([]outfmt.Column{{Name: "account"}}, t.Columns...),
+		Empty:   t.Empty,
+		Items:   []any{},
+	}
+}
+
+// selectAccounts returns the credentials of the selected accounts, sorted by
+// name for --all-accounts and in the given order for --accounts. Named
+// accounts missing from the keyring are returned without a token.
+func selectAccounts(f *accountsFlags) ([]secrets.Credentials, error) {
+	store, err := openSecrets()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open keyring: %w", err)
+	}
+
+	if f.all {
+		creds, err := store.List()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list accounts: %w", err)
+		}
+		if len(creds) == 0 {
+			return nil, errors.New("no accounts configured. Run: controld auth login")
+		}
+		slices.SortFunc(creds, func(a, b secrets.Credentials) int { return strings.Compare(a.Name, b.Name) })
+		return creds, nil
+	}
+
+	creds := make([]secrets.Credentials, 0, len(f.names))
+	for _, name := range f.names {
+		c, err := store.Get(name)
+		if err != nil {
+			c = secrets.Credentials{Name: name}
+		}
+		creds = append(creds, c)
+	}
+	return creds, nil
+}
+
+// accountClient returns a client for the named keyring account.
+func accountClient(ctx context.Context, name string) (*controld.API, error) {
+	store, err := openSecrets()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open keyring: %w", err)
+	}
+	creds, err := store.Get(name)
+	if err != nil {
+		return nil, fmt.Errorf("account %q not found in keyring. Run: controld auth login --name %s", name, name)
+	}
+	return newClient(ctx, creds.Token, creds.Name, flags.NoCache)
+}
+
+func completeAccountNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	store, err := openSecrets()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	creds, err := store.List()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	names := make([]string, 0, len(creds))
+	for _, c := range creds {
+		names = append(names, c.Name)
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/salmonumbrella/controld-cli/internal/controld/controldsim"
+)
+
+func TestAllAccounts(t *testing.T) {
+	srv := newTestServer(t, controldsim.WithToken("good"))
+	srv.Seed()
+	useTestKeyring(t, map[string]string{"alpha": "good", "beta": "revoked"})
+
+	// A failing account is reported without hiding the others.
+	out, err := runCmd(t, "devices", "list", "--all-accounts")
+	assert.ErrorContains(t, err, "1 of 2 accounts failed")
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	require.Len(t, lines, 4)
+	assert.True(t, strings.HasPrefix(lines[0], "ACCOUNT"))
+	for _, line := range lines[1:] {
+		assert.True(t, strings.HasPrefix(line, "alpha "))
+	}
+
+	out, err = runCmd(t, "--output", "json", "profiles", "list", "--accounts", "alpha,beta,missing")
+	assert.ErrorContains(t, err, "2 of 3 accounts failed")
+	var results []struct {
+		Account string            `json:"account"`
+		Result  []json.RawMessage `json:"result"`
+		Error   string            `json:"error"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(out), &results))
+	require.Len(t, results, 3)
+	assert.Equal(t, "alpha", results[0].Account)
+	assert.Len(t, results[0].Result, 2)
+	assert.Empty(t, results[0].Error)
+	assert.NotEmpty(t, results[1].Error)
+	assert.Equal(t, "account not found in keyring", results[2].Error)
+
+	out, err = runCmd(t, "network", "ip", "--accounts", "alpha")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(out, "ACCOUNT"))
+	assert.Contains(t, out, "alpha")
+
+	_, err = runCmd(t, "--token", "good", "devices", "list", "--all-accounts")
+	assert.ErrorContains(t, err, "--token cannot be used")
+}
+
+func TestAllAccountsFields(t *testing.T) {
+	srv := newTestServer(t, controldsim.WithToken("good"))
+	srv.Seed()
+	useTestKeyring(t, map[string]string{"alpha": "good", "beta": "good"})
+	devices := srv.Devices()
+	require.NotEmpty(t, devices)
+
+	// Table columns and paths into each device both resolve per row.
+	out, err := runCmd(t, "devices", "list", "--all-accounts", "--fields", "account,name,profile.name", "--no-headers")
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	require.Len(t, lines, 2*len(devices))
+	for i, line := range lines {
+		account := "alpha"
+		if i >= len(devices) {
+			account = "beta"
+		}
+		d := devices[i%len(devices)]
+		assert.Equal(t, []string{account, d.Name, d.Profile.Name}, strings.Fields(line))
+	}
+
+	out, err = runCmd(t, "network", "ip", "--accounts", "alpha", "--fields", "account,ip", "--no-headers")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(out, "alpha "), out)
+}
@@ -0,0 +1,285 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/spf13/cobra"
+
+	"github.com/salmonumbrella/controld-cli/internal/config"
+	"github.com/salmonumbrella/controld-cli/internal/outfmt"
+	"github.com/salmonumbrella/controld-cli/internal/ui"
+)
+
+const (
+	// aliasAnnotation marks alias commands and holds their expansion.
+	aliasAnnotation = "alias"
+	aliasGroupID    = "aliases"
+)
+
+func newAliasCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "alias",
+		Short: "Manage command aliases",
+		Long: `Manage command aliases.
+
+An alias is a shortcut for a longer command line. It is invoked like any other
+command, and its arguments and flags are appended to the expansion:
+
+  $ controld alias set block 'profiles rules create --action block'
+  $ controld block Kids --hostname ads.example.com
+
+Aliases are stored in the configuration file and apply to every context. They
+cannot shadow built-in commands or expand to another alias.`,
+	}
+
+	cmd.AddCommand(newAliasSetCmd())
+	cmd.AddCommand(newAliasListCmd())
+	cmd.AddCommand(newAliasDeleteCmd())
+	return cmd
+}
+
+func newAliasSetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "set <name> <expansion>",
+		Short: "Create or change an alias",
+		Long: `Create or change an alias. The expansion is split into arguments like a
+shell would, so quote it as a whole and quote arguments containing spaces
+within it.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name, expansion := args[0], args[1]
+			if err := validateAlias(cmd.Root(), name, expansion); err != nil {
+				return err
+			}
+
+			file, err := config.Load()
+			if err != nil {
+				return err
+			}
+			if file.Aliases == nil {
+				file.Aliases = map[string]string{}
+			}
+			file.Aliases[name] = expansion
+			if err := file.Save(); err != nil {
+				return fmt.Errorf("failed to save config: %w", err)
+			}
+
+			ui.FromContext(cmd.Context()).Success(fmt.Sprintf("Alias %s expands to: controld %s", name, expansion))
+			return nil
+		},
+	}
+}
+
+func newAliasListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List aliases",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, err := config.Load()
+			if err != nil {
+				return err
+			}
+
+			type alias struct {
+				Name      string `json:"name"`
+				Expansion string `json:"expansion"`
+			}
+			aliases := make([]alias, 0, len(file.Aliases))
+			t := &outfmt.Table{
+				Columns: []outfmt.Column{{Name: "name"}, {Name: "expansion"}},
+				Empty:   "No aliases. Create one with: controld alias set <name> <expansion>",
+			}
+			for _, name := range file.AliasNames() {
+				aliases = append(aliases, alias{Name: name, Expansion: file.Aliases[name]})
+				t.AddRow(name, file.Aliases[name])
+			}
+			return outfmt.Write(cmd.Context(), os.Stdout, aliases, t)
+		},
+	}
+}
+
+func newAliasDeleteCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete <name>",
+		Short: "Delete an alias",
+		Args:  cobra.ExactArgs(1),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) > 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			file, err := config.Load()
+			if err != nil {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return file.AliasNames(), cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, err := config.Load()
+			if err != nil {
+				return err
+			}
+			if _, ok := file.Aliases[args[0]]; !ok {
+				return fmt.Errorf("alias %q not found", args[0])
+			}
+			delete(file.Aliases, args[0])
+			if err := file.Save(); err != nil {
+				return fmt.Errorf("failed to save config: %w", err)
+			}
+
+			ui.FromContext(cmd.Context()).Success(fmt.Sprintf("Deleted alias %s", args[0]))
+			return nil
+		},
+	}
+}
+
+// addAliases adds a command to root for each alias in the configuration file.
+// Invalid aliases are skipped with a warning. Errors loading the file are
+// left to loadContext to report.
+func addAliases(root *cobra.Command) {
+	file, err := config.Load()
+	if err != nil || len(file.Aliases) == 0 {
+		return
+	}
+
+	root.AddGroup(&cobra.Group{ID: aliasGroupID, Title: "Alias Commands:"})
+	for _, name := range file.AliasNames() {
+		expansion := file.Aliases[name]
+		if err := validateAlias(root, name, expansion); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "warning: ignoring alias %s: %v\n", name, err)
+			continue
+		}
+		root.AddCommand(newAliasRunCmd(name, expansion))
+	}
+}
+
+// validateAlias reports whether name can be an alias for expansion: it must
+// not shadow a built-in command, and expansion must start with one. Aliases
+// take precedence over plugins of the same name.
+func validateAlias(root *cobra.Command, name, expansion string) error {
+	if name == "" || strings.HasPrefix(name, "-") || strings.HasPrefix(name, "__") || strings.ContainsFunc(name, unicode.IsSpace) {
+		return fmt.Errorf("invalid alias name %q: must be a single word not starting with '-'", name)
+	}
+	if name == "help" {
+		return fmt.Errorf("alias %q would shadow the built-in command", name)
+	}
+	for _, c := range root.Commands() {
+		if !isAlias(c) && !isPlugin(c) && (c.Name() == name || c.HasAlias(name)) {
+			return fmt.Errorf("alias %q would shadow the built-in command", name)
+		}
+	}
+
+	words, err := splitWords(expansion)
+	if err != nil {
+		return err
+	}
+	if len(words) == 0 {
+		return fmt.Errorf("alias %q has an empty expansion", name)
+	}
+	target, _, err := root.Find(words)
+	if err != nil || target == root || isAlias(target) || isPlugin(target) {
+		return fmt.Errorf("expansion %q must start with a built-in command", expansion)
+	}
+	return nil
+}
+
+func isAlias(cmd *cobra.Command) bool {
+	_, ok := cmd.Annotations[aliasAnnotation]
+	return ok
+}
+
+// newAliasRunCmd returns the command that runs an alias. It takes its
+// arguments unparsed and runs a fresh command tree on the expansion followed
+// by them, so flags given before or after the alias name keep working.
+func newAliasRunCmd(name, expansion string) *cobra.Command {
+	return &cobra.Command{
+		Use:                name + " [args...]",
+		Short:              "Alias for: " + expansion,
+		Long:               fmt.Sprintf("Alias for: controld %s\n\nArguments and flags are appended to the expansion.", expansion),
+		GroupID:            aliasGroupID,
+		Annotations:        map[string]string{aliasAnnotation: expansion},
+		DisableFlagParsing: true,
+		// The expanded command runs the root hooks itself.
+		PersistentPreRunE: func(*cobra.Command, []string) error { return nil },
+		ValidArgsFunction: completeAlias,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			words, err := splitWords(expansion)
+			if err != nil {
+				return err
+			}
+			root := NewRootCmd()
+			root.SilenceErrors = true
+			root.SetArgs(append(words, args...))
+			root.SetOut(cmd.OutOrStdout())
+			root.SetErr(cmd.ErrOrStderr())
+			return root.ExecuteContext(cmd.Context())
+		},
+	}
+}
+
+// completeAlias completes an alias's arguments as the command it expands to
+// would.
+func completeAlias(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	words, err := splitWords(cmd.Annotations[aliasAnnotation])
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	target, rest, err := NewRootCmd().Find(words)
+	if err != nil || target.ValidArgsFunction == nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	if err := target.ParseFlags(append(rest, args...)); err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	target.SetContext(cmd.Context())
+	return target.ValidArgsFunction(target, target.Flags().Args(), toComplete)
+}
+
+// splitWords splits s into arguments like a POSIX shell, honoring single and
+// double quotes and backslash escapes.
+func splitWords(s string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+	escaped := false
+
+	for _, r := range s {
+		switch {
+		case escaped:
+			word.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inWord = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case unicode.IsSpace(r):
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 || escaped {
+		return nil, fmt.Errorf("unterminated quote or escape in %q", s)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAliases(t *testing.T) {
+	srv := newTestServer(t)
+	srv.Seed()
+
+	_, err := runCmd(t, "alias", "set", "block", "profiles rules create --action block")
+	require.NoError(t, err)
+
+	out, err := runCmd(t, "alias", "list")
+	require.NoError(t, err)
+	assert.Contains(t, out, "profiles rules create --action block")
+
+	// Flags before and after the alias name are passed on.
+	out, err = runCmd(t, "--output", "json", "block", "Kids", "--hostname", "ads.example")
+	require.NoError(t, err)
+	assert.Contains(t, out, `"do": 0`)
+
+	out, err = runCmd(t, "--help")
+	require.NoError(t, err)
+	assert.Contains(t, out, "Alias Commands:")
+
+	_, err = runCmd(t, "alias", "set", "devices", "profiles list")
+	assert.ErrorContains(t, err, "shadow the built-in command")
+	_, err = runCmd(t, "alias", "set", "loop", "block Kids")
+	assert.ErrorContains(t, err, "must start with a built-in command")
+
+	_, err = runCmd(t, "alias", "delete", "block")
+	require.NoError(t, err)
+	_, err = runCmd(t, "block", "Kids")
+	assert.ErrorContains(t, err, "unknown command")
+}
+
+func TestSplitWords(t *testing.T) {
+	words, err := splitWords(`rules create --comment "my rule" 'a b' c\ d`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"rules", "create", "--comment", "my rule", "a b", "c d"}, words)
+
+	_, err = splitWords(`create "open`)
+	assert.Error(t, err)
+}
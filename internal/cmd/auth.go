@@ -2,15 +2,21 @@ package cmd
 
 import (
 	"bufio"
+	"cmp"
+	"context"
 	"fmt"
 	"os"
+	"slices"
 	"strings"
+	"sync"
 	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 	"golang.org/x/term"
 
 	"github.com/salmonumbrella/controld-cli/internal/auth"
+	"github.com/salmonumbrella/controld-cli/internal/controld"
 	"github.com/salmonumbrella/controld-cli/internal/outfmt"
 	"github.com/salmonumbrella/controld-cli/internal/secrets"
 	"github.com/salmonumbrella/controld-cli/internal/ui"
@@ -25,6 +31,7 @@ func newAuthCmd() *cobra.Command {
 	cmd.AddCommand(newAuthLogoutCmd())
 	cmd.AddCommand(newAuthListCmd())
 	cmd.AddCommand(newAuthStatusCmd())
+	cmd.AddCommand(newAuthRotateCmd())
 	return cmd
 }
 
@@ -32,6 +39,7 @@ func newAuthLoginCmd() *cobra.Command {
 	var name string
 	var token string
 	var noBrowser bool
+	var meta tokenMetaFlags
 
 	cmd := &cobra.Command{
 		Use:   "login",
@@ -39,17 +47,22 @@ func newAuthLoginCmd() *cobra.Command {
 		Long: `Authenticate with ControlD API.
 
 By default, opens a browser window for interactive authentication.
-Use --no-browser for terminal-only authentication.`,
+Use --no-browser for terminal-only authentication.
+
+The token is checked with the API, and the email and PK of the account it
+belongs to are stored with it. In terminal auth, --label, --scope and --expires
+record the token's dashboard name, type and expiry; auth status and auth list
+warn as the expiry approaches.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			u := ui.FromContext(cmd.Context())
 
-			store, err := secrets.OpenDefault()
+			store, err := openSecrets()
 			if err != nil {
 				return fmt.Errorf("failed to open keyring: %w", err)
 			}
 
 			// Use browser flow if no flags provided and we're in a terminal
-			useBrowser := !noBrowser && token == "" && term.IsTerminal(int(syscall.Stdin))
+			useBrowser := !noBrowser && token == "" && meta == (tokenMetaFlags{}) && term.IsTerminal(int(syscall.Stdin))
 
 			if useBrowser {
 				u.Info("Opening browser for authentication...")
@@ -76,33 +89,24 @@ Use --no-browser for terminal-only authentication.`,
 			}
 
 			if token == "" {
-				fmt.Fprint(os.Stderr, "API Token: ")
-				if term.IsTerminal(int(syscall.Stdin)) {
-					tokenBytes, err := term.ReadPassword(int(syscall.Stdin))
-					if err != nil {
-						return fmt.Errorf("failed to read token: %w", err)
-					}
-					fmt.Fprintln(os.Stderr)
-					token = string(tokenBytes)
-				} else {
-					reader := bufio.NewReader(os.Stdin)
-					line, err := reader.ReadString('\n')
-					if err != nil {
-						return fmt.Errorf("failed to read token: %w", err)
-					}
-					token = strings.TrimSpace(line)
+				token, err = readToken()
+				if err != nil {
+					return err
 				}
 			}
 
-			if token == "" {
-				return fmt.Errorf("token is required")
+			creds := secrets.Credentials{Name: name, Token: token}
+			if err := meta.apply(&creds); err != nil {
+				return err
 			}
-
-			if err := store.Set(name, token); err != nil {
+			if _, err := identifyToken(cmd.Context(), &creds); err != nil {
+				return err
+			}
+			if err := store.Save(creds); err != nil {
 				return fmt.Errorf("failed to save credentials: %w", err)
 			}
 
-			u.Success(fmt.Sprintf("Authenticated as %s", name))
+			u.Success(fmt.Sprintf("Authenticated as %s (%s)", name, creds.Email))
 			return nil
 		},
 	}
@@ -110,9 +114,102 @@ Use --no-browser for terminal-only authentication.`,
 	cmd.Flags().StringVar(&name, "name", "", "Account name for terminal auth (default: default)")
 	cmd.Flags().StringVar(&token, "token", "", "API token (skips interactive prompt)")
 	cmd.Flags().BoolVar(&noBrowser, "no-browser", false, "Use terminal-only authentication")
+	meta.register(cmd)
 	return cmd
 }
 
+// readToken prompts for an API token on stderr and reads it from stdin,
+// without echo when stdin is a terminal.
+func readToken() (string, error) {
+	fmt.Fprint(os.Stderr, "API Token: ")
+	var token string
+	if term.IsTerminal(int(syscall.Stdin)) {
+		tokenBytes, err := term.ReadPassword(int(syscall.Stdin))
+		if err != nil {
+			return "", fmt.Errorf("failed to read token: %w", err)
+		}
+		fmt.Fprintln(os.Stderr)
+		token = string(tokenBytes)
+	} else {
+		reader := bufio.NewReader(os.Stdin)
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return "", fmt.Errorf("failed to read token: %w", err)
+		}
+		token = strings.TrimSpace(line)
+	}
+	if token == "" {
+		return "", fmt.Errorf("token is required")
+	}
+	return token, nil
+}
+
+// tokenMetaFlags set the metadata stored with a token.
+type tokenMetaFlags struct {
+	label   string
+	scope   string
+	expires string
+}
+
+func (f *tokenMetaFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.label, "label", "", "Token name in the ControlD dashboard")
+	cmd.Flags().StringVar(&f.scope, "scope", "", "Token type: read or write")
+	cmd.Flags().StringVar(&f.expires, "expires", "", "Token expiry as a date (2026-12-31) or from now (90d)")
+	_ = cmd.RegisterFlagCompletionFunc("scope", cobra.FixedCompletions([]string{"read", "write"}, cobra.ShellCompDirectiveNoFileComp))
+}
+
+// apply sets the metadata given on the command line in creds.
+func (f *tokenMetaFlags) apply(creds *secrets.Credentials) error {
+	if f.label != "" {
+		creds.Label = f.label
+	}
+	if f.scope != "" {
+		if f.scope != "read" && f.scope != "write" {
+			return fmt.Errorf("invalid scope %q: must be 'read' or 'write'", f.scope)
+		}
+		creds.Scope = f.scope
+	}
+	if f.expires != "" {
+		expires, err := parseExpiry(f.expires, time.Now())
+		if err != nil {
+			return err
+		}
+		creds.ExpiresAt = expires
+	}
+	return nil
+}
+
+// parseExpiry parses a date such as "2026-12-31" or a duration from now such
+// as "90d".
+func parseExpiry(s string, now time.Time) (time.Time, error) {
+	if date, err := time.ParseInLocation(time.DateOnly, s, time.Local); err == nil {
+		return date.UTC(), nil
+	}
+	d, err := parseDuration(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid expiry %q: use a date (2026-12-31) or a duration (90d)", s)
+	}
+	return now.Add(d).UTC(), nil
+}
+
+// identifyToken checks creds.Token with the API, records the user it belongs
+// to in creds and returns that user.
+func identifyToken(ctx context.Context, creds *secrets.Credentials) (controld.User, error) {
+	// A token revoked since the last command must not look valid because
+	// of a cached response.
+	client, err := newClient(ctx, creds.Token, creds.Name, true)
+	if err != nil {
+		return controld.User{}, err
+	}
+	user, err := client.ListUser(ctx)
+	if err != nil {
+		return controld.User{}, fmt.Errorf("token rejected by the API: %w", err)
+	}
+	creds.Email = user.Email
+	creds.AccountPK = user.PK
+	return user, nil
+}
+
 func newAuthLogoutCmd() *cobra.Command {
 	var name string
 
@@ -126,7 +223,7 @@ func newAuthLogoutCmd() *cobra.Command {
 				name = "default"
 			}
 
-			store, err := secrets.OpenDefault()
+			store, err := openSecrets()
 			if err != nil {
 				return fmt.Errorf("failed to open keyring: %w", err)
 			}
@@ -149,7 +246,7 @@ func newAuthListCmd() *cobra.Command {
 		Use:   "list",
 		Short: "List stored accounts",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			store, err := secrets.OpenDefault()
+			store, err := openSecrets()
 			if err != nil {
 				return fmt.Errorf("failed to open keyring: %w", err)
 			}
@@ -159,33 +256,70 @@ func newAuthListCmd() *cobra.Command {
 				return fmt.Errorf("failed to list accounts: %w", err)
 			}
 
-			if outfmt.IsJSON(cmd.Context()) {
-				return outfmt.WriteJSON(os.Stdout, creds)
-			}
-
-			if len(creds) == 0 {
-				fmt.Println("No accounts configured. Run: controld auth login")
-				return nil
+			t := credentialsTable(creds...)
+			t.Empty = "No accounts configured. Run: controld auth login"
+			if err := outfmt.Write(cmd.Context(), os.Stdout, creds, t); err != nil {
+				return err
 			}
+			warnCredentials(ui.FromContext(cmd.Context()), creds)
+			return nil
+		},
+	}
+}
 
-			tw := outfmt.NewTabWriter(os.Stdout)
-			_, _ = fmt.Fprintln(tw, "NAME\tCREATED")
-			for _, c := range creds {
-				_, _ = fmt.Fprintf(tw, "%s\t%s\n", c.Name, c.CreatedAt.Format("2006-01-02"))
-			}
-			return tw.Flush()
+// credentialsTable lists stored accounts, one row per account.
+func credentialsTable(creds ...secrets.Credentials) *outfmt.Table {
+	t := &outfmt.Table{
+		Columns: []outfmt.Column{
+			{Name: "name"},
+			{Name: "email"},
+			{Name: "label"},
+			{Name: "scope"},
+			{Name: "created"},
+			{Name: "expires"},
 		},
 	}
+	for _, c := range creds {
+		t.AddRow(c.Name, c.Email, c.Label, c.Scope, c.CreatedAt.Format("2006-01-02"), formatExpiry(c.ExpiresAt))
+	}
+	return t
+}
+
+// tokenStatus is what auth status reports for one keyring account.
+type tokenStatus struct {
+	Account string `json:"account"`
+	// Selected is set for the account commands use by default.
+	Selected bool `json:"selected"`
+	// Status is valid, expired or revoked, or error when the token could not
+	// be checked.
+	Status     string    `json:"status"`
+	Email      string    `json:"email,omitempty"`
+	AccountPK  string    `json:"account_pk,omitempty"`
+	TwoFA      bool      `json:"twofa"`
+	LastActive time.Time `json:"last_active,omitzero"`
+	// TokenType is read or write as recorded with the token, or unknown:
+	// the API does not report it.
+	TokenType string    `json:"token_type"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at,omitzero"`
+	Error     string    `json:"error,omitempty"`
 }
 
 func newAuthStatusCmd() *cobra.Command {
 	return &cobra.Command{
 		Use:   "status",
 		Short: "Show current authentication status",
+		Long: `Show current authentication status.
+
+Every keyring account's token is checked against the API, and the account's
+email, PK, 2FA status and last activity are shown. Tokens the API rejects are
+reported as expired or revoked. The command fails if the selected account
+(--account, or the only account) has a token that does not work.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			u := ui.FromContext(cmd.Context())
+			ctx := cmd.Context()
+			u := ui.FromContext(ctx)
 
-			store, err := secrets.OpenDefault()
+			store, err := openSecrets()
 			if err != nil {
 				return fmt.Errorf("failed to open keyring: %w", err)
 			}
@@ -199,12 +333,260 @@ func newAuthStatusCmd() *cobra.Command {
 				u.Warn("Not authenticated. Run: controld auth login")
 				return nil
 			}
+			slices.SortFunc(creds, func(a, b secrets.Credentials) int { return strings.Compare(a.Name, b.Name) })
+
+			// Keyring account names are stored lowercased.
+			selected := strings.ToLower(strings.TrimSpace(flags.Account))
+			if selected == "" && len(creds) == 1 {
+				selected = creds[0].Name
+			}
+
+			statuses := checkTokens(ctx, creds)
+			for i := range statuses {
+				statuses[i].Selected = statuses[i].Account == selected
+			}
+
+			t := &outfmt.Table{
+				Columns: []outfmt.Column{
+					{Name: "current"},
+					{Name: "account"},
+					{Name: "status", Status: true},
+					{Name: "email"},
+					{Name: "account_pk"},
+					{Name: "2fa"},
+					{Name: "last_active"},
+					{Name: "type"},
+					{Name: "created", Wide: true},
+					{Name: "expires", Wide: true},
+				},
+			}
+			for _, s := range statuses {
+				current, twofa, lastActive := "", "", ""
+				if s.Selected {
+					current = "*"
+				}
+				if s.Status == "valid" {
+					twofa = fmt.Sprint(s.TwoFA)
+				}
+				if !s.LastActive.IsZero() {
+					lastActive = s.LastActive.Local().Format("2006-01-02 15:04")
+				}
+				t.AddRow(current, s.Account, s.Status, s.Email, s.AccountPK, twofa, lastActive, s.TokenType,
+					s.CreatedAt.Format("2006-01-02"), formatExpiry(s.ExpiresAt))
+			}
+			if err := outfmt.Write(ctx, os.Stdout, statuses, t); err != nil {
+				return err
+			}
+
+			for _, s := range statuses {
+				if s.Error != "" {
+					u.Error(fmt.Sprintf("%s: %s", s.Account, s.Error))
+				}
+			}
+			warnCredentials(u, creds)
+			if selected == "" {
+				return nil
+			}
+			for _, s := range statuses {
+				if s.Selected {
+					if s.Status != "valid" {
+						return fmt.Errorf("token for account %s is %s. Run: controld auth rotate %s", s.Account, s.Status, s.Account)
+					}
+					return nil
+				}
+			}
+			return fmt.Errorf("account %q not found in keyring. Run: controld auth login --name %s", selected, selected)
+		},
+	}
+}
 
-			u.Success(fmt.Sprintf("Authenticated with %d account(s)", len(creds)))
-			for _, c := range creds {
-				fmt.Printf("  - %s (added %s)\n", c.Name, c.CreatedAt.Format("2006-01-02"))
+// checkTokens identifies every account's token concurrently. The
+// email and PK stored with a token are kept when the API rejects it.
+func checkTokens(ctx context.Context, creds []secrets.Credentials) []tokenStatus {
+	statuses := make([]tokenStatus, len(creds))
+	sem := make(chan struct{}, maxParallelAccounts)
+	var wg sync.WaitGroup
+	for i, c := range creds {
+		s := &statuses[i]
+		*s = tokenStatus{
+			Account:   c.Name,
+			Email:     c.Email,
+			AccountPK: c.AccountPK,
+			TokenType: cmp.Or(c.Scope, "unknown"),
+			CreatedAt: c.CreatedAt,
+			ExpiresAt: c.ExpiresAt,
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			user, err := identifyToken(ctx, &c)
+			s.Status = tokenState(err)
+			if err != nil {
+				s.Error = err.Error()
+				return
 			}
+			s.Email = user.Email
+			s.AccountPK = user.PK
+			s.TwoFA = bool(user.Twofa)
+			s.LastActive = user.LastActive.Time
+		}()
+	}
+	wg.Wait()
+	return statuses
+}
+
+// tokenState classifies the result of a call made with a token. The API
+// rejects expired and revoked tokens alike; its message tells them apart.
+func tokenState(err error) string {
+	switch {
+	case err == nil:
+		return "valid"
+	case !controld.IsAuth(err):
+		return "error"
+	case strings.Contains(strings.ToLower(err.Error()), "expired"):
+		return "expired"
+	default:
+		return "revoked"
+	}
+}
+
+func newAuthRotateCmd() *cobra.Command {
+	var token string
+	var force bool
+	var meta tokenMetaFlags
+
+	cmd := &cobra.Command{
+		Use:   "rotate <name>",
+		Short: "Replace an account's stored token",
+		Long: `Replace an account's stored token with a new one.
+
+The new token is checked with the API before anything changes and must belong
+to the same ControlD account as the old one, unless --force is given. The
+keyring entry is then replaced in a single write. The label and scope are kept
+unless new ones are given; the old token's expiry is dropped unless --expires
+is given.
+
+The old token keeps working until you revoke it in the ControlD dashboard.`,
+		Args: cobra.ExactArgs(1),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) > 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return completeAccountNames(cmd, args, toComplete)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			u := ui.FromContext(ctx)
+
+			store, err := openSecrets()
+			if err != nil {
+				return fmt.Errorf("failed to open keyring: %w", err)
+			}
+			old, err := store.Get(args[0])
+			if err != nil {
+				return fmt.Errorf("account %q not found in keyring. Run: controld auth login --name %s", args[0], args[0])
+			}
+
+			if token == "" {
+				token, err = readToken()
+				if err != nil {
+					return err
+				}
+			}
+			if token == old.Token {
+				return fmt.Errorf("the new token is the one already stored for %s", old.Name)
+			}
+
+			creds := secrets.Credentials{
+				Name:  old.Name,
+				Token: token,
+				Label: old.Label,
+				Scope: old.Scope,
+			}
+			if err := meta.apply(&creds); err != nil {
+				return err
+			}
+			if _, err := identifyToken(ctx, &creds); err != nil {
+				return err
+			}
+			if old.AccountPK != "" && creds.AccountPK != old.AccountPK && !force {
+				return fmt.Errorf("the new token belongs to %s (%s), not %s (%s). Use --force to store it anyway",
+					creds.Email, creds.AccountPK, old.Email, old.AccountPK)
+			}
+			if err := store.Save(creds); err != nil {
+				return fmt.Errorf("failed to save credentials: %w", err)
+			}
+
+			if !outfmt.IsText(ctx) {
+				saved, err := store.Get(creds.Name)
+				if err != nil {
+					return err
+				}
+				t := credentialsTable(saved)
+				t.Detail = true
+				return outfmt.Write(ctx, os.Stdout, saved, t)
+			}
+			u.Success(fmt.Sprintf("Rotated token for %s (%s)", creds.Name, creds.Email))
+			u.Info("Revoke the old token in the ControlD dashboard once nothing else uses it")
 			return nil
 		},
 	}
+
+	cmd.Flags().StringVar(&token, "token", "", "New API token (skips interactive prompt)")
+	cmd.Flags().BoolVar(&force, "force", false, "Store the token even if it belongs to a different ControlD account")
+	meta.register(cmd)
+	return cmd
+}
+
+// expiryNotice is how long before a token expires auth commands start to
+// warn about it.
+const expiryNotice = 14 * 24 * time.Hour
+
+// credentialWarnings returns reminders for c: its expiry is near or past, or
+// it is older than maxAge. A zero maxAge disables the age check.
+func credentialWarnings(c secrets.Credentials, maxAge time.Duration, now time.Time) []string {
+	var warnings []string
+	switch {
+	case c.ExpiresAt.IsZero():
+	case !now.Before(c.ExpiresAt):
+		warnings = append(warnings, fmt.Sprintf("token for %s expired on %s. Run: controld auth rotate %s",
+			c.Name, formatExpiry(c.ExpiresAt), c.Name))
+	case c.ExpiresAt.Sub(now) <= expiryNotice:
+		warnings = append(warnings, fmt.Sprintf("token for %s expires on %s, in %s. Run: controld auth rotate %s",
+			c.Name, formatExpiry(c.ExpiresAt), formatAge(c.ExpiresAt.Sub(now)), c.Name))
+	}
+	if age := now.Sub(c.CreatedAt); maxAge > 0 && !c.CreatedAt.IsZero() && age > maxAge {
+		warnings = append(warnings, fmt.Sprintf("token for %s is %s old, older than token-max-age. Run: controld auth rotate %s",
+			c.Name, formatAge(age), c.Name))
+	}
+	return warnings
+}
+
+// warnCredentials prints the reminders for every account in creds.
+func warnCredentials(u *ui.UI, creds []secrets.Credentials) {
+	var maxAge time.Duration
+	if settings.TokenMaxAge != "" {
+		d, err := parseDuration(settings.TokenMaxAge)
+		if err != nil {
+			u.Warn(fmt.Sprintf("ignoring token-max-age: %v", err))
+		} else {
+			maxAge = d
+		}
+	}
+	now := time.Now()
+	for _, c := range creds {
+		for _, w := range credentialWarnings(c, maxAge, now) {
+			u.Warn(w)
+		}
+	}
+}
+
+func formatExpiry(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Local().Format("2006-01-02")
 }
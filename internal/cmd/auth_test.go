@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/salmonumbrella/controld-cli/internal/controld"
+	"github.com/salmonumbrella/controld-cli/internal/controld/controldsim"
+	"github.com/salmonumbrella/controld-cli/internal/secrets"
+)
+
+func TestAuthStatus(t *testing.T) {
+	srv := newTestServer(t, controldsim.WithToken("good"))
+	srv.Seed()
+	useTestKeyring(t, map[string]string{"alpha": "good", "beta": "revoked"})
+
+	out, err := runCmd(t, "--output", "json", "auth", "status")
+	require.NoError(t, err)
+	var statuses []tokenStatus
+	require.NoError(t, json.Unmarshal([]byte(out), &statuses))
+	require.Len(t, statuses, 2)
+	assert.Equal(t, "alpha", statuses[0].Account)
+	assert.Equal(t, "valid", statuses[0].Status)
+	assert.Equal(t, "user@example.com", statuses[0].Email)
+	assert.NotEmpty(t, statuses[0].AccountPK)
+	assert.Equal(t, "beta", statuses[1].Account)
+	assert.Equal(t, "revoked", statuses[1].Status)
+	assert.NotEmpty(t, statuses[1].Error)
+
+	out, err = runCmd(t, "auth", "status", "--account", "alpha")
+	require.NoError(t, err)
+	assert.Contains(t, out, "user@example.com")
+
+	// The selected account's token is broken.
+	_, err = runCmd(t, "auth", "status", "--account", "beta")
+	assert.ErrorContains(t, err, "token for account beta is revoked")
+
+	_, err = runCmd(t, "auth", "status", "--account", "missing")
+	assert.ErrorContains(t, err, `account "missing" not found`)
+}
+
+func TestTokenState(t *testing.T) {
+	authErr := func(msg string) error {
+		return controld.NewAuthorizationError(&controld.Error{
+			StatusCode: http.StatusUnauthorized,
+			Error:      controld.ResponseInfo{Message: msg},
+		})
+	}
+	assert.Equal(t, "valid", tokenState(nil))
+	assert.Equal(t, "expired", tokenState(authErr("API token has expired")))
+	assert.Equal(t, "revoked", tokenState(authErr("Invalid API token")))
+	assert.Equal(t, "error", tokenState(errors.New("connection refused")))
+}
+
+func TestAuthLoginMetadata(t *testing.T) {
+	newTestServer(t, controldsim.WithToken("good"))
+	store := useTestKeyring(t, nil)
+
+	_, err := runCmd(t, "auth", "login", "--no-browser", "--name", "work", "--token", "good",
+		"--label", "laptop", "--scope", "read", "--expires", "2030-01-31")
+	require.NoError(t, err)
+	creds, err := store.Get("work")
+	require.NoError(t, err)
+	assert.Equal(t, "user@example.com", creds.Email)
+	assert.NotEmpty(t, creds.AccountPK)
+	assert.Equal(t, "laptop", creds.Label)
+	assert.Equal(t, "read", creds.Scope)
+	assert.Equal(t, "2030-01-31", formatExpiry(creds.ExpiresAt))
+
+	out, err := runCmd(t, "auth", "list")
+	require.NoError(t, err)
+	assert.Contains(t, out, "laptop")
+
+	// Tokens the API rejects are not stored.
+	_, err = runCmd(t, "auth", "login", "--no-browser", "--name", "home", "--token", "bad")
+	assert.ErrorContains(t, err, "token rejected")
+	_, err = store.Get("home")
+	assert.Error(t, err)
+
+	_, err = runCmd(t, "auth", "login", "--no-browser", "--name", "home", "--token", "good", "--scope", "admin")
+	assert.ErrorContains(t, err, "invalid scope")
+}
+
+func TestAuthRotate(t *testing.T) {
+	newTestServer(t, controldsim.WithToken("new"))
+	store := useTestKeyring(t, nil)
+	require.NoError(t, store.Save(secrets.Credentials{
+		Name: "work", Token: "old", Label: "laptop", Scope: "write", AccountPK: "user00001",
+		ExpiresAt: time.Now().Add(24 * time.Hour),
+	}))
+
+	// An invalid token leaves the stored one in place.
+	_, err := runCmd(t, "auth", "rotate", "work", "--token", "wrong")
+	assert.ErrorContains(t, err, "token rejected")
+	creds, err := store.Get("work")
+	require.NoError(t, err)
+	assert.Equal(t, "old", creds.Token)
+
+	out, err := runCmd(t, "--output", "json", "auth", "rotate", "work", "--token", "new")
+	require.NoError(t, err)
+	assert.NotContains(t, out, "new", "the token is not printed")
+	creds, err = store.Get("work")
+	require.NoError(t, err)
+	assert.Equal(t, "new", creds.Token)
+	assert.Equal(t, "user@example.com", creds.Email)
+	assert.Equal(t, "laptop", creds.Label)
+	assert.Equal(t, "write", creds.Scope)
+	assert.True(t, creds.ExpiresAt.IsZero())
+
+	_, err = runCmd(t, "auth", "rotate", "missing", "--token", "new")
+	assert.ErrorContains(t, err, `account "missing" not found`)
+}
+
+func TestAuthRotateOtherAccount(t *testing.T) {
+	newTestServer(t, controldsim.WithToken("new"))
+	store := useTestKeyring(t, nil)
+	require.NoError(t, store.Save(secrets.Credentials{Name: "work", Token: "old", Email: "other@example.com", AccountPK: "other"}))
+
+	_, err := runCmd(t, "auth", "rotate", "work", "--token", "new")
+	assert.ErrorContains(t, err, "Use --force")
+
+	out, err := runCmd(t, "--output", "csv", "auth", "rotate", "work", "--token", "new", "--force")
+	require.NoError(t, err)
+	assert.Contains(t, out, "work,user@example.com,")
+	creds, err := store.Get("work")
+	require.NoError(t, err)
+	assert.Equal(t, "new", creds.Token)
+}
+
+func TestCredentialWarnings(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	warnings := credentialWarnings(secrets.Credentials{Name: "work", CreatedAt: now.Add(-100 * day)}, 90*day, now)
+	require.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], "older than token-max-age")
+
+	assert.Empty(t, credentialWarnings(secrets.Credentials{Name: "work", CreatedAt: now.Add(-100 * day)}, 0, now))
+	assert.Empty(t, credentialWarnings(secrets.Credentials{Name: "work", CreatedAt: now, ExpiresAt: now.Add(30 * day)}, 0, now))
+
+	warnings = credentialWarnings(secrets.Credentials{Name: "work", CreatedAt: now, ExpiresAt: now.Add(3 * day)}, 0, now)
+	require.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], "expires on")
+
+	warnings = credentialWarnings(secrets.Credentials{Name: "work", CreatedAt: now, ExpiresAt: now.Add(-day)}, 0, now)
+	require.Len(t, warnings, 1)
+	assert.True(t, strings.HasPrefix(warnings[0], "token for work expired on"))
+}
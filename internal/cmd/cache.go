@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/salmonumbrella/controld-cli/internal/api"
+	"github.com/salmonumbrella/controld-cli/internal/cache"
+	"github.com/salmonumbrella/controld-cli/internal/ui"
+)
+
+// cacheStores are the on-disk caches removed by cache clear.
+var cacheStores = []string{api.ResponseCacheName, "completion"}
+
+func newCacheCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Manage cached API responses",
+		Long: `Manage cached API responses.
+
+Responses to list requests are cached on disk for a short time, between 30
+seconds for account data such as profiles and devices and an hour for catalog
+data such as service categories. Changes made through the CLI invalidate the
+affected entries. Use --no-cache to bypass the cache for a single command.`,
+	}
+
+	cmd.AddCommand(newCacheClearCmd())
+	return cmd
+}
+
+func newCacheClearCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "clear",
+		Short: "Remove all cached responses",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			for _, name := range cacheStores {
+				store, err := cache.Open(name)
+				if err != nil {
+					return err
+				}
+				if err := store.Clear(); err != nil {
+					return fmt.Errorf("failed to clear %s cache: %w", name, err)
+				}
+			}
+			ui.FromContext(cmd.Context()).Success("Cache cleared")
+			return nil
+		},
+	}
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseCacheAcrossCommands(t *testing.T) {
+	srv := newTestServer(t)
+	srv.Seed()
+
+	out, err := runCmd(t, "devices", "list")
+	require.NoError(t, err)
+	assert.NotContains(t, out, "Console")
+
+	// A device added behind the CLI's back is not seen until the cache is
+	// bypassed or cleared.
+	srv.AddDevice("Console", srv.Profiles()[0].PK)
+
+	out, err = runCmd(t, "devices", "list")
+	require.NoError(t, err)
+	assert.NotContains(t, out, "Console")
+
+	out, err = runCmd(t, "--no-cache", "devices", "list")
+	require.NoError(t, err)
+	assert.Contains(t, out, "Console")
+
+	_, err = runCmd(t, "cache", "clear")
+	require.NoError(t, err)
+	out, err = runCmd(t, "devices", "list")
+	require.NoError(t, err)
+	assert.Contains(t, out, "Console")
+}
+
+func TestResponseCacheInvalidatedByMutation(t *testing.T) {
+	srv := newTestServer(t)
+	srv.Seed()
+
+	_, err := runCmd(t, "devices", "list")
+	require.NoError(t, err)
+	_, err = runCmd(t, "devices", "create", "--name", "Console", "--profile-id", "Home")
+	require.NoError(t, err)
+
+	out, err := runCmd(t, "devices", "list")
+	require.NoError(t, err)
+	assert.Contains(t, out, "Console")
+}
+
+func TestNoCacheStillInvalidates(t *testing.T) {
+	srv := newTestServer(t)
+	srv.Seed()
+
+	_, err := runCmd(t, "devices", "list")
+	require.NoError(t, err)
+	_, err = runCmd(t, "--no-cache", "--yes", "devices", "delete", "Tablet")
+	require.NoError(t, err)
+
+	out, err := runCmd(t, "devices", "list")
+	require.NoError(t, err)
+	assert.NotContains(t, out, "Tablet")
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/99designs/keyring"
+	"github.com/stretchr/testify/require"
+
+	"github.com/salmonumbrella/controld-cli/internal/config"
+	"github.com/salmonumbrella/controld-cli/internal/controld"
+	"github.com/salmonumbrella/controld-cli/internal/controld/controldsim"
+	"github.com/salmonumbrella/controld-cli/internal/controld/controldtest"
+	"github.com/salmonumbrella/controld-cli/internal/secrets"
+)
+
+// newTestServer starts a simulator and points the CLI at it. The CLI config
+// directory is moved to a temporary directory so tests never touch the
+// user's schedule or caches.
+func newTestServer(t *testing.T, opts ...controldsim.Option) *controldtest.Server {
+	t.Helper()
+
+	srv := controldtest.NewServer(t, opts...)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv(config.EnvToken, "test-token")
+	t.Setenv(config.EnvBaseURL, srv.URL)
+	t.Setenv(config.EnvOutput, "")
+
+	prev := clientOptions
+	clientOptions = []controld.Option{
+		controld.UsingRateLimit(1000),
+		controld.UsingRetryPolicy(1, 0, 0),
+	}
+	t.Cleanup(func() { clientOptions = prev })
+
+	return srv
+}
+
+// useTestKeyring replaces the keyring with an in-memory one holding the
+// given account tokens.
+func useTestKeyring(t *testing.T, tokens map[string]string) secrets.Store {
+	t.Helper()
+
+	store := secrets.NewKeyringStore(keyring.NewArrayKeyring(nil))
+	for name, token := range tokens {
+		require.NoError(t, store.Set(name, token))
+	}
+
+	prev := openSecrets
+	openSecrets = func() (secrets.Store, error) { return store, nil }
+	t.Cleanup(func() { openSecrets = prev })
+	return store
+}
+
+// runCmd runs the CLI with args and returns what it wrote to stdout.
+func runCmd(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	out := make(chan string)
+	go func() {
+		var buf bytes.Buffer
+		_, _ = io.Copy(&buf, r)
+		out <- buf.String()
+	}()
+
+	cmd := NewRootCmd()
+	cmd.SetArgs(append([]string{"--color", "never"}, args...))
+	cmd.SetErr(io.Discard)
+	runErr := cmd.ExecuteContext(context.Background())
+
+	_ = w.Close()
+	return <-out, runErr
+}
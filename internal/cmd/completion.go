@@ -1,9 +1,17 @@
 package cmd
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
+
+	"github.com/salmonumbrella/controld-cli/internal/cache"
+	"github.com/salmonumbrella/controld-cli/internal/controld"
 )
 
 func newCompletionCmd() *cobra.Command {
@@ -45,3 +53,186 @@ PowerShell:
 	}
 	return cmd
 }
+
+// Completion results are cached on disk so repeated tab presses stay fast.
+// Entries younger than completionCacheTTL are used without contacting the API;
+// older entries up to completionOfflineTTL are used only if the API call fails.
+const (
+	completionCacheTTL   = 2 * time.Minute
+	completionOfflineTTL = time.Hour
+	// completionTimeout bounds API calls (including retries) so an offline
+	// shell falls back to the cache instead of hanging.
+	completionTimeout = 5 * time.Second
+)
+
+// completionFunc lists the candidates for one positional argument, given the
+// arguments already present on the command line.
+type completionFunc func(ctx context.Context, client *controld.API, args []string) ([]candidate, error)
+
+// completeArgs returns a ValidArgsFunction that completes each positional
+// argument with the corresponding completionFunc. A nil entry disables
+// completion for that position.
+func completeArgs(fns ...completionFunc) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		if len(args) >= len(fns) || fns[len(args)] == nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return runCompletion(cmd, fns[len(args)], args)
+	}
+}
+
+// completeRepeated is like completeArgs, but the last function also completes
+// every further argument (e.g. "<device-id>...").
+func completeRepeated(fns ...completionFunc) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		i := min(len(args), len(fns)-1)
+		if fns[i] == nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return runCompletion(cmd, fns[i], args)
+	}
+}
+
+// completeFlag returns a flag completion function backed by fn.
+func completeFlag(fn completionFunc) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		return runCompletion(cmd, fn, args)
+	}
+}
+
+func runCompletion(cmd *cobra.Command, fn completionFunc, args []string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(ctx, completionTimeout)
+	defer cancel()
+
+	client, err := getClient(ctx)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	cands, err := fn(ctx, client, args)
+	if err != nil {
+		cobra.CompDebugln(err.Error(), true)
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	out := make([]cobra.Completion, 0, len(cands))
+	for _, c := range cands {
+		out = append(out, cobra.CompletionWithDesc(c.ID, c.Name))
+	}
+	return out, cobra.ShellCompDirectiveNoFileComp
+}
+
+// cachedCompletion wraps fn with the on-disk completion cache. scope is the
+// number of leading positional arguments the result depends on, and is part
+// of the cache key together with the account's token. Only the profile is
+// ever in scope, so a missing profile argument is keyed by the context's
+// default profile.
+func cachedCompletion(kind string, scope int, fn completionFunc) completionFunc {
+	return func(ctx context.Context, client *controld.API, args []string) ([]candidate, error) {
+		store, err := cache.Open("completion")
+		if err != nil {
+			return fn(ctx, client, args)
+		}
+
+		scoped := args[:min(scope, len(args))]
+		if scope > 0 && len(scoped) == 0 {
+			if ref, err := profileRef(nil); err == nil {
+				scoped = []string{ref}
+			}
+		}
+		sum := sha256.Sum256([]byte(client.APIToken))
+		key := strings.Join(append([]string{hex.EncodeToString(sum[:]), kind}, scoped...), "\x00")
+
+		var cands []candidate
+		if ok, _ := store.Get(key, completionCacheTTL, &cands); ok {
+			return cands, nil
+		}
+
+		cands, err = fn(ctx, client, args)
+		if err != nil {
+			if ok, _ := store.Get(key, completionOfflineTTL, &cands); ok {
+				return cands, nil
+			}
+			return nil, err
+		}
+
+		_ = store.Set(key, cands)
+		return cands, nil
+	}
+}
+
+var (
+	completeProfiles = cachedCompletion("profiles", 0, func(ctx context.Context, client *controld.API, _ []string) ([]candidate, error) {
+		profiles, err := client.ListProfiles(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return profileCandidates(profiles), nil
+	})
+
+	completeDevices = cachedCompletion("devices", 0, func(ctx context.Context, client *controld.API, _ []string) ([]candidate, error) {
+		devices, err := client.ListDevices(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return deviceCandidates(devices), nil
+	})
+
+	completeProfileServices = cachedCompletion("services", 1, func(ctx context.Context, client *controld.API, args []string) ([]candidate, error) {
+		profileID, err := completionProfileID(ctx, client, args)
+		if err != nil {
+			return nil, err
+		}
+		services, err := client.ListProfileServices(ctx, controld.ListProfileServicesParams{ProfileID: profileID})
+		if err != nil {
+			return nil, err
+		}
+		return serviceCandidates(services), nil
+	})
+
+	completeProfileFilters = cachedCompletion("filters", 1, func(ctx context.Context, client *controld.API, args []string) ([]candidate, error) {
+		profileID, err := completionProfileID(ctx, client, args)
+		if err != nil {
+			return nil, err
+		}
+		filters, err := listProfileFilters(ctx, client, profileID)
+		if err != nil {
+			return nil, err
+		}
+		return filterCandidates(filters), nil
+	})
+
+	completeRuleFolders = cachedCompletion("folders", 1, func(ctx context.Context, client *controld.API, args []string) ([]candidate, error) {
+		profileID, err := completionProfileID(ctx, client, args)
+		if err != nil {
+			return nil, err
+		}
+		folders, err := client.ListProfileRuleFolders(ctx, controld.ListProfileRuleFoldersParams{ProfileID: profileID})
+		if err != nil {
+			return nil, err
+		}
+		return folderCandidates(folders), nil
+	})
+)
+
+// completionProfileID resolves the profile given as the first positional
+// argument, or the context's default profile, using the cached profile list.
+func completionProfileID(ctx context.Context, client *controld.API, args []string) (string, error) {
+	ref, err := profileRef(args)
+	if err != nil {
+		return "", err
+	}
+	cands, err := completeProfiles(ctx, client, nil)
+	if err != nil {
+		return "", err
+	}
+	i, err := matchCandidates("profile", ref, cands)
+	if err != nil {
+		return "", err
+	}
+	return cands[i].ID, nil
+}
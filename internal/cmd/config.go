@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/salmonumbrella/controld-cli/internal/config"
+	"github.com/salmonumbrella/controld-cli/internal/outfmt"
+	"github.com/salmonumbrella/controld-cli/internal/ui"
+)
+
+// defaultContextName is the context config set creates when none is current.
+const defaultContextName = "default"
+
+func newConfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Manage CLI configuration and contexts",
+		Long: `Manage CLI configuration and contexts.
+
+The configuration file (~/.config/controld-cli/config.yaml) holds named
+contexts, each a set of defaults such as the account, output format, color,
+default profile, API base URL, rate limit and retry policy. The current
+context applies to every command; use --context or CONTROLD_CONTEXT to pick
+another for a single command.
+
+Flags take precedence over environment variables, which take precedence over
+the context, which takes precedence over built-in defaults.
+
+Keys: ` + strings.Join(config.ContextKeys, ", "),
+	}
+
+	cmd.AddCommand(newConfigGetCmd())
+	cmd.AddCommand(newConfigSetCmd())
+	cmd.AddCommand(newConfigUseContextCmd())
+	cmd.AddCommand(newConfigViewCmd())
+	return cmd
+}
+
+// configSetting is a context setting as printed by config get.
+type configSetting struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+func newConfigGetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:               "get <key>",
+		Short:             "Print a setting of the current context",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeConfigKeys,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, err := config.Load()
+			if err != nil {
+				return err
+			}
+			c, err := file.Select(flags.Context)
+			if err != nil {
+				return err
+			}
+			value, err := c.Get(args[0])
+			if err != nil {
+				return err
+			}
+
+			setting := configSetting{Key: args[0], Value: value}
+			t := &outfmt.Table{
+				Columns: []outfmt.Column{
+					{Name: "key"},
+					{Name: "value"},
+				},
+				Detail: true,
+			}
+			t.AddRow(setting.Key, setting.Value)
+			return outfmt.Write(cmd.Context(), os.Stdout, setting, t)
+		},
+	}
+}
+
+func newConfigSetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "Change a setting of the current context",
+		Long: `Change a setting of the current context, or of the context named by
+--context, creating it if needed. An empty value unsets the key. When there is
+no current context, a context named "default" is created and made current.`,
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeConfigKeys,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			key, value := args[0], args[1]
+			if key == "output" && value != "" {
+				if err := outfmt.ValidateFormat(value); err != nil {
+					return err
+				}
+			}
+			if key == "token-max-age" && value != "" {
+				if _, err := parseDuration(value); err != nil {
+					return fmt.Errorf("invalid token-max-age: %w", err)
+				}
+			}
+
+			file, err := config.Load()
+			if err != nil {
+				return err
+			}
+			name := flags.Context
+			if name == "" {
+				name = file.CurrentContext
+			}
+			if name == "" {
+				name = defaultContextName
+				file.CurrentContext = name
+			}
+			if err := file.Context(name).Set(key, value); err != nil {
+				return err
+			}
+			if err := file.Save(); err != nil {
+				return fmt.Errorf("failed to save config: %w", err)
+			}
+
+			ui.FromContext(cmd.Context()).Success(fmt.Sprintf("Set %s in context %s", key, name))
+			return nil
+		},
+	}
+}
+
+func newConfigUseContextCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:               "use-context <name>",
+		Short:             "Switch the current context",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeConfigContexts,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, err := config.Load()
+			if err != nil {
+				return err
+			}
+			if _, ok := file.Contexts[args[0]]; !ok {
+				return fmt.Errorf("context %q not found. Create it with: controld config set --context %s <key> <value>", args[0], args[0])
+			}
+			file.CurrentContext = args[0]
+			if err := file.Save(); err != nil {
+				return fmt.Errorf("failed to save config: %w", err)
+			}
+
+			ui.FromContext(cmd.Context()).Success(fmt.Sprintf("Switched to context %s", args[0]))
+			return nil
+		},
+	}
+}
+
+func newConfigViewCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "view",
+		Short: "Show the configuration file",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, err := config.Load()
+			if err != nil {
+				return err
+			}
+
+			if !outfmt.IsText(cmd.Context()) {
+				t := &outfmt.Table{
+					Columns: []outfmt.Column{
+						{Name: "current"},
+						{Name: "name"},
+						{Name: "account"},
+						{Name: "output"},
+						{Name: "profile"},
+						{Name: "base_url"},
+					},
+				}
+				for _, name := range file.Names() {
+					c := file.Contexts[name]
+					current := ""
+					if name == file.CurrentContext {
+						current = "*"
+					}
+					t.AddRow(current, name, c.Account, c.Output, c.Profile, c.BaseURL)
+				}
+				return outfmt.Write(cmd.Context(), os.Stdout, file, t)
+			}
+
+			data, err := yaml.Marshal(file)
+			if err != nil {
+				return err
+			}
+			_, err = os.Stdout.Write(data)
+			return err
+		},
+	}
+}
+
+func completeConfigKeys(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return config.ContextKeys, cobra.ShellCompDirectiveNoFileComp
+}
+
+func completeConfigContexts(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	file, err := config.Load()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return file.Names(), cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/salmonumbrella/controld-cli/internal/config"
+)
+
+func TestConfigContextPrecedence(t *testing.T) {
+	srv := newTestServer(t)
+	srv.Seed()
+
+	_, err := runCmd(t, "config", "set", "output", "json")
+	require.NoError(t, err)
+
+	// The context applies when no flag or environment variable is given.
+	out, err := runCmd(t, "devices", "list")
+	require.NoError(t, err)
+	assert.True(t, json.Valid([]byte(out)))
+
+	// Flags win over the context.
+	out, err = runCmd(t, "--output", "text", "devices", "list")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(out, "DEVICE_ID"))
+
+	// So do environment variables.
+	t.Setenv(config.EnvOutput, "csv")
+	out, err = runCmd(t, "devices", "list")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(out, "device_id,"))
+}
+
+func TestConfigContexts(t *testing.T) {
+	srv := newTestServer(t)
+	srv.Seed()
+
+	_, err := runCmd(t, "config", "set", "--context", "acme", "profile", "Kids")
+	require.NoError(t, err)
+	_, err = runCmd(t, "config", "set", "--context", "acme", "base-url", srv.URL)
+	require.NoError(t, err)
+
+	_, err = runCmd(t, "config", "use-context", "missing")
+	assert.ErrorContains(t, err, `context "missing" not found`)
+	_, err = runCmd(t, "--context", "missing", "devices", "list")
+	assert.ErrorContains(t, err, `context "missing" not found`)
+
+	_, err = runCmd(t, "config", "use-context", "acme")
+	require.NoError(t, err)
+	out, err := runCmd(t, "config", "get", "profile")
+	require.NoError(t, err)
+	assert.Equal(t, "key    profile\nvalue  Kids\n", out)
+
+	out, err = runCmd(t, "config", "get", "profile", "--query", "value")
+	require.NoError(t, err)
+	assert.Equal(t, "Kids\n", out)
+
+	out, err = runCmd(t, "config", "view")
+	require.NoError(t, err)
+	assert.Contains(t, out, "current-context: acme")
+
+	// The context's base URL is used when CONTROLD_BASE_URL is unset, and its
+	// profile when a command's profile argument is omitted.
+	t.Setenv(config.EnvBaseURL, "")
+	out, err = runCmd(t, "profiles", "filters", "list")
+	require.NoError(t, err)
+	assert.Contains(t, out, "gambling")
+
+	_, err = runCmd(t, "config", "set", "retry.max-retries", "many")
+	assert.ErrorContains(t, err, "non-negative integer")
+	_, err = runCmd(t, "config", "set", "token-max-age", "soon")
+	assert.ErrorContains(t, err, "invalid token-max-age")
+	_, err = runCmd(t, "config", "set", "nope", "1")
+	assert.ErrorContains(t, err, `unknown config key "nope"`)
+}
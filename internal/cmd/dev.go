@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/salmonumbrella/controld-cli/internal/config"
+	"github.com/salmonumbrella/controld-cli/internal/controld/controldsim"
+)
+
+func newDevCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "dev",
+		Short: "Developer tools",
+	}
+	cmd.AddCommand(newDevMockServerCmd())
+	return cmd
+}
+
+func newDevMockServerCmd() *cobra.Command {
+	var addr string
+	var requireToken string
+	var empty bool
+	var faults []string
+
+	cmd := &cobra.Command{
+		Use:   "mock-server",
+		Short: "Run a local in-memory ControlD API",
+		Long: `Run a local in-memory ControlD API for offline development and testing.
+
+Point the CLI at it by exporting the printed CONTROLD_BASE_URL. State is kept
+in memory and lost when the server stops.
+
+Use --fail to inject errors. Each value has the form "[METHOD] PATH=STATUS[xN]",
+where PATH may contain * wildcards and xN limits the fault to N requests:
+
+  controld dev mock-server --fail "GET /devices=503x2" --fail "/profiles/*=403"`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var opts []controldsim.Option
+			if requireToken != "" {
+				opts = append(opts, controldsim.WithToken(requireToken))
+			}
+			sim := controldsim.New(opts...)
+			if !empty {
+				sim.Seed()
+			}
+			for _, spec := range faults {
+				f, err := parseFault(spec)
+				if err != nil {
+					return err
+				}
+				sim.Inject(f)
+			}
+
+			ln, err := net.Listen("tcp", addr)
+			if err != nil {
+				return err
+			}
+			srv := &http.Server{Handler: sim, ReadHeaderTimeout: 10 * time.Second}
+
+			baseURL := "http://" + ln.Addr().String()
+			_, _ = fmt.Fprintf(os.Stderr, "Mock ControlD API listening on %s\n\n", baseURL)
+			_, _ = fmt.Fprintf(os.Stderr, "  export %s=%s\n", config.EnvBaseURL, baseURL)
+			if requireToken != "" {
+				_, _ = fmt.Fprintf(os.Stderr, "  export %s=%s\n", config.EnvToken, requireToken)
+			} else {
+				_, _ = fmt.Fprintf(os.Stderr, "  export %s=mock\n", config.EnvToken)
+			}
+			_, _ = fmt.Fprintln(os.Stderr)
+
+			errc := make(chan error, 1)
+			go func() { errc <- srv.Serve(ln) }()
+
+			select {
+			case err := <-errc:
+				return err
+			case <-cmd.Context().Done():
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				if err := srv.Shutdown(ctx); err != nil {
+					return err
+				}
+				if err := <-errc; !errors.Is(err, http.ErrServerClosed) {
+					return err
+				}
+				return nil
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&addr, "addr", "127.0.0.1:8787", "Address to listen on")
+	cmd.Flags().StringVar(&requireToken, "require-token", "", "Reject requests without this API token (default: accept any token)")
+	cmd.Flags().BoolVar(&empty, "empty", false, "Start without sample profiles and devices")
+	cmd.Flags().StringArrayVar(&faults, "fail", nil, `Inject an error: "[METHOD] PATH=STATUS[xN]" (repeatable)`)
+	return cmd
+}
+
+// parseFault parses a fault of the form "[METHOD] PATH=STATUS[xN]".
+func parseFault(spec string) (controldsim.Fault, error) {
+	target, result, ok := strings.Cut(spec, "=")
+	if !ok {
+		return controldsim.Fault{}, fmt.Errorf("invalid fault %q: expected [METHOD] PATH=STATUS", spec)
+	}
+
+	var f controldsim.Fault
+	fields := strings.Fields(target)
+	switch len(fields) {
+	case 1:
+		f.Path = fields[0]
+	case 2:
+		f.Method = strings.ToUpper(fields[0])
+		f.Path = fields[1]
+	default:
+		return controldsim.Fault{}, fmt.Errorf("invalid fault %q: expected [METHOD] PATH=STATUS", spec)
+	}
+
+	status, times, hasTimes := strings.Cut(strings.TrimSpace(result), "x")
+	code, err := strconv.Atoi(status)
+	if err != nil || code < 400 || code > 599 {
+		return controldsim.Fault{}, fmt.Errorf("invalid fault %q: status must be between 400 and 599", spec)
+	}
+	f.Status = code
+	if hasTimes {
+		n, err := strconv.Atoi(times)
+		if err != nil || n < 1 {
+			return controldsim.Fault{}, fmt.Errorf("invalid fault %q: count must be a positive number", spec)
+		}
+		f.Times = n
+	}
+	return f, nil
+}
@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/salmonumbrella/controld-cli/internal/controld/controldsim"
+)
+
+func TestParseFault(t *testing.T) {
+	f, err := parseFault("GET /devices=503x2")
+	require.NoError(t, err)
+	assert.Equal(t, controldsim.Fault{Method: "GET", Path: "/devices", Status: 503, Times: 2}, f)
+
+	f, err = parseFault("/profiles/*=403")
+	require.NoError(t, err)
+	assert.Equal(t, controldsim.Fault{Path: "/profiles/*", Status: 403}, f)
+
+	for _, spec := range []string{"/devices", "/devices=200", "/devices=503x0", "a b c=500"} {
+		_, err := parseFault(spec)
+		assert.Error(t, err, spec)
+	}
+}
@@ -22,14 +22,25 @@ func newDevicesCmd() *cobra.Command {
 	cmd.AddCommand(newDevicesModifyCmd())
 	cmd.AddCommand(newDevicesDeleteCmd())
 	cmd.AddCommand(newDevicesTypesCmd())
+	for _, action := range deviceStatusActions {
+		cmd.AddCommand(newDevicesStatusCmd(action))
+	}
+	cmd.AddCommand(newDevicesPendingCmd())
+	cmd.AddCommand(newDevicesScheduleCmd())
 	return cmd
 }
 
 func newDevicesListCmd() *cobra.Command {
-	return &cobra.Command{
+	var accounts accountsFlags
+
+	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List all devices",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if accounts.enabled() {
+				return forEachAccount(cmd, &accounts, (*controld.API).ListDevices, deviceTable)
+			}
+
 			client, err := getClient(cmd.Context())
 			if err != nil {
 				return err
@@ -40,78 +51,99 @@ func newDevicesListCmd() *cobra.Command {
 				return err
 			}
 
-			if outfmt.IsJSON(cmd.Context()) {
-				return outfmt.WriteJSON(os.Stdout, devices)
-			}
+			return outfmt.Write(cmd.Context(), os.Stdout, devices, deviceTable(devices))
+		},
+	}
 
-			if len(devices) == 0 {
-				fmt.Println("No devices found")
-				return nil
-			}
+	accounts.register(cmd)
+	return cmd
+}
 
-			tw := outfmt.NewTabWriter(os.Stdout)
-			_, _ = fmt.Fprintln(tw, "DEVICE_ID\tNAME\tSTATUS\tPROFILE")
-			for _, d := range devices {
-				status := "pending"
-				switch d.Status {
-				case controld.Active:
-					status = "active"
-				case controld.SoftDisabled:
-					status = "soft-disabled"
-				case controld.HardDisabled:
-					status = "hard-disabled"
-				}
-				_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", d.DeviceID, d.Name, status, d.Profile.Name)
-			}
-			return tw.Flush()
+func deviceTable(devices []controld.Device) *outfmt.Table {
+	t := &outfmt.Table{
+		Columns: []outfmt.Column{
+			{Name: "device_id"},
+			{Name: "name"},
+			{Name: "status", Status: true},
+			{Name: "profile"},
+			{Name: "doh", Wide: true},
+			{Name: "dot", Wide: true},
+			{Name: "ddns", Wide: true},
+			{Name: "analytics", Wide: true},
 		},
+		Empty: "No devices found",
+	}
+	for _, d := range devices {
+		t.AddRow(d.DeviceID, d.Name, deviceStatusToString(d.Status), d.Profile.Name,
+			d.Resolvers.DoH, d.Resolvers.DoT, deviceDDNS(d), analyticsToString(d.Stats))
+	}
+	return t
+}
+
+// deviceDetail is the table for a single device, as shown by devices get.
+func deviceDetail(device controld.Device) *outfmt.Table {
+	t := &outfmt.Table{
+		Columns: []outfmt.Column{
+			{Name: "device_id"},
+			{Name: "name"},
+			{Name: "status", Status: true},
+			{Name: "profile"},
+			{Name: "doh"},
+			{Name: "dot"},
+		},
+		Detail: true,
+	}
+	row := []string{device.DeviceID, device.Name, deviceStatusToString(device.Status), device.Profile.Name, device.Resolvers.DoH, device.Resolvers.DoT}
+	if device.Icon != nil {
+		t.Columns = append(t.Columns, outfmt.Column{Name: "icon"})
+		row = append(row, string(*device.Icon))
+	}
+	t.AddRow(row...)
+	return t
+}
+
+// deviceDDNS returns a device's dynamic DNS hostname, or "-" if it has none.
+func deviceDDNS(d controld.Device) string {
+	if d.DDNS == nil || d.DDNS.Status == 0 || d.DDNS.Hostname == "" {
+		return "-"
+	}
+	return d.DDNS.Hostname
+}
+
+func analyticsToString(level *controld.AnalyticsLevel) string {
+	if level == nil {
+		return "-"
+	}
+	switch *level {
+	case controld.Off:
+		return "off"
+	case controld.Basic:
+		return "basic"
+	case controld.Full:
+		return "full"
+	default:
+		return fmt.Sprintf("unknown(%d)", *level)
 	}
 }
 
 func newDevicesGetCmd() *cobra.Command {
 	return &cobra.Command{
-		Use:   "get <device-id>",
-		Short: "Get device details",
-		Args:  cobra.ExactArgs(1),
+		Use:               "get <device-id>",
+		Short:             "Get device details",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeArgs(completeDevices),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			client, err := getClient(cmd.Context())
 			if err != nil {
 				return err
 			}
 
-			devices, err := client.ListDevices(cmd.Context())
+			device, err := resolveDevice(cmd.Context(), client, args[0])
 			if err != nil {
 				return err
 			}
 
-			deviceID := args[0]
-			var device *controld.Device
-			for i := range devices {
-				if devices[i].DeviceID == deviceID {
-					device = &devices[i]
-					break
-				}
-			}
-
-			if device == nil {
-				return fmt.Errorf("device not found: %s", deviceID)
-			}
-
-			if outfmt.IsJSON(cmd.Context()) {
-				return outfmt.WriteJSON(os.Stdout, device)
-			}
-
-			tw := outfmt.NewTabWriter(os.Stdout)
-			_, _ = fmt.Fprintf(tw, "device_id\t%s\n", device.DeviceID)
-			_, _ = fmt.Fprintf(tw, "name\t%s\n", device.Name)
-			_, _ = fmt.Fprintf(tw, "status\t%d\n", device.Status)
-			_, _ = fmt.Fprintf(tw, "profile\t%s\n", device.Profile.Name)
-			_, _ = fmt.Fprintf(tw, "doh\t%s\n", device.Resolvers.DoH)
-			_, _ = fmt.Fprintf(tw, "dot\t%s\n", device.Resolvers.DoT)
-			if device.Icon != nil {
-				_, _ = fmt.Fprintf(tw, "icon\t%s\n", *device.Icon)
-			}
-			return tw.Flush()
+			return outfmt.Write(cmd.Context(), os.Stdout, device, deviceDetail(*device))
 		},
 	}
 }
@@ -131,6 +163,17 @@ func newDevicesCreateCmd() *cobra.Command {
 				return err
 			}
 
+			ref := profileID
+			if ref == "" {
+				if ref, err = profileRef(nil); err != nil {
+					return err
+				}
+			}
+			profileID, err := resolveProfileID(cmd.Context(), client, ref)
+			if err != nil {
+				return err
+			}
+
 			device, err := client.CreateDevice(cmd.Context(), controld.CreateDeviceParams{
 				Name:      name,
 				ProfileID: profileID,
@@ -140,8 +183,8 @@ func newDevicesCreateCmd() *cobra.Command {
 				return err
 			}
 
-			if outfmt.IsJSON(cmd.Context()) {
-				return outfmt.WriteJSON(os.Stdout, device)
+			if !outfmt.IsText(cmd.Context()) {
+				return outfmt.Write(cmd.Context(), os.Stdout, device, deviceDetail(device))
 			}
 
 			u.Success(fmt.Sprintf("Created device: %s (%s)", device.Name, device.DeviceID))
@@ -150,10 +193,10 @@ func newDevicesCreateCmd() *cobra.Command {
 	}
 
 	cmd.Flags().StringVar(&name, "name", "", "Device name (required)")
-	cmd.Flags().StringVar(&profileID, "profile-id", "", "Profile ID (required)")
+	cmd.Flags().StringVar(&profileID, "profile-id", "", "Profile ID (defaults to the config context's profile)")
 	cmd.Flags().StringVar(&icon, "icon", "router", "Device icon")
 	_ = cmd.MarkFlagRequired("name")
-	_ = cmd.MarkFlagRequired("profile-id")
+	_ = cmd.RegisterFlagCompletionFunc("profile-id", completeFlag(completeProfiles))
 	return cmd
 }
 
@@ -163,9 +206,10 @@ func newDevicesModifyCmd() *cobra.Command {
 	var status int
 
 	cmd := &cobra.Command{
-		Use:   "modify <device-id>",
-		Short: "Modify an existing device",
-		Args:  cobra.ExactArgs(1),
+		Use:               "modify <device-id>",
+		Short:             "Modify an existing device",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeArgs(completeDevices),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			u := ui.FromContext(cmd.Context())
 			client, err := getClient(cmd.Context())
@@ -173,14 +217,23 @@ func newDevicesModifyCmd() *cobra.Command {
 				return err
 			}
 
+			deviceID, err := resolveDeviceID(cmd.Context(), client, args[0])
+			if err != nil {
+				return err
+			}
+
 			params := controld.UpdateDeviceParams{
-				DeviceID: args[0],
+				DeviceID: deviceID,
 			}
 
 			if cmd.Flags().Changed("name") {
 				params.Name = &name
 			}
 			if cmd.Flags().Changed("profile-id") {
+				profileID, err := resolveProfileID(cmd.Context(), client, profileID)
+				if err != nil {
+					return err
+				}
 				params.ProfileID = &profileID
 			}
 			if cmd.Flags().Changed("status") {
@@ -193,8 +246,8 @@ func newDevicesModifyCmd() *cobra.Command {
 				return err
 			}
 
-			if outfmt.IsJSON(cmd.Context()) {
-				return outfmt.WriteJSON(os.Stdout, device)
+			if !outfmt.IsText(cmd.Context()) {
+				return outfmt.Write(cmd.Context(), os.Stdout, device, deviceDetail(device))
 			}
 
 			u.Success(fmt.Sprintf("Modified device: %s (%s)", device.Name, device.DeviceID))
@@ -205,14 +258,16 @@ func newDevicesModifyCmd() *cobra.Command {
 	cmd.Flags().StringVar(&name, "name", "", "New device name")
 	cmd.Flags().StringVar(&profileID, "profile-id", "", "New profile ID")
 	cmd.Flags().IntVar(&status, "status", 0, "Device status (0=pending, 1=active, 2=soft-disabled, 3=hard-disabled)")
+	_ = cmd.RegisterFlagCompletionFunc("profile-id", completeFlag(completeProfiles))
 	return cmd
 }
 
 func newDevicesDeleteCmd() *cobra.Command {
 	return &cobra.Command{
-		Use:   "delete <device-id>",
-		Short: "Delete a device",
-		Args:  cobra.ExactArgs(1),
+		Use:               "delete <device-id>",
+		Short:             "Delete a device",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeArgs(completeDevices),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			u := ui.FromContext(cmd.Context())
 			client, err := getClient(cmd.Context())
@@ -220,8 +275,13 @@ func newDevicesDeleteCmd() *cobra.Command {
 				return err
 			}
 
+			device, err := resolveDeviceStrict(cmd.Context(), client, args[0])
+			if err != nil {
+				return err
+			}
+
 			if !outfmt.GetYes(cmd.Context()) {
-				_, _ = fmt.Fprintf(os.Stderr, "Delete device %s? [y/N]: ", args[0])
+				_, _ = fmt.Fprintf(os.Stderr, "Delete device %s (%s)? [y/N]: ", device.Name, device.DeviceID)
 				var confirm string
 				_, _ = fmt.Scanln(&confirm)
 				if confirm != "y" && confirm != "Y" {
@@ -231,13 +291,13 @@ func newDevicesDeleteCmd() *cobra.Command {
 			}
 
 			_, err = client.DeleteDevice(cmd.Context(), controld.DeleteDeviceParams{
-				DeviceID: args[0],
+				DeviceID: device.DeviceID,
 			})
 			if err != nil {
 				return err
 			}
 
-			u.Success(fmt.Sprintf("Deleted device: %s", args[0]))
+			u.Success(fmt.Sprintf("Deleted device: %s", device.DeviceID))
 			return nil
 		},
 	}
@@ -258,17 +318,12 @@ func newDevicesTypesCmd() *cobra.Command {
 				return err
 			}
 
-			if outfmt.IsJSON(cmd.Context()) {
-				return outfmt.WriteJSON(os.Stdout, types)
-			}
-
-			tw := outfmt.NewTabWriter(os.Stdout)
-			_, _ = fmt.Fprintln(tw, "CATEGORY\tNAME")
-			_, _ = fmt.Fprintf(tw, "os\t%s\n", types.OS.Name)
-			_, _ = fmt.Fprintf(tw, "browser\t%s\n", types.Browser.Name)
-			_, _ = fmt.Fprintf(tw, "tv\t%s\n", types.TV.Name)
-			_, _ = fmt.Fprintf(tw, "router\t%s\n", types.Router.Name)
-			return tw.Flush()
+			t := &outfmt.Table{Columns: []outfmt.Column{{Name: "category"}, {Name: "name"}}}
+			t.AddRow("os", types.OS.Name)
+			t.AddRow("browser", types.Browser.Name)
+			t.AddRow("tv", types.TV.Name)
+			t.AddRow("router", types.Router.Name)
+			return outfmt.Write(cmd.Context(), os.Stdout, types, t)
 		},
 	}
 }
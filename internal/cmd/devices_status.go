@@ -0,0 +1,317 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/salmonumbrella/controld-cli/internal/controld"
+	"github.com/salmonumbrella/controld-cli/internal/outfmt"
+	"github.com/salmonumbrella/controld-cli/internal/schedule"
+	"github.com/salmonumbrella/controld-cli/internal/ui"
+)
+
+// deviceStatusAction describes one of the device lifecycle subcommands.
+type deviceStatusAction struct {
+	use    string
+	short  string
+	verb   string
+	status controld.DeviceStatus
+	// temporary actions require --until.
+	temporary bool
+}
+
+var deviceStatusActions = []deviceStatusAction{
+	{use: "enable", short: "Enable devices", verb: "Enabled", status: controld.Active},
+	{use: "disable", short: "Soft-disable devices", verb: "Disabled", status: controld.SoftDisabled},
+	{use: "suspend", short: "Soft-disable devices for a limited time", verb: "Suspended", status: controld.SoftDisabled, temporary: true},
+	{use: "hard-disable", short: "Hard-disable devices", verb: "Hard-disabled", status: controld.HardDisabled},
+}
+
+func newDevicesStatusCmd(action deviceStatusAction) *cobra.Command {
+	var until string
+	var reason string
+
+	cmd := &cobra.Command{
+		Use:   action.use + " <device-id>...",
+		Short: action.short,
+		Long: fmt.Sprintf(`%s.
+
+Use --until to record a local schedule that returns each device to its
+previous status once the duration has passed. Scheduled changes are applied
+by 'devices schedule apply', which can be run from cron.`, action.short),
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: completeRepeated(completeDevices),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			u := ui.FromContext(cmd.Context())
+
+			var expires time.Time
+			if until != "" {
+				d, err := parseDuration(until)
+				if err != nil {
+					return err
+				}
+				expires = time.Now().Add(d).UTC()
+			}
+
+			client, err := getClient(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			if action.status == controld.HardDisabled && !outfmt.GetYes(cmd.Context()) {
+				_, _ = fmt.Fprintf(os.Stderr, "Hard-disable %d device(s)? [y/N]: ", len(args))
+				var confirm string
+				_, _ = fmt.Scanln(&confirm)
+				if confirm != "y" && confirm != "Y" {
+					_, _ = fmt.Fprintln(os.Stderr, "Cancelled")
+					return nil
+				}
+			}
+
+			devices, err := client.ListDevices(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			sched, err := schedule.Open()
+			if err != nil {
+				return err
+			}
+
+			find := findDevice
+			if action.status == controld.HardDisabled {
+				find = findDeviceStrict
+			}
+
+			var updated []controld.Device
+			var errs []error
+			for _, ref := range args {
+				device, err := find(devices, ref)
+				if err != nil {
+					errs = append(errs, err)
+					continue
+				}
+				deviceID := device.DeviceID
+
+				// Keep the original status when extending an existing
+				// schedule so the device is not restored to a disabled state.
+				restore := device.Status
+				if prev, ok := sched.Get(deviceID); ok && !prev.Until.IsZero() {
+					restore = prev.Restore
+				}
+
+				status := action.status
+				result, err := client.UpdateDevice(cmd.Context(), controld.UpdateDeviceParams{
+					DeviceID: deviceID,
+					Status:   &status,
+				})
+				if err != nil {
+					errs = append(errs, fmt.Errorf("%s: %w", deviceID, err))
+					continue
+				}
+				updated = append(updated, result)
+
+				if until != "" || reason != "" {
+					sched.Put(schedule.Entry{
+						DeviceID:  deviceID,
+						Name:      device.Name,
+						Status:    action.status,
+						Restore:   restore,
+						Until:     expires,
+						Reason:    reason,
+						CreatedAt: time.Now().UTC(),
+					})
+				} else {
+					sched.Remove(deviceID)
+				}
+
+				if outfmt.IsText(cmd.Context()) {
+					msg := fmt.Sprintf("%s device: %s (%s)", action.verb, device.Name, deviceID)
+					if !expires.IsZero() {
+						msg += fmt.Sprintf(" until %s", expires.Local().Format("2006-01-02 15:04"))
+					}
+					u.Success(msg)
+				}
+			}
+
+			if err := sched.Save(); err != nil {
+				errs = append(errs, fmt.Errorf("failed to save schedule: %w", err))
+			}
+
+			if !outfmt.IsText(cmd.Context()) {
+				if err := outfmt.Write(cmd.Context(), os.Stdout, updated, deviceTable(updated)); err != nil {
+					return err
+				}
+			}
+			return errors.Join(errs...)
+		},
+	}
+
+	cmd.Flags().StringVar(&until, "until", "", "Restore the previous status after this duration (e.g. 2h, 3d)")
+	cmd.Flags().StringVar(&reason, "reason", "", "Reason recorded in the local schedule")
+	if action.temporary {
+		_ = cmd.MarkFlagRequired("until")
+	}
+	return cmd
+}
+
+func newDevicesPendingCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "pending",
+		Short: "List devices waiting in pending status",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := getClient(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			devices, err := client.ListDevices(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			var pending []controld.Device
+			for _, d := range devices {
+				if d.Status == controld.Pending {
+					pending = append(pending, d)
+				}
+			}
+			sort.Slice(pending, func(i, j int) bool {
+				return pending[i].Ts.Before(pending[j].Ts.Time)
+			})
+
+			now := time.Now()
+			t := &outfmt.Table{
+				Columns: []outfmt.Column{
+					{Name: "device_id"},
+					{Name: "name"},
+					{Name: "profile"},
+					{Name: "created"},
+					{Name: "waiting"},
+				},
+				Empty: "No pending devices",
+			}
+			for _, d := range pending {
+				t.AddRow(d.DeviceID, d.Name, d.Profile.Name,
+					d.Ts.Format("2006-01-02 15:04"), formatAge(now.Sub(d.Ts.Time)))
+			}
+			return outfmt.Write(cmd.Context(), os.Stdout, pending, t)
+		},
+	}
+}
+
+func newDevicesScheduleCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "schedule",
+		Short: "Show scheduled device status changes",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sched, err := schedule.Open()
+			if err != nil {
+				return err
+			}
+
+			entries := sched.Entries()
+
+			t := &outfmt.Table{
+				Columns: []outfmt.Column{
+					{Name: "device_id"},
+					{Name: "name"},
+					{Name: "status", Status: true},
+					{Name: "restore", Status: true},
+					{Name: "until"},
+					{Name: "reason"},
+				},
+				Empty: "No scheduled device changes",
+			}
+			for _, e := range entries {
+				until := "-"
+				if !e.Until.IsZero() {
+					until = e.Until.Local().Format("2006-01-02 15:04")
+				}
+				t.AddRow(e.DeviceID, e.Name, deviceStatusToString(e.Status),
+					deviceStatusToString(e.Restore), until, e.Reason)
+			}
+			return outfmt.Write(cmd.Context(), os.Stdout, entries, t)
+		},
+	}
+	cmd.AddCommand(newDevicesScheduleApplyCmd())
+	return cmd
+}
+
+func newDevicesScheduleApplyCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "apply",
+		Short: "Restore devices whose scheduled change has expired",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			u := ui.FromContext(cmd.Context())
+
+			sched, err := schedule.Open()
+			if err != nil {
+				return err
+			}
+
+			due := sched.Due(time.Now())
+			if len(due) == 0 {
+				if !outfmt.IsText(cmd.Context()) {
+					return outfmt.Write(cmd.Context(), os.Stdout, []controld.Device{}, deviceTable(nil))
+				}
+				u.Info("No scheduled changes are due")
+				return nil
+			}
+
+			client, err := getClient(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			var updated []controld.Device
+			var errs []error
+			for _, e := range due {
+				status := e.Restore
+				device, err := client.UpdateDevice(cmd.Context(), controld.UpdateDeviceParams{
+					DeviceID: e.DeviceID,
+					Status:   &status,
+				})
+				if err != nil {
+					errs = append(errs, fmt.Errorf("%s: %w", e.DeviceID, err))
+					continue
+				}
+				updated = append(updated, device)
+				sched.Remove(e.DeviceID)
+
+				if outfmt.IsText(cmd.Context()) {
+					u.Success(fmt.Sprintf("Restored device: %s (%s) to %s", e.Name, e.DeviceID, deviceStatusToString(e.Restore)))
+				}
+			}
+
+			if err := sched.Save(); err != nil {
+				errs = append(errs, fmt.Errorf("failed to save schedule: %w", err))
+			}
+
+			if !outfmt.IsText(cmd.Context()) {
+				if err := outfmt.Write(cmd.Context(), os.Stdout, updated, deviceTable(updated)); err != nil {
+					return err
+				}
+			}
+			return errors.Join(errs...)
+		},
+	}
+}
+
+func deviceStatusToString(status controld.DeviceStatus) string {
+	switch status {
+	case controld.Active:
+		return "active"
+	case controld.SoftDisabled:
+		return "soft-disabled"
+	case controld.HardDisabled:
+		return "hard-disabled"
+	default:
+		return "pending"
+	}
+}
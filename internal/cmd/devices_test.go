@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/salmonumbrella/controld-cli/internal/controld"
+	"github.com/salmonumbrella/controld-cli/internal/controld/controldsim"
+)
+
+func TestDevicesList(t *testing.T) {
+	srv := newTestServer(t)
+	srv.Seed()
+
+	out, err := runCmd(t, "devices", "list")
+	require.NoError(t, err)
+	assert.Contains(t, out, "Laptop")
+	assert.Contains(t, out, "Tablet")
+
+	out, err = runCmd(t, "--output", "json", "devices", "list")
+	require.NoError(t, err)
+	var devices []controld.Device
+	require.NoError(t, json.Unmarshal([]byte(out), &devices))
+	assert.Len(t, devices, 3)
+}
+
+func TestDevicesListFormats(t *testing.T) {
+	srv := newTestServer(t)
+	srv.Seed()
+
+	out, err := runCmd(t, "--output", "csv", "devices", "list")
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	require.Len(t, lines, 4)
+	assert.Equal(t, "device_id,name,status,profile", lines[0])
+
+	out, err = runCmd(t, "--output", "ndjson", "devices", "list")
+	require.NoError(t, err)
+	assert.Len(t, strings.Split(strings.TrimSpace(out), "\n"), 3)
+
+	out, err = runCmd(t, "--output", "yaml", "devices", "get", "laptop")
+	require.NoError(t, err)
+	assert.Contains(t, out, "name: Laptop\n")
+
+	out, err = runCmd(t, "--output", "template={{.Name}}", "devices", "list")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"Laptop", "Phone", "Tablet"}, strings.Fields(out))
+
+	_, err = runCmd(t, "--output", "xml", "devices", "list")
+	assert.ErrorContains(t, err, "invalid output format")
+}
+
+func TestDevicesListQueryAndFields(t *testing.T) {
+	srv := newTestServer(t)
+	srv.Seed()
+
+	out, err := runCmd(t, "--query", "[?name=='Tablet'].profile.name", "devices", "list")
+	require.NoError(t, err)
+	assert.Equal(t, "Kids\n", out)
+
+	out, err = runCmd(t, "--fields", "name,profile.name", "--output", "csv", "devices", "get", "laptop")
+	require.NoError(t, err)
+	assert.Equal(t, "name,profile.name\nLaptop,Home\n", out)
+
+	_, err = runCmd(t, "--query", "[?", "devices", "list")
+	assert.ErrorContains(t, err, "invalid query")
+}
+
+func TestDevicesListTableOptions(t *testing.T) {
+	srv := newTestServer(t)
+	srv.Seed()
+
+	out, err := runCmd(t, "--no-headers", "--sort-by", "name", "--output", "tsv", "devices", "list")
+	require.NoError(t, err)
+	var names []string
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		names = append(names, strings.Split(line, "\t")[1])
+	}
+	assert.Equal(t, []string{"Laptop", "Phone", "Tablet"}, names)
+
+	out, err = runCmd(t, "devices", "list")
+	require.NoError(t, err)
+	assert.NotContains(t, out, "DOH")
+
+	out, err = runCmd(t, "--wide", "devices", "list")
+	require.NoError(t, err)
+	assert.Contains(t, out, "DOH")
+	assert.Contains(t, out, "ANALYTICS")
+}
+
+func TestDevicesCreateModifyDelete(t *testing.T) {
+	srv := newTestServer(t)
+	srv.AddProfile("Home")
+	kids := srv.AddProfile("Kids")
+
+	_, err := runCmd(t, "devices", "create", "--name", "Console", "--profile-id", "home")
+	require.NoError(t, err)
+	devices := srv.Devices()
+	require.Len(t, devices, 1)
+	assert.Equal(t, "Home", devices[0].Profile.Name)
+
+	_, err = runCmd(t, "devices", "modify", "console", "--profile-id", "Kids")
+	require.NoError(t, err)
+	device, _ := srv.Device(devices[0].DeviceID)
+	assert.Equal(t, kids.PK, device.Profile.PK)
+
+	// Destructive commands do not resolve fuzzy references.
+	_, err = runCmd(t, "--yes", "devices", "delete", "cnsl")
+	assert.ErrorContains(t, err, "Did you mean Console")
+	assert.Len(t, srv.Devices(), 1)
+
+	_, err = runCmd(t, "--yes", "devices", "delete", "Console")
+	require.NoError(t, err)
+	assert.Empty(t, srv.Devices())
+}
+
+func TestDevicesDisableWithSchedule(t *testing.T) {
+	srv := newTestServer(t)
+	device := srv.AddDevice("Laptop", srv.AddProfile("Home").PK)
+
+	_, err := runCmd(t, "devices", "suspend", "laptop", "--until", "2h", "--reason", "homework")
+	require.NoError(t, err)
+	got, _ := srv.Device(device.DeviceID)
+	assert.Equal(t, controld.DeviceStatus(controld.SoftDisabled), got.Status)
+
+	out, err := runCmd(t, "devices", "schedule")
+	require.NoError(t, err)
+	assert.Contains(t, out, "homework")
+
+	// Nothing is due yet, so apply leaves the device disabled.
+	_, err = runCmd(t, "devices", "schedule", "apply")
+	require.NoError(t, err)
+	got, _ = srv.Device(device.DeviceID)
+	assert.Equal(t, controld.DeviceStatus(controld.SoftDisabled), got.Status)
+
+	_, err = runCmd(t, "devices", "enable", "laptop")
+	require.NoError(t, err)
+	got, _ = srv.Device(device.DeviceID)
+	assert.Equal(t, controld.DeviceStatus(controld.Active), got.Status)
+
+	out, err = runCmd(t, "devices", "schedule")
+	require.NoError(t, err)
+	assert.Contains(t, out, "No scheduled device changes")
+}
+
+func TestDevicesAPIErrors(t *testing.T) {
+	srv := newTestServer(t)
+
+	srv.Inject(controldsim.Fault{Path: "/devices", Status: http.StatusUnauthorized, Message: "Invalid API token"})
+	_, err := runCmd(t, "devices", "list")
+	assert.ErrorContains(t, err, "Invalid API token")
+
+	srv.ClearFaults()
+	_, err = runCmd(t, "devices", "get", "missing")
+	assert.ErrorContains(t, err, "not found")
+}
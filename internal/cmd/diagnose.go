@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/salmonumbrella/controld-cli/internal/config"
+	"github.com/salmonumbrella/controld-cli/internal/controld"
+	"github.com/salmonumbrella/controld-cli/internal/ui"
+)
+
+// captivePortalHints and proxyHints are phrases found in pages served by
+// captive portals and by corporate proxies or web filters.
+var (
+	captivePortalHints = []string{"captive", "hotspot", "wi-fi", "wifi", "log in", "login", "sign in", "terms of use", "accept the terms", "portal"}
+	proxyHints         = []string{"proxy", "zscaler", "bluecoat", "blue coat", "forcepoint", "websense", "fortiguard", "squid", "web filter", "blocked", "access denied"}
+)
+
+// printDiagnosis explains err on stderr when it suggests the CLI is not
+// talking to the ControlD API.
+func printDiagnosis(err error) {
+	if hint := diagnose(err); hint != "" {
+		ui.New(flags.Color).Warn(hint)
+	}
+}
+
+// diagnose returns advice for errors that suggest something other than the
+// ControlD API answered, or that the connection was intercepted. It returns ""
+// for other errors.
+func diagnose(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	var unexpected *controld.UnexpectedResponseError
+	if errors.As(err, &unexpected) {
+		return diagnoseResponse(unexpected)
+	}
+
+	var unknownAuthority x509.UnknownAuthorityError
+	if errors.As(err, &unknownAuthority) {
+		return "The server's TLS certificate is not trusted. A corporate proxy may be intercepting HTTPS traffic; " +
+			"ask your network administrator, or add the proxy's CA certificate to your system trust store."
+	}
+
+	if strings.Contains(err.Error(), "proxyconnect") {
+		return "Could not connect through the configured proxy. Check HTTPS_PROXY and HTTP_PROXY."
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+		return fmt.Sprintf("Could not resolve %s. Check your network connection%s.", dnsErr.Name, baseURLAdvice())
+	}
+
+	return ""
+}
+
+func diagnoseResponse(e *controld.UnexpectedResponseError) string {
+	text := strings.ToLower(e.Title + " " + e.Snippet)
+	containsAny := func(hints []string) bool {
+		for _, h := range hints {
+			if strings.Contains(text, h) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch {
+	case e.StatusCode == http.StatusProxyAuthRequired || containsAny(proxyHints):
+		return "The reply came from a proxy or web filter, not the ControlD API. Check HTTPS_PROXY and HTTP_PROXY, " +
+			"or ask your network administrator to allow api.controld.com."
+	case e.IsHTML() && containsAny(captivePortalHints):
+		return "The reply looks like a captive portal (a Wi-Fi login page). Sign in to the network in a browser, then try again."
+	case baseURLAdvice() != "" || e.StatusCode == http.StatusNotFound || e.StatusCode == http.StatusMethodNotAllowed:
+		return fmt.Sprintf("The server at %s does not look like the ControlD API. Check the API base URL%s.", e.URL, baseURLAdvice())
+	default:
+		return "The reply did not come from the ControlD API. A proxy, firewall or captive portal on your network may be answering instead."
+	}
+}
+
+// baseURLAdvice mentions the base URL override when one is set, by the
+// environment or the config context.
+func baseURLAdvice() string {
+	if v := os.Getenv(config.EnvBaseURL); v != "" {
+		return fmt.Sprintf(" (%s is set to %q)", config.EnvBaseURL, v)
+	}
+	if settings.BaseURL != "" {
+		return fmt.Sprintf(" (the config context's base-url is set to %q)", settings.BaseURL)
+	}
+	return ""
+}
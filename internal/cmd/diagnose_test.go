@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/salmonumbrella/controld-cli/internal/config"
+	"github.com/salmonumbrella/controld-cli/internal/controld"
+)
+
+func TestDiagnose(t *testing.T) {
+	t.Setenv(config.EnvBaseURL, "")
+	prev := settings
+	settings = &config.Context{}
+	t.Cleanup(func() { settings = prev })
+
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"nil", nil, ""},
+		{"other", errors.New("boom"), ""},
+		{"captive portal", &controld.UnexpectedResponseError{
+			StatusCode: 200, ContentType: "text/html", Title: "Guest Wi-Fi Login",
+		}, "captive portal"},
+		{"proxy page", &controld.UnexpectedResponseError{
+			StatusCode: 403, ContentType: "text/html", Title: "Zscaler - Website blocked",
+		}, "proxy or web filter"},
+		{"proxy auth", &controld.UnexpectedResponseError{StatusCode: 407}, "proxy or web filter"},
+		{"not found page", &controld.UnexpectedResponseError{
+			StatusCode: 404, ContentType: "text/html", URL: "https://example.com/devices", Title: "Not Found",
+		}, "https://example.com/devices does not look like the ControlD API"},
+		{"unknown page", &controld.UnexpectedResponseError{StatusCode: 400, ContentType: "text/html"}, "did not come from the ControlD API"},
+		{"tls interception", fmt.Errorf("HTTP request failed: %w", x509.UnknownAuthorityError{}), "TLS certificate is not trusted"},
+		{"dns", fmt.Errorf("wrapped: %w", &net.DNSError{Name: "api.controld.test", IsNotFound: true}), "Could not resolve api.controld.test"},
+	}
+
+	for _, tt := range tests {
+		got := diagnose(tt.err)
+		if tt.want == "" {
+			assert.Empty(t, got, tt.name)
+		} else {
+			assert.Contains(t, got, tt.want, tt.name)
+		}
+	}
+}
+
+func TestDiagnoseMentionsBaseURL(t *testing.T) {
+	t.Setenv(config.EnvBaseURL, "http://localhost:9999")
+
+	got := diagnose(&controld.UnexpectedResponseError{StatusCode: 200, ContentType: "text/html", URL: "http://localhost:9999/users"})
+	assert.Contains(t, got, `CONTROLD_BASE_URL is set to "http://localhost:9999"`)
+
+	t.Setenv(config.EnvBaseURL, "")
+	prev := settings
+	settings = &config.Context{BaseURL: "http://localhost:8888"}
+	t.Cleanup(func() { settings = prev })
+	got = diagnose(&controld.UnexpectedResponseError{StatusCode: 200, ContentType: "text/html", URL: "http://localhost:8888/users"})
+	assert.Contains(t, got, "does not look like the ControlD API")
+	assert.Contains(t, got, `base-url is set to "http://localhost:8888"`)
+}
+
+func TestHTMLErrorPageEndToEnd(t *testing.T) {
+	newTestServer(t)
+	page := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = io.WriteString(w, "<html><head><title>Web Filter: Access Denied</title></head></html>")
+	}))
+	t.Cleanup(page.Close)
+	t.Setenv(config.EnvBaseURL, page.URL)
+
+	_, err := runCmd(t, "--no-cache", "devices", "list")
+	var unexpected *controld.UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpected)
+	assert.Equal(t, "Web Filter: Access Denied", unexpected.Title)
+	assert.Contains(t, diagnose(err), "proxy or web filter")
+}
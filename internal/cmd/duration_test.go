@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"hours", "2h", 2 * time.Hour, false},
+		{"minutes", "90m", 90 * time.Minute, false},
+		{"days", "30d", 30 * 24 * time.Hour, false},
+		{"weeks", "2w", 14 * 24 * time.Hour, false},
+		{"days and hours", "1d12h", 36 * time.Hour, false},
+		{"fractional hours", "1.5h", 90 * time.Minute, false},
+		{"empty", "", 0, true},
+		{"no unit", "30", 0, true},
+		{"unknown unit", "3y", 0, true},
+		{"garbage", "soon", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseDuration(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFormatAge(t *testing.T) {
+	tests := []struct {
+		input time.Duration
+		want  string
+	}{
+		{30 * time.Second, "30s"},
+		{5 * time.Minute, "5m"},
+		{5*time.Hour + 12*time.Minute, "5h12m"},
+		{3*24*time.Hour + 4*time.Hour, "3d4h"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			assert.Equal(t, tt.want, formatAge(tt.input))
+		})
+	}
+}
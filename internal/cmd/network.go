@@ -3,9 +3,11 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strconv"
 
 	"github.com/spf13/cobra"
 
+	"github.com/salmonumbrella/controld-cli/internal/controld"
 	"github.com/salmonumbrella/controld-cli/internal/outfmt"
 )
 
@@ -34,27 +36,36 @@ func newNetworkStatusCmd() *cobra.Command {
 				return err
 			}
 
-			if outfmt.IsJSON(cmd.Context()) {
-				return outfmt.WriteJSON(os.Stdout, network)
+			t := &outfmt.Table{
+				Columns: []outfmt.Column{
+					{Name: "pop"},
+					{Name: "city"},
+					{Name: "country"},
+					{Name: "api"},
+					{Name: "dns"},
+					{Name: "proxy"},
+				},
 			}
-
-			tw := outfmt.NewTabWriter(os.Stdout)
-			_, _ = fmt.Fprintln(tw, "POP\tCITY\tCOUNTRY\tAPI\tDNS\tPROXY")
 			for _, n := range network {
-				_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%d\t%d\n",
-					n.IataCode, n.CityName, n.CountryName,
-					n.Status.API, n.Status.DNS, n.Status.Pxy)
+				t.AddRow(n.IataCode, n.CityName, n.CountryName,
+					strconv.Itoa(int(n.Status.API)), strconv.Itoa(int(n.Status.DNS)), strconv.Itoa(int(n.Status.Pxy)))
 			}
-			return tw.Flush()
+			return outfmt.Write(cmd.Context(), os.Stdout, network, t)
 		},
 	}
 }
 
 func newNetworkIPCmd() *cobra.Command {
-	return &cobra.Command{
+	var accounts accountsFlags
+
+	cmd := &cobra.Command{
 		Use:   "ip",
 		Short: "Show your current IP information",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if accounts.enabled() {
+				return forEachAccount(cmd, &accounts, (*controld.API).ListIP, ipTable)
+			}
+
 			client, err := getClient(cmd.Context())
 			if err != nil {
 				return err
@@ -65,17 +76,25 @@ func newNetworkIPCmd() *cobra.Command {
 				return err
 			}
 
-			if outfmt.IsJSON(cmd.Context()) {
-				return outfmt.WriteJSON(os.Stdout, ip)
-			}
+			return outfmt.Write(cmd.Context(), os.Stdout, ip, ipTable(ip))
+		},
+	}
+
+	accounts.register(cmd)
+	return cmd
+}
 
-			tw := outfmt.NewTabWriter(os.Stdout)
-			_, _ = fmt.Fprintf(tw, "ip\t%s\n", ip.IP)
-			_, _ = fmt.Fprintf(tw, "type\t%s\n", ip.Type)
-			_, _ = fmt.Fprintf(tw, "country\t%s\n", ip.Country)
-			_, _ = fmt.Fprintf(tw, "org\t%s\n", ip.Org)
-			_, _ = fmt.Fprintf(tw, "pop\t%s\n", ip.Pop)
-			return tw.Flush()
+func ipTable(ip controld.IP) *outfmt.Table {
+	t := &outfmt.Table{
+		Columns: []outfmt.Column{
+			{Name: "ip"},
+			{Name: "type"},
+			{Name: "country"},
+			{Name: "org"},
+			{Name: "pop"},
 		},
+		Detail: true,
 	}
+	t.AddRow(fmt.Sprint(ip.IP), ip.Type, ip.Country, ip.Org, ip.Pop)
+	return t
 }
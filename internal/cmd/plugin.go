@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/salmonumbrella/controld-cli/internal/api"
+	"github.com/salmonumbrella/controld-cli/internal/config"
+)
+
+const (
+	// pluginPrefix starts the name of every plugin executable.
+	pluginPrefix = "controld-"
+	// pluginAnnotation marks plugin commands and holds the executable path.
+	pluginAnnotation = "plugin"
+	pluginGroupID    = "plugins"
+)
+
+// addPlugins adds a command to root for each controld-<name> executable on
+// PATH. The first executable found for a name wins, and names already taken
+// by a built-in command or an alias are skipped.
+func addPlugins(root *cobra.Command) {
+	plugins := findPlugins()
+	if len(plugins) == 0 {
+		return
+	}
+
+	added := false
+	for _, p := range plugins {
+		if c, _, err := root.Find([]string{p.name}); err == nil && c != root {
+			continue
+		}
+		if !added {
+			root.AddGroup(&cobra.Group{ID: pluginGroupID, Title: "Plugin Commands:"})
+			added = true
+		}
+		root.AddCommand(newPluginCmd(p.name, p.path))
+	}
+}
+
+type plugin struct {
+	name string
+	path string
+}
+
+// findPlugins returns the plugin executables on PATH, in PATH order.
+func findPlugins() []plugin {
+	var plugins []plugin
+	seen := map[string]bool{}
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if dir == "" {
+			continue
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			name, ok := strings.CutPrefix(e.Name(), pluginPrefix)
+			if !ok || e.IsDir() {
+				continue
+			}
+			if runtime.GOOS == "windows" {
+				name = strings.TrimSuffix(name, filepath.Ext(name))
+			}
+			if name == "" || name == "help" || strings.HasPrefix(name, "__") || seen[name] {
+				continue
+			}
+			path := filepath.Join(dir, e.Name())
+			if !isExecutable(path) {
+				continue
+			}
+			seen[name] = true
+			plugins = append(plugins, plugin{name: name, path: path})
+		}
+	}
+	return plugins
+}
+
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		return strings.EqualFold(filepath.Ext(path), ".exe")
+	}
+	return info.Mode().Perm()&0o111 != 0
+}
+
+// newPluginCmd returns the command that runs a plugin. Global flags at the
+// start of its arguments are applied as usual; the rest are passed to the
+// plugin, together with the resolved settings in the environment:
+//
+//	CONTROLD_API_TOKEN  the API token, if one was found
+//	CONTROLD_ACCOUNT    the account name
+//	CONTROLD_OUTPUT     the output format
+//	CONTROLD_COLOR      the color mode
+//	CONTROLD_BASE_URL   the API base URL, if not the default
+func newPluginCmd(name, path string) *cobra.Command {
+	return &cobra.Command{
+		Use:                name + " [args...]",
+		Short:              "Plugin: " + path,
+		GroupID:            pluginGroupID,
+		Annotations:        map[string]string{pluginAnnotation: path},
+		DisableFlagParsing: true,
+		// The root hooks run once the global flags are parsed.
+		PersistentPreRunE: func(*cobra.Command, []string) error { return nil },
+		RunE: func(cmd *cobra.Command, args []string) error {
+			global, rest := splitGlobalFlags(cmd.InheritedFlags(), args)
+			if err := cmd.InheritedFlags().Parse(global); err != nil {
+				return err
+			}
+			if err := cmd.Root().PersistentPreRunE(cmd, rest); err != nil {
+				return err
+			}
+
+			baseURL := os.Getenv(config.EnvBaseURL)
+			if baseURL == "" {
+				baseURL = settings.BaseURL
+			}
+			env := os.Environ()
+			for _, kv := range [][2]string{
+				{config.EnvToken, api.ResolveToken(api.ClientConfig{Token: flags.Token, Account: flags.Account})},
+				{config.EnvAccount, flags.Account},
+				{config.EnvOutput, flags.Output},
+				{config.EnvColor, flags.Color},
+				{config.EnvBaseURL, baseURL},
+			} {
+				if kv[1] != "" {
+					env = append(env, kv[0]+"="+kv[1])
+				}
+			}
+
+			c := exec.CommandContext(cmd.Context(), path, rest...)
+			c.Env = env
+			c.Stdin = os.Stdin
+			c.Stdout = os.Stdout
+			c.Stderr = os.Stderr
+			err := c.Run()
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) {
+				// The plugin reported its own error; only its exit code
+				// is passed on.
+				cmd.SilenceErrors = true
+			}
+			return err
+		},
+	}
+}
+
+func isPlugin(cmd *cobra.Command) bool {
+	_, ok := cmd.Annotations[pluginAnnotation]
+	return ok
+}
+
+// splitGlobalFlags splits args into the leading flags defined in fs and the
+// rest. A "--" ends the global flags and is dropped.
+func splitGlobalFlags(fs *pflag.FlagSet, args []string) (global, rest []string) {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			return global, args[i+1:]
+		}
+		if len(arg) < 2 || arg[0] != '-' {
+			return global, args[i:]
+		}
+
+		var f *pflag.Flag
+		hasValue := false
+		if name, ok := strings.CutPrefix(arg, "--"); ok {
+			name, _, hasValue = strings.Cut(name, "=")
+			f = fs.Lookup(name)
+		} else {
+			f = fs.ShorthandLookup(arg[1:2])
+			hasValue = len(arg) > 2
+		}
+		if f == nil {
+			return global, args[i:]
+		}
+
+		global = append(global, arg)
+		if !hasValue && f.NoOptDefVal == "" && i+1 < len(args) {
+			i++
+			global = append(global, args[i])
+		}
+	}
+	return global, nil
+}
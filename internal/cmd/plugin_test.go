@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlugins(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugins are shell scripts")
+	}
+	newTestServer(t)
+
+	dir := t.TempDir()
+	writePlugin := func(name, script string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "controld-"+name), []byte("#!/bin/sh\n"+script+"\n"), 0o755))
+	}
+	writePlugin("hello", `echo "$CONTROLD_API_TOKEN $CONTROLD_ACCOUNT $CONTROLD_OUTPUT $*"`)
+	writePlugin("fail", "exit 3")
+	writePlugin("devices", "echo shadowed")
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	// Leading global flags are applied; the rest go to the plugin.
+	out, err := runCmd(t, "--output", "json", "hello", "--account", "work", "arg", "--output", "x")
+	require.NoError(t, err)
+	assert.Equal(t, "test-token work json arg --output x\n", out)
+
+	out, err = runCmd(t, "--help")
+	require.NoError(t, err)
+	assert.Contains(t, out, "Plugin Commands:")
+	assert.Contains(t, out, "hello")
+
+	// Built-in commands win over plugins.
+	out, err = runCmd(t, "devices", "--help")
+	require.NoError(t, err)
+	assert.NotContains(t, out, "shadowed")
+
+	_, err = runCmd(t, "fail")
+	var exitErr *exec.ExitError
+	require.True(t, errors.As(err, &exitErr))
+	assert.Equal(t, 3, exitErr.ExitCode())
+}
@@ -21,6 +21,7 @@ func newProfilesCmd() *cobra.Command {
 	cmd.AddCommand(newProfilesCreateCmd())
 	cmd.AddCommand(newProfilesModifyCmd())
 	cmd.AddCommand(newProfilesDeleteCmd())
+	cmd.AddCommand(newProfilesCopyCmd())
 	cmd.AddCommand(newProfilesRulesCmd())
 	cmd.AddCommand(newProfilesFiltersCmd())
 	cmd.AddCommand(newProfilesServicesCmd())
@@ -28,10 +29,16 @@ func newProfilesCmd() *cobra.Command {
 }
 
 func newProfilesListCmd() *cobra.Command {
-	return &cobra.Command{
+	var accounts accountsFlags
+
+	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List all profiles",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if accounts.enabled() {
+				return forEachAccount(cmd, &accounts, (*controld.API).ListProfiles, profileTable)
+			}
+
 			client, err := getClient(cmd.Context())
 			if err != nil {
 				return err
@@ -42,63 +49,60 @@ func newProfilesListCmd() *cobra.Command {
 				return err
 			}
 
-			if outfmt.IsJSON(cmd.Context()) {
-				return outfmt.WriteJSON(os.Stdout, profiles)
-			}
+			return outfmt.Write(cmd.Context(), os.Stdout, profiles, profileTable(profiles))
+		},
+	}
 
-			if len(profiles) == 0 {
-				fmt.Println("No profiles found")
-				return nil
-			}
+	accounts.register(cmd)
+	return cmd
+}
 
-			tw := outfmt.NewTabWriter(os.Stdout)
-			_, _ = fmt.Fprintln(tw, "PROFILE_ID\tNAME\tUPDATED")
-			for _, p := range profiles {
-				_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\n", p.PK, p.Name, p.Updated.Format("2006-01-02"))
-			}
-			return tw.Flush()
+func profileTable(profiles []controld.Profile) *outfmt.Table {
+	t := &outfmt.Table{
+		Columns: []outfmt.Column{
+			{Name: "profile_id"},
+			{Name: "name"},
+			{Name: "updated"},
 		},
+		Empty: "No profiles found",
 	}
+	for _, p := range profiles {
+		t.AddRow(p.PK, p.Name, p.Updated.Format("2006-01-02"))
+	}
+	return t
 }
 
 func newProfilesGetCmd() *cobra.Command {
 	return &cobra.Command{
-		Use:   "get <profile-id>",
-		Short: "Get profile details",
-		Args:  cobra.ExactArgs(1),
+		Use:               "get [profile-id]",
+		Short:             "Get profile details",
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeArgs(completeProfiles),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			client, err := getClient(cmd.Context())
 			if err != nil {
 				return err
 			}
 
-			profiles, err := client.ListProfiles(cmd.Context())
+			ref, err := profileRef(args)
 			if err != nil {
 				return err
 			}
-
-			profileID := args[0]
-			var profile *controld.Profile
-			for i := range profiles {
-				if profiles[i].PK == profileID {
-					profile = &profiles[i]
-					break
-				}
-			}
-
-			if profile == nil {
-				return fmt.Errorf("profile not found: %s", profileID)
+			profile, err := resolveProfile(cmd.Context(), client, ref)
+			if err != nil {
+				return err
 			}
 
-			if outfmt.IsJSON(cmd.Context()) {
-				return outfmt.WriteJSON(os.Stdout, profile)
+			t := &outfmt.Table{
+				Columns: []outfmt.Column{
+					{Name: "profile_id"},
+					{Name: "name"},
+					{Name: "updated"},
+				},
+				Detail: true,
 			}
-
-			tw := outfmt.NewTabWriter(os.Stdout)
-			_, _ = fmt.Fprintf(tw, "profile_id\t%s\n", profile.PK)
-			_, _ = fmt.Fprintf(tw, "name\t%s\n", profile.Name)
-			_, _ = fmt.Fprintf(tw, "updated\t%s\n", profile.Updated.Format("2006-01-02 15:04:05"))
-			return tw.Flush()
+			t.AddRow(profile.PK, profile.Name, profile.Updated.Format("2006-01-02 15:04:05"))
+			return outfmt.Write(cmd.Context(), os.Stdout, profile, t)
 		},
 	}
 }
@@ -119,7 +123,11 @@ func newProfilesCreateCmd() *cobra.Command {
 
 			params := controld.CreateProfileParams{Name: name}
 			if cloneFrom != "" {
-				params.CloneProfileID = &cloneFrom
+				cloneID, err := resolveProfileID(cmd.Context(), client, cloneFrom)
+				if err != nil {
+					return err
+				}
+				params.CloneProfileID = &cloneID
 			}
 
 			profiles, err := client.CreateProfile(cmd.Context(), params)
@@ -127,8 +135,8 @@ func newProfilesCreateCmd() *cobra.Command {
 				return err
 			}
 
-			if outfmt.IsJSON(cmd.Context()) {
-				return outfmt.WriteJSON(os.Stdout, profiles)
+			if !outfmt.IsText(cmd.Context()) {
+				return outfmt.Write(cmd.Context(), os.Stdout, profiles, profileTable(profiles))
 			}
 
 			if len(profiles) > 0 {
@@ -141,6 +149,7 @@ func newProfilesCreateCmd() *cobra.Command {
 	cmd.Flags().StringVar(&name, "name", "", "Profile name (required)")
 	cmd.Flags().StringVar(&cloneFrom, "clone-from", "", "Clone from existing profile ID")
 	_ = cmd.MarkFlagRequired("name")
+	_ = cmd.RegisterFlagCompletionFunc("clone-from", completeFlag(completeProfiles))
 	return cmd
 }
 
@@ -148,9 +157,10 @@ func newProfilesModifyCmd() *cobra.Command {
 	var name string
 
 	cmd := &cobra.Command{
-		Use:   "modify <profile-id>",
-		Short: "Modify an existing profile",
-		Args:  cobra.ExactArgs(1),
+		Use:               "modify <profile-id>",
+		Short:             "Modify an existing profile",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeArgs(completeProfiles),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			u := ui.FromContext(cmd.Context())
 			client, err := getClient(cmd.Context())
@@ -158,8 +168,13 @@ func newProfilesModifyCmd() *cobra.Command {
 				return err
 			}
 
+			profileID, err := resolveProfileID(cmd.Context(), client, args[0])
+			if err != nil {
+				return err
+			}
+
 			params := controld.UpdateProfileParams{
-				ProfileID: args[0],
+				ProfileID: profileID,
 			}
 
 			if cmd.Flags().Changed("name") {
@@ -171,8 +186,8 @@ func newProfilesModifyCmd() *cobra.Command {
 				return err
 			}
 
-			if outfmt.IsJSON(cmd.Context()) {
-				return outfmt.WriteJSON(os.Stdout, profiles)
+			if !outfmt.IsText(cmd.Context()) {
+				return outfmt.Write(cmd.Context(), os.Stdout, profiles, profileTable(profiles))
 			}
 
 			if len(profiles) > 0 {
@@ -188,9 +203,10 @@ func newProfilesModifyCmd() *cobra.Command {
 
 func newProfilesDeleteCmd() *cobra.Command {
 	return &cobra.Command{
-		Use:   "delete <profile-id>",
-		Short: "Delete a profile",
-		Args:  cobra.ExactArgs(1),
+		Use:               "delete <profile-id>",
+		Short:             "Delete a profile",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeArgs(completeProfiles),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			u := ui.FromContext(cmd.Context())
 			client, err := getClient(cmd.Context())
@@ -198,8 +214,13 @@ func newProfilesDeleteCmd() *cobra.Command {
 				return err
 			}
 
+			profile, err := resolveProfileStrict(cmd.Context(), client, args[0])
+			if err != nil {
+				return err
+			}
+
 			if !outfmt.GetYes(cmd.Context()) {
-				_, _ = fmt.Fprintf(os.Stderr, "Delete profile %s? [y/N]: ", args[0])
+				_, _ = fmt.Fprintf(os.Stderr, "Delete profile %s (%s)? [y/N]: ", profile.Name, profile.PK)
 				var confirm string
 				_, _ = fmt.Scanln(&confirm)
 				if confirm != "y" && confirm != "Y" {
@@ -209,13 +230,13 @@ func newProfilesDeleteCmd() *cobra.Command {
 			}
 
 			_, err = client.DeleteProfile(cmd.Context(), controld.DeleteProfileParams{
-				ProfileID: args[0],
+				ProfileID: profile.PK,
 			})
 			if err != nil {
 				return err
 			}
 
-			u.Success(fmt.Sprintf("Deleted profile: %s", args[0]))
+			u.Success(fmt.Sprintf("Deleted profile: %s", profile.PK))
 			return nil
 		},
 	}
@@ -0,0 +1,385 @@
+package cmd
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/salmonumbrella/controld-cli/internal/controld"
+	"github.com/salmonumbrella/controld-cli/internal/outfmt"
+	"github.com/salmonumbrella/controld-cli/internal/ui"
+)
+
+// profileCopy is a profile's configuration as copied between accounts.
+type profileCopy struct {
+	Name        string `json:"name"`
+	FromAccount string `json:"from_account"`
+	ToAccount   string `json:"to_account"`
+	// ProfileID is the copy's ID in the destination account, once created.
+	ProfileID string               `json:"profile_id,omitempty"`
+	Filters   []copiedFilter       `json:"filters"`
+	Services  []copiedService      `json:"services"`
+	Folders   []controld.Group     `json:"folders"`
+	Rules     []controld.Rule      `json:"rules"`
+	Default   controld.DefaultRule `json:"default"`
+	Options   []controld.Opt       `json:"options"`
+}
+
+// copiedFilter is an enabled filter. Level is the name of its enabled level,
+// for filters that have levels.
+type copiedFilter struct {
+	PK    string `json:"PK"`
+	Level string `json:"level,omitempty"`
+}
+
+type copiedService struct {
+	PK     string          `json:"PK"`
+	Action controld.Action `json:"action"`
+}
+
+func newProfilesCopyCmd() *cobra.Command {
+	var fromAccount, toAccount, name string
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "copy <profile>",
+		Short: "Copy a profile to another account",
+		Long: `Copy a profile to another account.
+
+The profile's enabled filters, services, rule folders, custom rules, default
+rule and options are read from the source account and recreated in a new
+profile in the destination account. Use --name to give the copy a different
+name, and --dry-run to preview what would be created.
+
+Accounts are keyring accounts, as added with 'controld auth login --name'.
+The source defaults to the current account.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeArgs(completeProfiles),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			u := ui.FromContext(ctx)
+
+			if fromAccount == "" {
+				fromAccount = flags.Account
+			}
+			var src *controld.API
+			var err error
+			if fromAccount != "" {
+				src, err = accountClient(ctx, fromAccount)
+			} else {
+				src, err = getClient(ctx)
+			}
+			if err != nil {
+				return err
+			}
+			dst, err := accountClient(ctx, toAccount)
+			if err != nil {
+				return err
+			}
+
+			pc, err := exportProfile(ctx, src, args[0])
+			if err != nil {
+				return err
+			}
+			pc.FromAccount, pc.ToAccount = fromAccount, toAccount
+			if name != "" {
+				pc.Name = name
+			}
+
+			existing, err := dst.ListProfiles(ctx)
+			if err != nil {
+				return err
+			}
+			for _, p := range existing {
+				if p.Name == pc.Name {
+					return fmt.Errorf("account %s already has a profile named %q (%s). Pick another name with --name", toAccount, pc.Name, p.PK)
+				}
+			}
+
+			if dryRun {
+				if err := outfmt.Write(ctx, os.Stdout, pc, profileCopyTable(pc)); err != nil {
+					return err
+				}
+				if outfmt.IsText(ctx) {
+					u.Info(fmt.Sprintf("Would create profile %q in account %s", pc.Name, toAccount))
+				}
+				return nil
+			}
+
+			if err := importProfile(ctx, dst, pc); err != nil {
+				if pc.ProfileID == "" {
+					return err
+				}
+				return fmt.Errorf("profile %s was created in account %s but copying stopped: %w. Delete it with: controld profiles delete %s --account %s",
+					pc.ProfileID, toAccount, err, pc.ProfileID, toAccount)
+			}
+
+			if !outfmt.IsText(ctx) {
+				return outfmt.Write(ctx, os.Stdout, pc, profileCopyTable(pc))
+			}
+			u.Success(fmt.Sprintf("Copied profile to account %s: %s (%s) with %d filter(s), %d service(s), %d folder(s), %d rule(s) and %d option(s)",
+				toAccount, pc.Name, pc.ProfileID, len(pc.Filters), len(pc.Services), len(pc.Folders), len(pc.Rules), len(pc.Options)))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&fromAccount, "from-account", "", "Keyring account to copy from (default: current account)")
+	cmd.Flags().StringVar(&toAccount, "to-account", "", "Keyring account to copy to (required)")
+	cmd.Flags().StringVar(&name, "name", "", "Name of the copy (default: the source profile's name)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be copied without creating anything")
+	_ = cmd.MarkFlagRequired("to-account")
+	_ = cmd.RegisterFlagCompletionFunc("from-account", completeAccountNames)
+	_ = cmd.RegisterFlagCompletionFunc("to-account", completeAccountNames)
+	return cmd
+}
+
+// exportProfile reads the configuration of the profile ref names.
+func exportProfile(ctx context.Context, client *controld.API, ref string) (*profileCopy, error) {
+	profiles, err := client.ListProfiles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	profile, err := findProfile(profiles, ref)
+	if err != nil {
+		return nil, err
+	}
+	pc := &profileCopy{Name: profile.Name}
+	if profile.Settings != nil {
+		pc.Options = profile.Settings.Options.Data
+	}
+
+	filters, err := listProfileFilters(ctx, client, profile.PK)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range filters {
+		if !f.Status {
+			continue
+		}
+		cf := copiedFilter{PK: f.PK}
+		for _, l := range f.Levels {
+			if l.Status {
+				cf.Level = l.Name
+			}
+		}
+		pc.Filters = append(pc.Filters, cf)
+	}
+
+	services, err := client.ListProfileServices(ctx, controld.ListProfileServicesParams{ProfileID: profile.PK})
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range services {
+		pc.Services = append(pc.Services, copiedService{PK: s.PK, Action: s.Action})
+	}
+
+	pc.Folders, err = client.ListProfileRuleFolders(ctx, controld.ListProfileRuleFoldersParams{ProfileID: profile.PK})
+	if err != nil {
+		return nil, err
+	}
+	// Folder 0 holds the rules outside any folder.
+	for _, folderID := range append([]int{0}, folderIDs(pc.Folders)...) {
+		rules, err := client.ListProfileCustomRules(ctx, controld.ListProfileCustomRulesParams{
+			ProfileID: profile.PK,
+			FolderID:  strconv.Itoa(folderID),
+		})
+		if err != nil {
+			return nil, err
+		}
+		pc.Rules = append(pc.Rules, rules...)
+	}
+
+	pc.Default, err = client.ListProfileDefaultRule(ctx, controld.ListProfileDefaultRuleParams{ProfileID: profile.PK})
+	if err != nil {
+		return nil, err
+	}
+	return pc, nil
+}
+
+func folderIDs(folders []controld.Group) []int {
+	ids := make([]int, len(folders))
+	for i, f := range folders {
+		ids[i] = f.PK
+	}
+	return ids
+}
+
+// importProfile creates pc in the account client belongs to and sets
+// pc.ProfileID as soon as the profile exists. Rules sharing an action and
+// folder are created together.
+func importProfile(ctx context.Context, client *controld.API, pc *profileCopy) error {
+	profiles, err := client.CreateProfile(ctx, controld.CreateProfileParams{Name: pc.Name})
+	if err != nil {
+		return err
+	}
+	if len(profiles) == 0 {
+		return fmt.Errorf("creating profile %q returned no profile", pc.Name)
+	}
+	pc.ProfileID = profiles[0].PK
+
+	// A filter with levels is enabled at a level by enabling the level.
+	for _, f := range pc.Filters {
+		_, err := client.UpdateProfileFilter(ctx, controld.UpdateProfileFilterParams{
+			ProfileID: pc.ProfileID,
+			Filter:    cmp.Or(f.Level, f.PK),
+			Status:    true,
+		})
+		if err != nil {
+			return fmt.Errorf("filter %s: %w", f.PK, err)
+		}
+	}
+
+	for _, s := range pc.Services {
+		_, err := client.UpdateProfileService(ctx, controld.UpdateProfileServiceParams{
+			ProfileID: pc.ProfileID,
+			Service:   s.PK,
+			Do:        s.Action.Do,
+			Status:    s.Action.Status,
+			Via:       s.Action.Via,
+			ViaV6:     s.Action.ViaV6,
+		})
+		if err != nil {
+			return fmt.Errorf("service %s: %w", s.PK, err)
+		}
+	}
+
+	// Folder IDs differ between profiles, so rules are moved to the
+	// folder's new ID.
+	folderMap := map[int]int{}
+	for _, f := range pc.Folders {
+		status := f.Action.Status
+		groups, err := client.CreateProfileRuleFolder(ctx, controld.CreateProfileRuleFolderParams{
+			ProfileID: pc.ProfileID,
+			Name:      f.Group,
+			Do:        f.Action.Do,
+			Status:    &status,
+		})
+		if err != nil {
+			return fmt.Errorf("folder %s: %w", f.Group, err)
+		}
+		if len(groups) == 0 {
+			return fmt.Errorf("creating folder %s returned no folder", f.Group)
+		}
+		folderMap[f.PK] = groups[0].PK
+	}
+
+	for _, params := range ruleBatches(pc.Rules) {
+		params.ProfileID = pc.ProfileID
+		if params.Group != nil {
+			group := folderMap[*params.Group]
+			params.Group = &group
+		}
+		if _, err := client.CreateProfileCustomRule(ctx, params); err != nil {
+			return fmt.Errorf("rules %v: %w", params.Hostnames, err)
+		}
+	}
+
+	_, err = client.UpdateProfileDefaultRule(ctx, controld.UpdateProfileDefaultRuleParams{
+		ProfileID: pc.ProfileID,
+		Do:        pc.Default.Do,
+		Status:    pc.Default.Status,
+		Via:       pc.Default.Via,
+	})
+	if err != nil {
+		return fmt.Errorf("default rule: %w", err)
+	}
+
+	for _, o := range pc.Options {
+		params := controld.UpdateProfilesOption{
+			ProfileID: pc.ProfileID,
+			Name:      o.PK,
+			Status:    true,
+		}
+		if o.Value != nil {
+			value := fmt.Sprint(o.Value)
+			params.Value = &value
+		}
+		if _, err := client.UpdateProfilesOption(ctx, params); err != nil {
+			return fmt.Errorf("option %s: %w", o.PK, err)
+		}
+	}
+	return nil
+}
+
+// ruleBatches groups rules with the same action and folder, in the order
+// each group first appears.
+func ruleBatches(rules []controld.Rule) []controld.CreateProfileCustomRuleParams {
+	var batches []controld.CreateProfileCustomRuleParams
+	index := map[string]int{}
+	for _, r := range rules {
+		a := r.Action
+		key := fmt.Sprintf("%d/%t/%s/%s/%d", a.Do, bool(a.Status), deref(a.Via), deref(a.ViaV6), r.Group)
+		i, ok := index[key]
+		if !ok {
+			i = len(batches)
+			index[key] = i
+			params := controld.CreateProfileCustomRuleParams{
+				Do:     a.Do,
+				Status: a.Status,
+				Via:    a.Via,
+				ViaV6:  a.ViaV6,
+			}
+			if r.Group != 0 {
+				group := r.Group
+				params.Group = &group
+			}
+			batches = append(batches, params)
+		}
+		batches[i].Hostnames = append(batches[i].Hostnames, r.PK)
+	}
+	return batches
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// profileCopyTable lists everything a copy creates, one row per item.
+func profileCopyTable(pc *profileCopy) *outfmt.Table {
+	t := &outfmt.Table{
+		Columns: []outfmt.Column{
+			{Name: "kind"},
+			{Name: "name"},
+			{Name: "action"},
+		},
+	}
+	t.AddRow("profile", pc.Name, "create")
+	for _, f := range pc.Filters {
+		action := "enable"
+		if f.Level != "" {
+			action += " (" + f.Level + ")"
+		}
+		t.AddRow("filter", f.PK, action)
+	}
+	for _, s := range pc.Services {
+		t.AddRow("service", s.PK, actionToString(s.Action.Do))
+	}
+	folders := map[int]string{}
+	for _, f := range pc.Folders {
+		folders[f.PK] = f.Group
+		action := "none"
+		if f.Action.Do != nil {
+			action = actionToString(*f.Action.Do)
+		}
+		t.AddRow("folder", f.Group, action)
+	}
+	for _, r := range pc.Rules {
+		action := actionToString(r.Action.Do)
+		if name, ok := folders[r.Group]; ok {
+			action += " (" + name + ")"
+		}
+		t.AddRow("rule", r.PK, action)
+	}
+	t.AddRow("default", "", actionToString(pc.Default.Do))
+	for _, o := range pc.Options {
+		t.AddRow("option", o.PK, fmt.Sprint(o.Value))
+	}
+	return t
+}
@@ -26,16 +26,26 @@ func newProfilesFiltersListCmd() *cobra.Command {
 	var external bool
 
 	cmd := &cobra.Command{
-		Use:   "list <profile-id>",
-		Short: "List available filters",
-		Args:  cobra.ExactArgs(1),
+		Use:               "list [profile-id]",
+		Short:             "List available filters",
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeArgs(completeProfiles),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			client, err := getClient(cmd.Context())
 			if err != nil {
 				return err
 			}
 
-			params := controld.ListProfileFiltersParams{ProfileID: args[0]}
+			ref, err := profileRef(args)
+			if err != nil {
+				return err
+			}
+			profileID, err := resolveProfileID(cmd.Context(), client, ref)
+			if err != nil {
+				return err
+			}
+
+			params := controld.ListProfileFiltersParams{ProfileID: profileID}
 
 			var filters []controld.Filter
 			if external {
@@ -47,25 +57,22 @@ func newProfilesFiltersListCmd() *cobra.Command {
 				return err
 			}
 
-			if outfmt.IsJSON(cmd.Context()) {
-				return outfmt.WriteJSON(os.Stdout, filters)
-			}
-
-			if len(filters) == 0 {
-				fmt.Println("No filters found")
-				return nil
+			t := &outfmt.Table{
+				Columns: []outfmt.Column{
+					{Name: "filter_id"},
+					{Name: "name"},
+					{Name: "status", Status: true},
+				},
+				Empty: "No filters found",
 			}
-
-			tw := outfmt.NewTabWriter(os.Stdout)
-			_, _ = fmt.Fprintln(tw, "FILTER_ID\tNAME\tSTATUS")
 			for _, f := range filters {
 				status := "disabled"
 				if f.Status {
 					status = "enabled"
 				}
-				_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\n", f.PK, f.Name, status)
+				t.AddRow(f.PK, f.Name, status)
 			}
-			return tw.Flush()
+			return outfmt.Write(cmd.Context(), os.Stdout, filters, t)
 		},
 	}
 
@@ -75,9 +82,10 @@ func newProfilesFiltersListCmd() *cobra.Command {
 
 func newProfilesFiltersEnableCmd() *cobra.Command {
 	return &cobra.Command{
-		Use:   "enable <profile-id> <filter-id>",
-		Short: "Enable a filter",
-		Args:  cobra.ExactArgs(2),
+		Use:               "enable <profile-id> <filter-id>",
+		Short:             "Enable a filter",
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeArgs(completeProfiles, completeProfileFilters),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			u := ui.FromContext(cmd.Context())
 			client, err := getClient(cmd.Context())
@@ -85,16 +93,25 @@ func newProfilesFiltersEnableCmd() *cobra.Command {
 				return err
 			}
 
+			profileID, err := resolveProfileID(cmd.Context(), client, args[0])
+			if err != nil {
+				return err
+			}
+			filterID, err := resolveFilterID(cmd.Context(), client, profileID, args[1])
+			if err != nil {
+				return err
+			}
+
 			_, err = client.UpdateProfileFilter(cmd.Context(), controld.UpdateProfileFilterParams{
-				ProfileID: args[0],
-				Filter:    args[1],
+				ProfileID: profileID,
+				Filter:    filterID,
 				Status:    controld.IntBool(true),
 			})
 			if err != nil {
 				return err
 			}
 
-			u.Success(fmt.Sprintf("Enabled filter: %s", args[1]))
+			u.Success(fmt.Sprintf("Enabled filter: %s", filterID))
 			return nil
 		},
 	}
@@ -102,9 +119,10 @@ func newProfilesFiltersEnableCmd() *cobra.Command {
 
 func newProfilesFiltersDisableCmd() *cobra.Command {
 	return &cobra.Command{
-		Use:   "disable <profile-id> <filter-id>",
-		Short: "Disable a filter",
-		Args:  cobra.ExactArgs(2),
+		Use:               "disable <profile-id> <filter-id>",
+		Short:             "Disable a filter",
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeArgs(completeProfiles, completeProfileFilters),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			u := ui.FromContext(cmd.Context())
 			client, err := getClient(cmd.Context())
@@ -112,16 +130,25 @@ func newProfilesFiltersDisableCmd() *cobra.Command {
 				return err
 			}
 
+			profileID, err := resolveProfileID(cmd.Context(), client, args[0])
+			if err != nil {
+				return err
+			}
+			filterID, err := resolveFilterID(cmd.Context(), client, profileID, args[1])
+			if err != nil {
+				return err
+			}
+
 			_, err = client.UpdateProfileFilter(cmd.Context(), controld.UpdateProfileFilterParams{
-				ProfileID: args[0],
-				Filter:    args[1],
+				ProfileID: profileID,
+				Filter:    filterID,
 				Status:    controld.IntBool(false),
 			})
 			if err != nil {
 				return err
 			}
 
-			u.Success(fmt.Sprintf("Disabled filter: %s", args[1]))
+			u.Success(fmt.Sprintf("Disabled filter: %s", filterID))
 			return nil
 		},
 	}
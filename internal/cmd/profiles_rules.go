@@ -25,41 +25,49 @@ func newProfilesRulesCmd() *cobra.Command {
 
 func newProfilesRulesFoldersCmd() *cobra.Command {
 	return &cobra.Command{
-		Use:   "folders <profile-id>",
-		Short: "List rule folders for a profile",
-		Args:  cobra.ExactArgs(1),
+		Use:               "folders [profile-id]",
+		Short:             "List rule folders for a profile",
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeArgs(completeProfiles),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			client, err := getClient(cmd.Context())
 			if err != nil {
 				return err
 			}
 
-			folders, err := client.ListProfileRuleFolders(cmd.Context(), controld.ListProfileRuleFoldersParams{
-				ProfileID: args[0],
-			})
+			ref, err := profileRef(args)
 			if err != nil {
 				return err
 			}
-
-			if outfmt.IsJSON(cmd.Context()) {
-				return outfmt.WriteJSON(os.Stdout, folders)
+			profileID, err := resolveProfileID(cmd.Context(), client, ref)
+			if err != nil {
+				return err
 			}
 
-			if len(folders) == 0 {
-				fmt.Println("No rule folders found")
-				return nil
+			folders, err := client.ListProfileRuleFolders(cmd.Context(), controld.ListProfileRuleFoldersParams{
+				ProfileID: profileID,
+			})
+			if err != nil {
+				return err
 			}
 
-			tw := outfmt.NewTabWriter(os.Stdout)
-			_, _ = fmt.Fprintln(tw, "FOLDER_ID\tNAME\tRULE_COUNT\tSTATUS")
+			t := &outfmt.Table{
+				Columns: []outfmt.Column{
+					{Name: "folder_id"},
+					{Name: "name"},
+					{Name: "rule_count"},
+					{Name: "status", Status: true},
+				},
+				Empty: "No rule folders found",
+			}
 			for _, f := range folders {
 				status := "disabled"
 				if f.Action.Status {
 					status = "enabled"
 				}
-				_, _ = fmt.Fprintf(tw, "%d\t%s\t%d\t%s\n", f.PK, f.Group, f.Count, status)
+				t.AddRow(fmt.Sprint(f.PK), f.Group, fmt.Sprint(f.Count), status)
 			}
-			return tw.Flush()
+			return outfmt.Write(cmd.Context(), os.Stdout, folders, t)
 		},
 	}
 }
@@ -68,23 +76,36 @@ func newProfilesRulesListCmd() *cobra.Command {
 	var folderID string
 
 	cmd := &cobra.Command{
-		Use:   "list <profile-id>",
+		Use:   "list [profile-id]",
 		Short: "List custom rules for a profile",
 		Long: `List custom rules for a profile.
 
 Rules are organized into folders. Use --folder to specify a folder ID,
 or use 'profiles rules folders <profile-id>' to list available folders first.`,
-		Args: cobra.ExactArgs(1),
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeArgs(completeProfiles),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			client, err := getClient(cmd.Context())
 			if err != nil {
 				return err
 			}
 
-			profileID := args[0]
+			ref, err := profileRef(args)
+			if err != nil {
+				return err
+			}
+			profileID, err := resolveProfileID(cmd.Context(), client, ref)
+			if err != nil {
+				return err
+			}
 
 			// If no folder specified, first get the list of folders
-			if folderID == "" {
+			if folderID != "" {
+				folderID, err = resolveFolderID(cmd.Context(), client, profileID, folderID)
+				if err != nil {
+					return err
+				}
+			} else {
 				folders, err := client.ListProfileRuleFolders(cmd.Context(), controld.ListProfileRuleFoldersParams{
 					ProfileID: profileID,
 				})
@@ -93,8 +114,8 @@ or use 'profiles rules folders <profile-id>' to list available folders first.`,
 				}
 
 				if len(folders) == 0 {
-					if outfmt.IsJSON(cmd.Context()) {
-						return outfmt.WriteJSON(os.Stdout, []controld.CustomRule{})
+					if !outfmt.IsText(cmd.Context()) {
+						return outfmt.Write(cmd.Context(), os.Stdout, []controld.CustomRule{}, ruleTable(nil, ""))
 					}
 					fmt.Println("No rule folders found. Create a folder first or add rules via the ControlD dashboard.")
 					return nil
@@ -112,41 +133,45 @@ or use 'profiles rules folders <profile-id>' to list available folders first.`,
 				return err
 			}
 
-			if outfmt.IsJSON(cmd.Context()) {
-				return outfmt.WriteJSON(os.Stdout, rules)
-			}
-
-			if len(rules) == 0 {
-				fmt.Printf("No custom rules found in folder %s\n", folderID)
-				return nil
-			}
-
-			tw := outfmt.NewTabWriter(os.Stdout)
-			_, _ = fmt.Fprintln(tw, "DOMAIN\tACTION\tSTATUS\tFOLDER")
-			for _, r := range rules {
-				action := actionToString(r.Action.Do)
-				status := "disabled"
-				if r.Action.Status {
-					status = "enabled"
-				}
-				_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", r.PK, action, status, folderID)
-			}
-			return tw.Flush()
+			t := ruleTable(rules, folderID)
+			t.Empty = fmt.Sprintf("No custom rules found in folder %s", folderID)
+			return outfmt.Write(cmd.Context(), os.Stdout, rules, t)
 		},
 	}
 
-	cmd.Flags().StringVar(&folderID, "folder", "", "Folder ID (use 'folders' subcommand to list available folders)")
+	cmd.Flags().StringVar(&folderID, "folder", "", "Folder ID or name (use 'folders' subcommand to list available folders)")
+	_ = cmd.RegisterFlagCompletionFunc("folder", completeFlag(completeRuleFolders))
 	return cmd
 }
 
+func ruleTable(rules []controld.Rule, folderID string) *outfmt.Table {
+	t := &outfmt.Table{
+		Columns: []outfmt.Column{
+			{Name: "domain"},
+			{Name: "action"},
+			{Name: "status", Status: true},
+			{Name: "folder"},
+		},
+	}
+	for _, r := range rules {
+		status := "disabled"
+		if r.Action.Status {
+			status = "enabled"
+		}
+		t.AddRow(r.PK, actionToString(r.Action.Do), status, folderID)
+	}
+	return t
+}
+
 func newProfilesRulesCreateCmd() *cobra.Command {
 	var do string
 	var hostnames []string
 
 	cmd := &cobra.Command{
-		Use:   "create <profile-id>",
-		Short: "Create custom rules",
-		Args:  cobra.ExactArgs(1),
+		Use:               "create [profile-id]",
+		Short:             "Create custom rules",
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeArgs(completeProfiles),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			u := ui.FromContext(cmd.Context())
 			client, err := getClient(cmd.Context())
@@ -154,10 +179,19 @@ func newProfilesRulesCreateCmd() *cobra.Command {
 				return err
 			}
 
+			ref, err := profileRef(args)
+			if err != nil {
+				return err
+			}
+			profileID, err := resolveProfileID(cmd.Context(), client, ref)
+			if err != nil {
+				return err
+			}
+
 			doType := stringToAction(do)
 
 			rules, err := client.CreateProfileCustomRule(cmd.Context(), controld.CreateProfileCustomRuleParams{
-				ProfileID: args[0],
+				ProfileID: profileID,
 				Do:        doType,
 				Status:    controld.IntBool(true),
 				Hostnames: hostnames,
@@ -166,8 +200,16 @@ func newProfilesRulesCreateCmd() *cobra.Command {
 				return err
 			}
 
-			if outfmt.IsJSON(cmd.Context()) {
-				return outfmt.WriteJSON(os.Stdout, rules)
+			if !outfmt.IsText(cmd.Context()) {
+				t := &outfmt.Table{Columns: []outfmt.Column{{Name: "action"}, {Name: "status", Status: true}}}
+				for _, r := range rules {
+					status := "disabled"
+					if r.Status {
+						status = "enabled"
+					}
+					t.AddRow(actionToString(r.Do), status)
+				}
+				return outfmt.Write(cmd.Context(), os.Stdout, rules, t)
 			}
 
 			u.Success(fmt.Sprintf("Created %d rule(s)", len(rules)))
@@ -183,9 +225,10 @@ func newProfilesRulesCreateCmd() *cobra.Command {
 
 func newProfilesRulesDeleteCmd() *cobra.Command {
 	return &cobra.Command{
-		Use:   "delete <profile-id> <hostname>",
-		Short: "Delete a custom rule",
-		Args:  cobra.ExactArgs(2),
+		Use:               "delete <profile-id> <hostname>",
+		Short:             "Delete a custom rule",
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeArgs(completeProfiles),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			u := ui.FromContext(cmd.Context())
 			client, err := getClient(cmd.Context())
@@ -193,8 +236,13 @@ func newProfilesRulesDeleteCmd() *cobra.Command {
 				return err
 			}
 
+			profile, err := resolveProfileStrict(cmd.Context(), client, args[0])
+			if err != nil {
+				return err
+			}
+
 			_, err = client.DeleteProfileCustomRule(cmd.Context(), controld.DeleteProfileCustomRuleParams{
-				ProfileID: args[0],
+				ProfileID: profile.PK,
 				Hostname:  args[1],
 			})
 			if err != nil {
@@ -26,17 +26,27 @@ func newProfilesServicesListCmd() *cobra.Command {
 	var category string
 
 	cmd := &cobra.Command{
-		Use:   "list <profile-id>",
-		Short: "List services for a profile",
-		Args:  cobra.ExactArgs(1),
+		Use:               "list [profile-id]",
+		Short:             "List services for a profile",
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeArgs(completeProfiles),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			client, err := getClient(cmd.Context())
 			if err != nil {
 				return err
 			}
 
+			ref, err := profileRef(args)
+			if err != nil {
+				return err
+			}
+			profileID, err := resolveProfileID(cmd.Context(), client, ref)
+			if err != nil {
+				return err
+			}
+
 			services, err := client.ListProfileServices(cmd.Context(), controld.ListProfileServicesParams{
-				ProfileID: args[0],
+				ProfileID: profileID,
 			})
 			if err != nil {
 				return err
@@ -53,26 +63,24 @@ func newProfilesServicesListCmd() *cobra.Command {
 				services = filtered
 			}
 
-			if outfmt.IsJSON(cmd.Context()) {
-				return outfmt.WriteJSON(os.Stdout, services)
-			}
-
-			if len(services) == 0 {
-				fmt.Println("No services found")
-				return nil
+			t := &outfmt.Table{
+				Columns: []outfmt.Column{
+					{Name: "service_id"},
+					{Name: "name"},
+					{Name: "category"},
+					{Name: "action"},
+					{Name: "status", Status: true},
+				},
+				Empty: "No services found",
 			}
-
-			tw := outfmt.NewTabWriter(os.Stdout)
-			_, _ = fmt.Fprintln(tw, "SERVICE_ID\tNAME\tCATEGORY\tACTION\tSTATUS")
 			for _, s := range services {
-				action := actionToString(s.Action.Do)
 				status := "disabled"
 				if s.Action.Status {
 					status = "enabled"
 				}
-				_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", s.PK, s.Name, s.Category, action, status)
+				t.AddRow(s.PK, s.Name, s.Category, actionToString(s.Action.Do), status)
 			}
-			return tw.Flush()
+			return outfmt.Write(cmd.Context(), os.Stdout, services, t)
 		},
 	}
 
@@ -92,7 +100,8 @@ Actions:
   block   - Block access to this service
   bypass  - Allow access, bypassing any filters
   spoof   - Use proxy/redirect for geo-unblocking`,
-		Args: cobra.ExactArgs(2),
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeArgs(completeProfiles, completeProfileServices),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			u := ui.FromContext(cmd.Context())
 			client, err := getClient(cmd.Context())
@@ -100,9 +109,18 @@ Actions:
 				return err
 			}
 
+			profileID, err := resolveProfileID(cmd.Context(), client, args[0])
+			if err != nil {
+				return err
+			}
+			serviceID, err := resolveServiceID(cmd.Context(), client, args[1])
+			if err != nil {
+				return err
+			}
+
 			_, err = client.UpdateProfileService(cmd.Context(), controld.UpdateProfileServiceParams{
-				ProfileID: args[0],
-				Service:   args[1],
+				ProfileID: profileID,
+				Service:   serviceID,
 				Do:        stringToAction(action),
 				Status:    controld.IntBool(true),
 			})
@@ -110,7 +128,7 @@ Actions:
 				return err
 			}
 
-			u.Success(fmt.Sprintf("Set %s to %s", args[1], action))
+			u.Success(fmt.Sprintf("Set %s to %s", serviceID, action))
 			return nil
 		},
 	}
@@ -121,9 +139,10 @@ Actions:
 
 func newProfilesServicesDisableCmd() *cobra.Command {
 	return &cobra.Command{
-		Use:   "disable <profile-id> <service-id>",
-		Short: "Disable a service rule (remove custom action)",
-		Args:  cobra.ExactArgs(2),
+		Use:               "disable <profile-id> <service-id>",
+		Short:             "Disable a service rule (remove custom action)",
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeArgs(completeProfiles, completeProfileServices),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			u := ui.FromContext(cmd.Context())
 			client, err := getClient(cmd.Context())
@@ -131,9 +150,18 @@ func newProfilesServicesDisableCmd() *cobra.Command {
 				return err
 			}
 
+			profileID, err := resolveProfileID(cmd.Context(), client, args[0])
+			if err != nil {
+				return err
+			}
+			serviceID, err := resolveServiceID(cmd.Context(), client, args[1])
+			if err != nil {
+				return err
+			}
+
 			_, err = client.UpdateProfileService(cmd.Context(), controld.UpdateProfileServiceParams{
-				ProfileID: args[0],
-				Service:   args[1],
+				ProfileID: profileID,
+				Service:   serviceID,
 				Do:        controld.Block,
 				Status:    controld.IntBool(false),
 			})
@@ -141,7 +169,7 @@ func newProfilesServicesDisableCmd() *cobra.Command {
 				return err
 			}
 
-			u.Success(fmt.Sprintf("Disabled service rule: %s", args[1]))
+			u.Success(fmt.Sprintf("Disabled service rule: %s", serviceID))
 			return nil
 		},
 	}
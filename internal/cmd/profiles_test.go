@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/salmonumbrella/controld-cli/internal/controld"
+)
+
+func TestProfilesList(t *testing.T) {
+	srv := newTestServer(t)
+	srv.Seed()
+
+	out, err := runCmd(t, "profiles", "list")
+
+	require.NoError(t, err)
+	assert.Contains(t, out, "Home")
+	assert.Contains(t, out, "Kids")
+}
+
+func TestProfilesCreateClone(t *testing.T) {
+	srv := newTestServer(t)
+	srv.Seed()
+
+	_, err := runCmd(t, "profiles", "create", "--name", "Guests", "--clone-from", "kids")
+	require.NoError(t, err)
+
+	out, err := runCmd(t, "profiles", "filters", "list", "guests")
+	require.NoError(t, err)
+	assert.Contains(t, out, "gambling")
+}
+
+func TestProfilesServicesAndRules(t *testing.T) {
+	srv := newTestServer(t)
+	srv.AddProfile("Home")
+
+	_, err := runCmd(t, "profiles", "services", "set", "home", "spotify", "--action", "bypass")
+	require.NoError(t, err)
+	out, err := runCmd(t, "profiles", "services", "list", "home")
+	require.NoError(t, err)
+	assert.Contains(t, out, "spotify")
+
+	// Services resolve against the catalog, not just the configured ones.
+	_, err = runCmd(t, "profiles", "services", "set", "home", "amazonprime", "--action", "bypass")
+	require.NoError(t, err)
+	_, err = runCmd(t, "profiles", "services", "set", "home", "amazon", "--action", "block")
+	require.NoError(t, err)
+	out, err = runCmd(t, "--output", "json", "--query", "[].PK", "profiles", "services", "list", "home")
+	require.NoError(t, err)
+	assert.Contains(t, out, `"amazon"`)
+	assert.Contains(t, out, `"amazonprime"`)
+	_, err = runCmd(t, "profiles", "services", "set", "home", "youtub", "--action", "block")
+	require.NoError(t, err)
+
+	_, err = runCmd(t, "profiles", "rules", "create", "home", "--hostname", "ads.example", "--action", "block")
+	require.NoError(t, err)
+	_, err = runCmd(t, "profiles", "rules", "delete", "hme", "ads.example")
+	assert.ErrorContains(t, err, "Did you mean Home")
+	_, err = runCmd(t, "profiles", "rules", "delete", "home", "ads.example")
+	require.NoError(t, err)
+}
+
+func TestProfilesCopy(t *testing.T) {
+	srv := newTestServer(t)
+	srv.Seed()
+	useTestKeyring(t, map[string]string{"src": "test-token", "dst": "test-token"})
+
+	_, err := runCmd(t, "profiles", "rules", "create", "Kids", "--hostname", "a.example,b.example")
+	require.NoError(t, err)
+	_, err = runCmd(t, "profiles", "rules", "create", "Kids", "--hostname", "c.example", "--action", "bypass")
+	require.NoError(t, err)
+	kidsID, err := resolveProfileID(context.Background(), srv.NewClient(t), "Kids")
+	require.NoError(t, err)
+	_, err = srv.NewClient(t).UpdateProfileFilter(context.Background(), controld.UpdateProfileFilterParams{
+		ProfileID: kidsID,
+		Filter:    "ads_medium",
+		Status:    true,
+	})
+	require.NoError(t, err)
+
+	out, err := runCmd(t, "profiles", "copy", "Kids", "--from-account", "src", "--to-account", "dst", "--dry-run")
+	assert.ErrorContains(t, err, `already has a profile named "Kids"`)
+	assert.Empty(t, out)
+
+	out, err = runCmd(t, "profiles", "copy", "Kids", "--from-account", "src", "--to-account", "dst", "--name", "Client Kids", "--dry-run")
+	require.NoError(t, err)
+	assert.Contains(t, out, "filter   gambling")
+	assert.Contains(t, out, "enable (ads_medium)")
+	assert.Contains(t, out, "rule     c.example")
+	assert.Len(t, srv.Profiles(), 2)
+
+	out, err = runCmd(t, "--output", "json", "profiles", "copy", "Kids", "--from-account", "src", "--to-account", "dst", "--name", "Client Kids")
+	require.NoError(t, err)
+	var copied struct {
+		ProfileID string `json:"profile_id"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(out), &copied))
+	require.NotEmpty(t, copied.ProfileID)
+
+	out, err = runCmd(t, "profiles", "filters", "list", copied.ProfileID)
+	require.NoError(t, err)
+	assert.Contains(t, out, "gambling")
+	filters, err := srv.NewClient(t).ListProfileNativeFilters(context.Background(), controld.ListProfileFiltersParams{ProfileID: copied.ProfileID})
+	require.NoError(t, err)
+	require.Equal(t, "ads", filters[0].PK)
+	for _, l := range filters[0].Levels {
+		assert.Equal(t, l.Name == "ads_medium", bool(l.Status), l.Name)
+	}
+	rules, err := srv.NewClient(t).ListProfileCustomRules(context.Background(), controld.ListProfileCustomRulesParams{
+		ProfileID: copied.ProfileID,
+		FolderID:  "0",
+	})
+	require.NoError(t, err)
+	assert.Len(t, rules, 3)
+}
+
+func TestCompleteFoldersDefaultProfile(t *testing.T) {
+	srv := newTestServer(t)
+	srv.Seed()
+	kidsID, err := resolveProfileID(context.Background(), srv.NewClient(t), "Kids")
+	require.NoError(t, err)
+	_, err = srv.NewClient(t).CreateProfileRuleFolder(context.Background(), controld.CreateProfileRuleFolderParams{ProfileID: kidsID, Name: "Homework"})
+	require.NoError(t, err)
+
+	_, err = runCmd(t, "config", "set", "profile", "Kids")
+	require.NoError(t, err)
+	out, err := runCmd(t, "__complete", "profiles", "rules", "list", "--folder", "")
+	require.NoError(t, err)
+	assert.Contains(t, out, "Homework")
+}
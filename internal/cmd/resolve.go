@@ -0,0 +1,377 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/salmonumbrella/controld-cli/internal/controld"
+)
+
+// maxSuggestions caps the number of candidates listed in ambiguity errors.
+const maxSuggestions = 5
+
+// candidate is an object that can be referenced on the command line, either
+// by its primary key or by its name.
+type candidate struct {
+	ID   string
+	Name string
+}
+
+func (c candidate) String() string {
+	if c.Name == "" || c.Name == c.ID {
+		return c.ID
+	}
+	return fmt.Sprintf("%s (%s)", c.Name, c.ID)
+}
+
+// errNoMatch is returned by matchCandidates when nothing matches the reference.
+type errNoMatch struct {
+	kind string
+	ref  string
+}
+
+func (e errNoMatch) Error() string {
+	return fmt.Sprintf("%s not found: %s", e.kind, e.ref)
+}
+
+// matchStage reports whether a candidate matches a lowercased reference.
+type matchStage func(c candidate, needle string) bool
+
+// strictStages match an exact name (case-insensitive) or a unique ID or name
+// prefix.
+var strictStages = []matchStage{
+	func(c candidate, needle string) bool {
+		return strings.EqualFold(c.Name, needle)
+	},
+	func(c candidate, needle string) bool {
+		return strings.HasPrefix(strings.ToLower(c.ID), needle) ||
+			strings.HasPrefix(strings.ToLower(c.Name), needle)
+	},
+}
+
+// fuzzyStages match a unique substring or subsequence of the name.
+var fuzzyStages = []matchStage{
+	func(c candidate, needle string) bool {
+		return strings.Contains(strings.ToLower(c.Name), needle)
+	},
+	func(c candidate, needle string) bool {
+		return isSubsequence(needle, strings.ToLower(c.Name))
+	},
+}
+
+// matchCandidates resolves ref against cands, trying in order: an exact ID,
+// an exact name (case-insensitive), a unique ID or name prefix, and finally a
+// unique fuzzy match on the name. It returns the index of the match, or an
+// error listing the candidates when the reference is ambiguous.
+func matchCandidates(kind, ref string, cands []candidate) (int, error) {
+	return matchStages(kind, ref, cands, slices.Concat(strictStages, fuzzyStages))
+}
+
+// matchCandidatesStrict is matchCandidates without the fuzzy stages, for
+// commands that destroy data: "lp" must not pick "Laptop" unseen when the
+// confirmation is skipped. A reference only a fuzzy stage would resolve is
+// reported with the candidate it would have picked.
+func matchCandidatesStrict(kind, ref string, cands []candidate) (int, error) {
+	i, err := matchStages(kind, ref, cands, strictStages)
+	var noMatch errNoMatch
+	if !errors.As(err, &noMatch) {
+		return i, err
+	}
+	if j, err := matchStages(kind, ref, cands, fuzzyStages); err == nil {
+		return -1, fmt.Errorf("%s not found: %s. Did you mean %s? Use its exact ID or name", kind, ref, cands[j])
+	}
+	return -1, noMatch
+}
+
+func matchStages(kind, ref string, cands []candidate, stages []matchStage) (int, error) {
+	for i, c := range cands {
+		if c.ID == ref {
+			return i, nil
+		}
+	}
+
+	needle := strings.ToLower(strings.TrimSpace(ref))
+	if needle == "" {
+		return -1, fmt.Errorf("%s reference cannot be empty", kind)
+	}
+
+	for _, match := range stages {
+		var hits []int
+		for i, c := range cands {
+			if match(c, needle) {
+				hits = append(hits, i)
+			}
+		}
+		switch len(hits) {
+		case 0:
+			continue
+		case 1:
+			return hits[0], nil
+		default:
+			return -1, ambiguousError(kind, ref, cands, hits)
+		}
+	}
+
+	return -1, errNoMatch{kind: kind, ref: ref}
+}
+
+func ambiguousError(kind, ref string, cands []candidate, hits []int) error {
+	names := make([]string, 0, len(hits))
+	for _, i := range hits {
+		names = append(names, cands[i].String())
+	}
+	sort.Strings(names)
+
+	more := ""
+	if len(names) > maxSuggestions {
+		more = fmt.Sprintf(", and %d more", len(names)-maxSuggestions)
+		names = names[:maxSuggestions]
+	}
+	return fmt.Errorf("%s %q is ambiguous; did you mean: %s%s", kind, ref, strings.Join(names, ", "), more)
+}
+
+// isSubsequence reports whether all runes of needle appear in s in order.
+func isSubsequence(needle, s string) bool {
+	rs := []rune(s)
+	j := 0
+	for _, r := range needle {
+		for j < len(rs) && rs[j] != r {
+			j++
+		}
+		if j == len(rs) {
+			return false
+		}
+		j++
+	}
+	return true
+}
+
+func profileCandidates(profiles []controld.Profile) []candidate {
+	out := make([]candidate, len(profiles))
+	for i, p := range profiles {
+		out[i] = candidate{ID: p.PK, Name: p.Name}
+	}
+	return out
+}
+
+func deviceCandidates(devices []controld.Device) []candidate {
+	out := make([]candidate, len(devices))
+	for i, d := range devices {
+		out[i] = candidate{ID: d.DeviceID, Name: d.Name}
+	}
+	return out
+}
+
+func serviceCandidates(services []controld.ProfileService) []candidate {
+	out := make([]candidate, len(services))
+	for i, s := range services {
+		out[i] = candidate{ID: s.PK, Name: s.Name}
+	}
+	return out
+}
+
+func catalogServiceCandidates(services []controld.Service) []candidate {
+	out := make([]candidate, len(services))
+	for i, s := range services {
+		out[i] = candidate{ID: s.PK, Name: s.Name}
+	}
+	return out
+}
+
+func filterCandidates(filters []controld.Filter) []candidate {
+	out := make([]candidate, len(filters))
+	for i, f := range filters {
+		out[i] = candidate{ID: f.PK, Name: f.Name}
+	}
+	return out
+}
+
+func folderCandidates(folders []controld.Group) []candidate {
+	out := make([]candidate, len(folders))
+	for i, f := range folders {
+		out[i] = candidate{ID: strconv.Itoa(f.PK), Name: f.Group}
+	}
+	return out
+}
+
+// findProfile resolves ref against an already fetched list of profiles.
+func findProfile(profiles []controld.Profile, ref string) (*controld.Profile, error) {
+	i, err := matchCandidates("profile", ref, profileCandidates(profiles))
+	if err != nil {
+		return nil, err
+	}
+	return &profiles[i], nil
+}
+
+// findDevice resolves ref against an already fetched list of devices.
+func findDevice(devices []controld.Device, ref string) (*controld.Device, error) {
+	i, err := matchCandidates("device", ref, deviceCandidates(devices))
+	if err != nil {
+		return nil, err
+	}
+	return &devices[i], nil
+}
+
+// findDeviceStrict is findDevice without fuzzy matching, for commands that
+// destroy data.
+func findDeviceStrict(devices []controld.Device, ref string) (*controld.Device, error) {
+	i, err := matchCandidatesStrict("device", ref, deviceCandidates(devices))
+	if err != nil {
+		return nil, err
+	}
+	return &devices[i], nil
+}
+
+// resolveProfile resolves a profile PK, name or unique prefix.
+func resolveProfile(ctx context.Context, client *controld.API, ref string) (*controld.Profile, error) {
+	profiles, err := client.ListProfiles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return findProfile(profiles, ref)
+}
+
+// resolveProfileID is resolveProfile for callers that only need the PK.
+func resolveProfileID(ctx context.Context, client *controld.API, ref string) (string, error) {
+	profile, err := resolveProfile(ctx, client, ref)
+	if err != nil {
+		return "", err
+	}
+	return profile.PK, nil
+}
+
+// resolveProfileStrict is resolveProfile without fuzzy matching, for commands
+// that destroy data.
+func resolveProfileStrict(ctx context.Context, client *controld.API, ref string) (*controld.Profile, error) {
+	profiles, err := client.ListProfiles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	i, err := matchCandidatesStrict("profile", ref, profileCandidates(profiles))
+	if err != nil {
+		return nil, err
+	}
+	return &profiles[i], nil
+}
+
+// resolveDevice resolves a device ID, name or unique prefix.
+func resolveDevice(ctx context.Context, client *controld.API, ref string) (*controld.Device, error) {
+	devices, err := client.ListDevices(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return findDevice(devices, ref)
+}
+
+// resolveDeviceStrict is resolveDevice without fuzzy matching, for commands
+// that destroy data.
+func resolveDeviceStrict(ctx context.Context, client *controld.API, ref string) (*controld.Device, error) {
+	devices, err := client.ListDevices(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return findDeviceStrict(devices, ref)
+}
+
+// resolveDeviceID is resolveDevice for callers that only need the ID.
+func resolveDeviceID(ctx context.Context, client *controld.API, ref string) (string, error) {
+	device, err := resolveDevice(ctx, client, ref)
+	if err != nil {
+		return "", err
+	}
+	return device.DeviceID, nil
+}
+
+// resolveServiceID resolves a service against the full service catalog, so
+// services a profile has never configured resolve too.
+func resolveServiceID(ctx context.Context, client *controld.API, ref string) (string, error) {
+	services, err := listServiceCatalog(ctx, client)
+	if err != nil {
+		return "", err
+	}
+	// An exact PK never competes with services it is a prefix of.
+	if slices.ContainsFunc(services, func(s controld.Service) bool { return s.PK == ref }) {
+		return ref, nil
+	}
+	i, err := matchCandidates("service", ref, catalogServiceCandidates(services))
+	if err != nil {
+		return "", err
+	}
+	return services[i].PK, nil
+}
+
+// listServiceCatalog lists the services of every category.
+func listServiceCatalog(ctx context.Context, client *controld.API) ([]controld.Service, error) {
+	categories, err := client.ListServiceCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var services []controld.Service
+	for _, c := range categories {
+		list, err := client.ListServices(ctx, controld.ListServicesParams{Category: c.PK})
+		if err != nil {
+			return nil, err
+		}
+		services = append(services, list...)
+	}
+	return services, nil
+}
+
+// resolveFilterID resolves a native or external filter for a profile.
+func resolveFilterID(ctx context.Context, client *controld.API, profileID, ref string) (string, error) {
+	filters, err := listProfileFilters(ctx, client, profileID)
+	if err != nil {
+		return "", err
+	}
+	i, err := matchCandidates("filter", ref, filterCandidates(filters))
+	if err != nil {
+		return "", err
+	}
+	return filters[i].PK, nil
+}
+
+// resolveFolderID resolves a rule folder of a profile by PK or name.
+func resolveFolderID(ctx context.Context, client *controld.API, profileID, ref string) (string, error) {
+	folders, err := client.ListProfileRuleFolders(ctx, controld.ListProfileRuleFoldersParams{ProfileID: profileID})
+	if err != nil {
+		return "", err
+	}
+	cands := folderCandidates(folders)
+	i, err := matchCandidates("folder", ref, cands)
+	if err != nil {
+		return "", err
+	}
+	return cands[i].ID, nil
+}
+
+// listProfileFilters returns both the native and external filters of a
+// profile.
+func listProfileFilters(ctx context.Context, client *controld.API, profileID string) ([]controld.Filter, error) {
+	params := controld.ListProfileFiltersParams{ProfileID: profileID}
+	native, err := client.ListProfileNativeFilters(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	external, err := client.ListProfileExternalFilters(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return append(native, external...), nil
+}
+
+// profileRef returns the profile named by the first argument, or the config
+// context's default profile when the argument is omitted.
+func profileRef(args []string) (string, error) {
+	if len(args) > 0 {
+		return args[0], nil
+	}
+	if settings.Profile != "" {
+		return settings.Profile, nil
+	}
+	return "", fmt.Errorf("no profile given: pass a profile or set one with: controld config set profile <profile>")
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchCandidates(t *testing.T) {
+	cands := []candidate{
+		{ID: "p1abc", Name: "Family Safe"},
+		{ID: "p2def", Name: "Family Guest"},
+		{ID: "p3ghi", Name: "Productivity"},
+		{ID: "p4jkl", Name: "Kids"},
+	}
+
+	tests := []struct {
+		name   string
+		ref    string
+		wantID string
+	}{
+		{"exact id", "p3ghi", "p3ghi"},
+		{"exact name", "Kids", "p4jkl"},
+		{"name case-insensitive", "family safe", "p1abc"},
+		{"id prefix", "p2", "p2def"},
+		{"name prefix", "prod", "p3ghi"},
+		{"substring", "guest", "p2def"},
+		{"subsequence", "prdctvty", "p3ghi"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i, err := matchCandidates("profile", tt.ref, cands)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantID, cands[i].ID)
+		})
+	}
+}
+
+func TestMatchCandidatesAmbiguous(t *testing.T) {
+	cands := []candidate{
+		{ID: "p1abc", Name: "Family Safe"},
+		{ID: "p2def", Name: "Family Guest"},
+		{ID: "p3ghi", Name: "Productivity"},
+	}
+
+	_, err := matchCandidates("profile", "fam", cands)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "ambiguous")
+	assert.Contains(t, err.Error(), "Family Safe (p1abc)")
+	assert.Contains(t, err.Error(), "Family Guest (p2def)")
+	assert.NotContains(t, err.Error(), "Productivity")
+}
+
+func TestMatchCandidatesDuplicateNames(t *testing.T) {
+	cands := []candidate{
+		{ID: "d1", Name: "Laptop"},
+		{ID: "d2", Name: "Laptop"},
+	}
+
+	_, err := matchCandidates("device", "laptop", cands)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "ambiguous")
+
+	// The ID still resolves unambiguously.
+	i, err := matchCandidates("device", "d2", cands)
+	require.NoError(t, err)
+	assert.Equal(t, 1, i)
+}
+
+func TestMatchCandidatesTruncatesSuggestions(t *testing.T) {
+	var cands []candidate
+	for _, id := range []string{"a1", "a2", "a3", "a4", "a5", "a6", "a7"} {
+		cands = append(cands, candidate{ID: id, Name: "Device " + id})
+	}
+
+	_, err := matchCandidates("device", "a", cands)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "and 2 more")
+}
+
+func TestMatchCandidatesNotFound(t *testing.T) {
+	cands := []candidate{{ID: "p1abc", Name: "Family Safe"}}
+
+	_, err := matchCandidates("profile", "zzz", cands)
+
+	var noMatch errNoMatch
+	require.True(t, errors.As(err, &noMatch))
+	assert.Equal(t, "profile not found: zzz", err.Error())
+}
+
+func TestMatchCandidatesEmptyRef(t *testing.T) {
+	_, err := matchCandidates("profile", " ", []candidate{{ID: "p1", Name: "One"}})
+
+	assert.Error(t, err)
+}
+
+func TestIsSubsequence(t *testing.T) {
+	assert.True(t, isSubsequence("fmsf", "family safe"))
+	assert.True(t, isSubsequence("", "anything"))
+	assert.False(t, isSubsequence("sf", "f"))
+	assert.False(t, isSubsequence("xyz", "family safe"))
+}
+
+func TestMatchCandidatesStrict(t *testing.T) {
+	cands := []candidate{
+		{ID: "d1abc", Name: "Laptop"},
+		{ID: "d2def", Name: "Phone"},
+	}
+
+	for _, ref := range []string{"d1abc", "laptop", "lap", "d2"} {
+		_, err := matchCandidatesStrict("device", ref, cands)
+		assert.NoError(t, err, ref)
+	}
+
+	// Fuzzy matches are refused but suggested.
+	_, err := matchCandidatesStrict("device", "lp", cands)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Did you mean Laptop (d1abc)?")
+
+	_, err = matchCandidatesStrict("device", "tablet", cands)
+	var noMatch errNoMatch
+	assert.True(t, errors.As(err, &noMatch))
+}
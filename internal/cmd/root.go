@@ -4,28 +4,55 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 
 	"github.com/salmonumbrella/controld-cli/internal/api"
 	"github.com/salmonumbrella/controld-cli/internal/config"
 	"github.com/salmonumbrella/controld-cli/internal/controld"
 	"github.com/salmonumbrella/controld-cli/internal/debug"
 	"github.com/salmonumbrella/controld-cli/internal/outfmt"
+	"github.com/salmonumbrella/controld-cli/internal/secrets"
+	"github.com/salmonumbrella/controld-cli/internal/telemetry"
 	"github.com/salmonumbrella/controld-cli/internal/ui"
 )
 
 type rootFlags struct {
-	Token   string
-	Account string
-	Output  string
-	Color   string
-	Debug   bool
-	Yes     bool
+	Token     string
+	Account   string
+	Context   string
+	Output    string
+	Query     string
+	Fields    []string
+	SortBy    string
+	Wide      bool
+	NoHeaders bool
+	Color     string
+	Debug     string
+	Yes       bool
+	NoCache   bool
+
+	OTelEndpoint string
 }
 
 var flags rootFlags
 
+// clientOptions are passed to every API client. Tests use it to disable rate
+// limiting and retry delays.
+var clientOptions []controld.Option
+
+// openSecrets opens the credential store. Tests replace it with an in-memory
+// keyring.
+var openSecrets = secrets.OpenDefault
+
+// settings is the config context selected for the current run.
+var settings = &config.Context{}
+
+// tracing holds the telemetry provider set up for the current run, if any.
+var tracing *telemetry.Provider
+
 func NewRootCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:          "controld",
@@ -33,30 +60,66 @@ func NewRootCmd() *cobra.Command {
 		Long:         "A command-line interface for the ControlD DNS management API.",
 		SilenceUsage: true,
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-			if flags.Output != "" && flags.Output != "json" && flags.Output != "text" {
-				return fmt.Errorf("invalid output format %q: must be 'json' or 'text'", flags.Output)
+			if err := loadContext(cmd); err != nil {
+				return err
+			}
+			if err := outfmt.ValidateFormat(flags.Output); err != nil {
+				return err
+			}
+			if err := outfmt.ValidateQuery(flags.Query); err != nil {
+				return err
 			}
 
-			debug.SetupLogger(flags.Debug)
-			ctx := debug.WithDebug(cmd.Context(), flags.Debug)
+			level, err := debug.ParseLevel(flags.Debug)
+			if err != nil {
+				return err
+			}
+			debug.SetupLogger(level != debug.LevelOff)
+			ctx := debug.WithLevel(cmd.Context(), level)
 
 			u := ui.New(flags.Color)
 			ctx = ui.WithUI(ctx, u)
 
 			ctx = outfmt.WithFormat(ctx, flags.Output)
+			ctx = outfmt.WithQuery(ctx, flags.Query)
+			ctx = outfmt.WithFields(ctx, flags.Fields)
+			ctx = outfmt.WithTableOptions(ctx, outfmt.TableOptions{
+				SortBy:    flags.SortBy,
+				Wide:      flags.Wide,
+				NoHeaders: flags.NoHeaders,
+				Width:     terminalWidth(),
+			})
 			ctx = outfmt.WithYes(ctx, flags.Yes)
 
+			if telemetry.Enabled(flags.OTelEndpoint) {
+				p, err := telemetry.Setup(ctx, telemetry.Config{Endpoint: flags.OTelEndpoint, Version: Version})
+				if err != nil {
+					return fmt.Errorf("setting up telemetry: %w", err)
+				}
+				tracing = p
+				ctx = telemetry.WithProvider(ctx, p)
+			}
+
 			cmd.SetContext(ctx)
 			return nil
 		},
 	}
 
 	cmd.PersistentFlags().StringVar(&flags.Token, "token", "", "API token (overrides keyring and env)")
-	cmd.PersistentFlags().StringVar(&flags.Account, "account", os.Getenv(config.EnvToken), "Account name from keyring")
-	cmd.PersistentFlags().StringVar(&flags.Output, "output", getEnvOrDefault(config.EnvOutput, "text"), "Output format: text|json")
+	cmd.PersistentFlags().StringVar(&flags.Account, "account", os.Getenv(config.EnvAccount), "Account name from keyring")
+	cmd.PersistentFlags().StringVar(&flags.Context, "context", os.Getenv(config.EnvContext), "Config context to use instead of the current one")
+	cmd.PersistentFlags().StringVar(&flags.Output, "output", getEnvOrDefault(config.EnvOutput, "text"), "Output format: text|json|yaml|csv|tsv|ndjson|template=<go template>")
+	cmd.PersistentFlags().StringVar(&flags.Query, "query", "", "JMESPath expression applied to the JSON result (e.g. \"[?name=='Kids'].PK\")")
+	cmd.PersistentFlags().StringSliceVar(&flags.Fields, "fields", nil, "Columns to show in text, csv and tsv output, as column names or JSON paths (e.g. name,profile.name)")
+	cmd.PersistentFlags().StringVar(&flags.SortBy, "sort-by", "", "Sort table rows by this column")
+	cmd.PersistentFlags().BoolVar(&flags.Wide, "wide", false, "Show extra table columns")
+	cmd.PersistentFlags().BoolVar(&flags.NoHeaders, "no-headers", false, "Omit the table header row")
 	cmd.PersistentFlags().StringVar(&flags.Color, "color", getEnvOrDefault(config.EnvColor, "auto"), "Color output: auto|always|never")
-	cmd.PersistentFlags().BoolVar(&flags.Debug, "debug", false, "Enable debug output")
+	cmd.PersistentFlags().StringVar(&flags.Debug, "debug", "", "Enable debug output, optionally limited to HTTP headers or bodies: --debug[=headers|body|all]")
+	cmd.PersistentFlags().Lookup("debug").NoOptDefVal = string(debug.LevelAll)
 	cmd.PersistentFlags().BoolVarP(&flags.Yes, "yes", "y", false, "Skip confirmation prompts")
+	cmd.PersistentFlags().BoolVar(&flags.NoCache, "no-cache", false, "Bypass the API response cache")
+	cmd.PersistentFlags().StringVar(&flags.OTelEndpoint, "otel-endpoint", "", "OTLP/HTTP collector URL for traces and metrics (default from OTEL_EXPORTER_OTLP_ENDPOINT)")
 
 	cmd.AddCommand(newVersionCmd())
 	cmd.AddCommand(newAuthCmd())
@@ -67,10 +130,84 @@ func NewRootCmd() *cobra.Command {
 	cmd.AddCommand(newNetworkCmd())
 	cmd.AddCommand(newAccessCmd())
 	cmd.AddCommand(newCompletionCmd())
+	cmd.AddCommand(newCacheCmd())
+	cmd.AddCommand(newConfigCmd())
+	cmd.AddCommand(newAliasCmd())
+	cmd.AddCommand(newDevCmd())
+	addAliases(cmd)
+	addPlugins(cmd)
 
 	return cmd
 }
 
+// loadContext selects the config context for this run and fills in the
+// settings no flag or environment variable gave. Precedence is flags, then
+// environment variables, then the context, then built-in defaults.
+func loadContext(cmd *cobra.Command) error {
+	file, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	c, err := file.Select(flags.Context)
+	if err != nil {
+		// config commands must work before the context they name exists.
+		if !isConfigCmd(cmd) {
+			return err
+		}
+		c = &config.Context{}
+	}
+	settings = c
+
+	fromContext := func(flag, env, value string, dst *string) {
+		if value != "" && !cmd.Flags().Changed(flag) && os.Getenv(env) == "" {
+			*dst = value
+		}
+	}
+	fromContext("account", config.EnvAccount, c.Account, &flags.Account)
+	fromContext("output", config.EnvOutput, c.Output, &flags.Output)
+	fromContext("color", config.EnvColor, c.Color, &flags.Color)
+	return nil
+}
+
+func isConfigCmd(cmd *cobra.Command) bool {
+	for ; cmd != nil; cmd = cmd.Parent() {
+		if cmd.Name() == "config" && cmd.HasParent() && !cmd.Parent().HasParent() {
+			return true
+		}
+	}
+	return false
+}
+
+// contextOptions returns the client options set by the config context.
+func contextOptions() []controld.Option {
+	var opts []controld.Option
+	if settings.RateLimit > 0 {
+		opts = append(opts, controld.UsingRateLimit(settings.RateLimit))
+	}
+	if r := settings.Retry; r.MaxRetries != nil || r.MinDelay != nil || r.MaxDelay != nil {
+		opts = append(opts, controld.UsingRetryPolicy(
+			intOr(r.MaxRetries, defaultMaxRetries),
+			intOr(r.MinDelay, defaultMinRetryDelay),
+			intOr(r.MaxDelay, defaultMaxRetryDelay),
+		))
+	}
+	return opts
+}
+
+// Client retry defaults, used for retry settings a context leaves unset.
+const (
+	defaultMaxRetries    = 3
+	defaultMinRetryDelay = 1
+	defaultMaxRetryDelay = 30
+)
+
+func intOr(n *int, def int) int {
+	if n == nil {
+		return def
+	}
+	return *n
+}
+
 func getEnvOrDefault(key, def string) string {
 	if v := os.Getenv(key); v != "" {
 		return v
@@ -78,21 +215,78 @@ func getEnvOrDefault(key, def string) string {
 	return def
 }
 
+// terminalWidth returns the width of the terminal on stdout, or 0 when stdout
+// is not a terminal so piped tables are never truncated.
+func terminalWidth() int {
+	fd := int(os.Stdout.Fd())
+	if !term.IsTerminal(fd) {
+		return 0
+	}
+	width, _, err := term.GetSize(fd)
+	if err != nil {
+		return 0
+	}
+	return width
+}
+
 func Execute(args []string) error {
 	cmd := NewRootCmd()
 	cmd.SetArgs(args)
-	return cmd.Execute()
+	defer shutdownTelemetry()
+	err := cmd.Execute()
+	printDiagnosis(err)
+	return err
 }
 
 func ExecuteContext(ctx context.Context, args []string) error {
 	cmd := NewRootCmd()
 	cmd.SetArgs(args)
-	return cmd.ExecuteContext(ctx)
+	defer shutdownTelemetry()
+	err := cmd.ExecuteContext(ctx)
+	printDiagnosis(err)
+	return err
+}
+
+// shutdownTelemetry flushes any pending spans and metrics. It uses a fresh
+// context so data is still exported after the command was interrupted.
+func shutdownTelemetry() {
+	if tracing == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := tracing.Shutdown(ctx); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "warning: exporting telemetry: %v\n", err)
+	}
+	tracing = nil
 }
 
 func getClient(ctx context.Context) (*controld.API, error) {
+	return newClient(ctx, flags.Token, flags.Account, flags.NoCache)
+}
+
+// newClient returns a client for the given token, or for the account's
+// keyring token when token is empty. With noCache, responses are always
+// fetched rather than served from the response cache.
+func newClient(ctx context.Context, token, account string, noCache bool) (*controld.API, error) {
+	opts := append(contextOptions(), clientOptions...)
+	if p := telemetry.FromContext(ctx); p != nil {
+		traceOpts, err := p.ClientOptions()
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, traceOpts...)
+	}
+
+	baseURL := ""
+	if os.Getenv(config.EnvBaseURL) == "" {
+		baseURL = settings.BaseURL
+	}
 	return api.NewClient(ctx, api.ClientConfig{
-		Token:   flags.Token,
-		Account: flags.Account,
+		Token:   token,
+		Account: account,
+		BaseURL: baseURL,
+		NoCache: noCache,
+		Options: opts,
 	})
 }
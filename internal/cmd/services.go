@@ -42,21 +42,18 @@ func newServicesListCmd() *cobra.Command {
 				return err
 			}
 
-			if outfmt.IsJSON(cmd.Context()) {
-				return outfmt.WriteJSON(os.Stdout, services)
+			t := &outfmt.Table{
+				Columns: []outfmt.Column{
+					{Name: "service_id"},
+					{Name: "name"},
+					{Name: "category"},
+				},
+				Empty: "No services found",
 			}
-
-			if len(services) == 0 {
-				fmt.Println("No services found")
-				return nil
-			}
-
-			tw := outfmt.NewTabWriter(os.Stdout)
-			_, _ = fmt.Fprintln(tw, "SERVICE_ID\tNAME\tCATEGORY")
 			for _, s := range services {
-				_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\n", s.PK, s.Name, s.Category)
+				t.AddRow(s.PK, s.Name, s.Category)
 			}
-			return tw.Flush()
+			return outfmt.Write(cmd.Context(), os.Stdout, services, t)
 		},
 	}
 
@@ -79,16 +76,11 @@ func newServicesCategoriesCmd() *cobra.Command {
 				return err
 			}
 
-			if outfmt.IsJSON(cmd.Context()) {
-				return outfmt.WriteJSON(os.Stdout, categories)
-			}
-
-			tw := outfmt.NewTabWriter(os.Stdout)
-			_, _ = fmt.Fprintln(tw, "CATEGORY_ID\tNAME\tCOUNT")
+			t := &outfmt.Table{Columns: []outfmt.Column{{Name: "category_id"}, {Name: "name"}, {Name: "count"}}}
 			for _, c := range categories {
-				_, _ = fmt.Fprintf(tw, "%s\t%s\t%d\n", c.PK, c.Name, c.Count)
+				t.AddRow(c.PK, c.Name, fmt.Sprint(c.Count))
 			}
-			return tw.Flush()
+			return outfmt.Write(cmd.Context(), os.Stdout, categories, t)
 		},
 	}
 }
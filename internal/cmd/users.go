@@ -24,17 +24,18 @@ func newUsersCmd() *cobra.Command {
 				return err
 			}
 
-			if outfmt.IsJSON(cmd.Context()) {
-				return outfmt.WriteJSON(os.Stdout, user)
+			t := &outfmt.Table{
+				Columns: []outfmt.Column{
+					{Name: "email"},
+					{Name: "status"},
+					{Name: "resolver_ip"},
+					{Name: "stats_endpoint"},
+					{Name: "2fa"},
+				},
+				Detail: true,
 			}
-
-			tw := outfmt.NewTabWriter(os.Stdout)
-			_, _ = fmt.Fprintf(tw, "email\t%s\n", user.Email)
-			_, _ = fmt.Fprintf(tw, "status\t%v\n", user.Status)
-			_, _ = fmt.Fprintf(tw, "resolver_ip\t%s\n", user.ResolverIP)
-			_, _ = fmt.Fprintf(tw, "stats_endpoint\t%s\n", user.StatsEndpoint)
-			_, _ = fmt.Fprintf(tw, "2fa\t%v\n", user.Twofa)
-			return tw.Flush()
+			t.AddRow(user.Email, fmt.Sprint(user.Status), user.ResolverIP.String(), user.StatsEndpoint, fmt.Sprint(user.Twofa))
+			return outfmt.Write(cmd.Context(), os.Stdout, user, t)
 		},
 	}
 }
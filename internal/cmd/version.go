@@ -29,8 +29,20 @@ func newVersionCmd() *cobra.Command {
 				"arch":       runtime.GOARCH,
 			}
 
-			if outfmt.IsJSON(cmd.Context()) {
-				return outfmt.WriteJSON(cmd.OutOrStdout(), info)
+			if !outfmt.IsText(cmd.Context()) {
+				t := &outfmt.Table{
+					Columns: []outfmt.Column{
+						{Name: "version"},
+						{Name: "commit"},
+						{Name: "build_date"},
+						{Name: "go_version"},
+						{Name: "os"},
+						{Name: "arch"},
+					},
+					Detail: true,
+				}
+				t.AddRow(Version, Commit, BuildDate, runtime.Version(), runtime.GOOS, runtime.GOARCH)
+				return outfmt.Write(cmd.Context(), cmd.OutOrStdout(), info, t)
 			}
 
 			fmt.Printf("controld %s\n", Version)
@@ -0,0 +1,255 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the name of the configuration file in Dir.
+const FileName = "config.yaml"
+
+// File is the CLI configuration file. It holds named contexts, each a set of
+// defaults such as the account and output format, and which one is current.
+type File struct {
+	CurrentContext string              `yaml:"current-context,omitempty" json:"current_context,omitempty"`
+	Contexts       map[string]*Context `yaml:"contexts,omitempty" json:"contexts,omitempty"`
+	// Aliases map a command name to the arguments it expands to, such as
+	// "block: profiles rules create --action block". They apply to every
+	// context.
+	Aliases map[string]string `yaml:"aliases,omitempty" json:"aliases,omitempty"`
+}
+
+// Context is a named set of defaults. Flags and environment variables take
+// precedence over it.
+type Context struct {
+	Account   string  `yaml:"account,omitempty" json:"account,omitempty"`
+	Output    string  `yaml:"output,omitempty" json:"output,omitempty"`
+	Color     string  `yaml:"color,omitempty" json:"color,omitempty"`
+	Profile   string  `yaml:"profile,omitempty" json:"profile,omitempty"`
+	BaseURL   string  `yaml:"base-url,omitempty" json:"base_url,omitempty"`
+	RateLimit float64 `yaml:"rate-limit,omitempty" json:"rate_limit,omitempty"`
+	Retry     Retry   `yaml:"retry,omitempty" json:"retry,omitzero"`
+	// TokenMaxAge is how old a stored token may get, such as "90d", before
+	// auth commands warn that it is due for rotation.
+	TokenMaxAge string `yaml:"token-max-age,omitempty" json:"token_max_age,omitempty"`
+}
+
+// Retry overrides the client's retry policy. Unset fields keep the client
+// defaults.
+type Retry struct {
+	MaxRetries *int `yaml:"max-retries,omitempty" json:"max_retries,omitempty"`
+	// MinDelay and MaxDelay are in seconds.
+	MinDelay *int `yaml:"min-delay,omitempty" json:"min_delay,omitempty"`
+	MaxDelay *int `yaml:"max-delay,omitempty" json:"max_delay,omitempty"`
+}
+
+// ContextKeys are the keys accepted by Context.Get and Context.Set.
+var ContextKeys = []string{
+	"account", "output", "color", "profile", "base-url", "rate-limit",
+	"retry.max-retries", "retry.min-delay", "retry.max-delay", "token-max-age",
+}
+
+// FilePath returns the path of the configuration file.
+func FilePath() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, FileName), nil
+}
+
+// Load reads the configuration file. A missing file is an empty
+// configuration.
+func Load() (*File, error) {
+	path, err := FilePath()
+	if err != nil {
+		return nil, err
+	}
+	f := &File{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return f, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, f); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return f, nil
+}
+
+// Save writes the configuration file, replacing it atomically.
+func (f *File) Save() error {
+	path, err := FilePath()
+	if err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(f)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), FileName+".*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Names returns the context names in sorted order.
+func (f *File) Names() []string {
+	names := make([]string, 0, len(f.Contexts))
+	for name := range f.Contexts {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// AliasNames returns the alias names in sorted order.
+func (f *File) AliasNames() []string {
+	names := make([]string, 0, len(f.Aliases))
+	for name := range f.Aliases {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Select returns the named context, or the current context when name is
+// empty. With no name and no current context it returns an empty context.
+func (f *File) Select(name string) (*Context, error) {
+	if name == "" {
+		name = f.CurrentContext
+	}
+	if name == "" {
+		return &Context{}, nil
+	}
+	c, ok := f.Contexts[name]
+	if !ok {
+		return nil, fmt.Errorf("context %q not found", name)
+	}
+	return c, nil
+}
+
+// Context returns the named context, creating it if needed.
+func (f *File) Context(name string) *Context {
+	if f.Contexts == nil {
+		f.Contexts = map[string]*Context{}
+	}
+	c, ok := f.Contexts[name]
+	if !ok {
+		c = &Context{}
+		f.Contexts[name] = c
+	}
+	return c
+}
+
+// Get returns the value of key, or "" if it is unset.
+func (c *Context) Get(key string) (string, error) {
+	switch key {
+	case "account":
+		return c.Account, nil
+	case "output":
+		return c.Output, nil
+	case "color":
+		return c.Color, nil
+	case "profile":
+		return c.Profile, nil
+	case "base-url":
+		return c.BaseURL, nil
+	case "rate-limit":
+		if c.RateLimit == 0 {
+			return "", nil
+		}
+		return strconv.FormatFloat(c.RateLimit, 'f', -1, 64), nil
+	case "retry.max-retries":
+		return formatInt(c.Retry.MaxRetries), nil
+	case "retry.min-delay":
+		return formatInt(c.Retry.MinDelay), nil
+	case "retry.max-delay":
+		return formatInt(c.Retry.MaxDelay), nil
+	case "token-max-age":
+		return c.TokenMaxAge, nil
+	default:
+		return "", unknownKey(key)
+	}
+}
+
+// Set sets key to value. An empty value unsets the key.
+func (c *Context) Set(key, value string) error {
+	var err error
+	switch key {
+	case "account":
+		c.Account = value
+	case "output":
+		c.Output = value
+	case "color":
+		if value != "" && value != "auto" && value != "always" && value != "never" {
+			return fmt.Errorf("invalid color %q: must be 'auto', 'always' or 'never'", value)
+		}
+		c.Color = value
+	case "profile":
+		c.Profile = value
+	case "base-url":
+		c.BaseURL = value
+	case "rate-limit":
+		c.RateLimit = 0
+		if value != "" {
+			c.RateLimit, err = strconv.ParseFloat(value, 64)
+			if err != nil || c.RateLimit <= 0 {
+				c.RateLimit = 0
+				return fmt.Errorf("invalid rate-limit %q: must be a positive number of requests per second", value)
+			}
+		}
+	case "retry.max-retries":
+		c.Retry.MaxRetries, err = parseInt(key, value)
+	case "retry.min-delay":
+		c.Retry.MinDelay, err = parseInt(key, value)
+	case "retry.max-delay":
+		c.Retry.MaxDelay, err = parseInt(key, value)
+	case "token-max-age":
+		c.TokenMaxAge = value
+	default:
+		return unknownKey(key)
+	}
+	return err
+}
+
+func unknownKey(key string) error {
+	return fmt.Errorf("unknown config key %q: must be one of %s", key, strings.Join(ContextKeys, ", "))
+}
+
+func formatInt(n *int) string {
+	if n == nil {
+		return ""
+	}
+	return strconv.Itoa(*n)
+}
+
+func parseInt(key, value string) (*int, error) {
+	if value == "" {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("invalid %s %q: must be a non-negative integer", key, value)
+	}
+	return &n, nil
+}
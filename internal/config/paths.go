@@ -1,9 +1,41 @@
 package config
 
+import (
+	"os"
+	"path/filepath"
+)
+
 const (
-	AppName   = "controld-cli"
-	EnvPrefix = "CONTROLD"
-	EnvToken  = "CONTROLD_API_TOKEN"
-	EnvOutput = "CONTROLD_OUTPUT"
-	EnvColor  = "CONTROLD_COLOR"
+	AppName    = "controld-cli"
+	EnvPrefix  = "CONTROLD"
+	EnvToken   = "CONTROLD_API_TOKEN"
+	EnvAccount = "CONTROLD_ACCOUNT"
+	EnvContext = "CONTROLD_CONTEXT"
+	EnvOutput  = "CONTROLD_OUTPUT"
+	EnvColor   = "CONTROLD_COLOR"
+	EnvBaseURL = "CONTROLD_BASE_URL"
+
+	EnvRedactKeys = "CONTROLD_REDACT_KEYS"
 )
+
+// Dir returns the CLI configuration directory, creating it if needed.
+func Dir() (string, error) {
+	// Use XDG_CONFIG_HOME if set, otherwise ~/.config
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		configHome = filepath.Join(home, ".config")
+	}
+
+	configDir := filepath.Join(configHome, AppName)
+
+	// Ensure the directory exists
+	if err := os.MkdirAll(configDir, 0700); err != nil {
+		return "", err
+	}
+
+	return configDir, nil
+}
@@ -2,8 +2,6 @@ package controld
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
 	"net"
 	"net/http"
 )
@@ -28,7 +26,7 @@ type ListKnownIPsResponse struct {
 }
 
 type ListKnownIPsParams struct {
-	DeviceID string `json:"device_id"`
+	DeviceID string `json:"device_id" url:"device_id"`
 }
 
 type LearnNewIPsParams struct {
@@ -54,15 +52,11 @@ type DeleteLearnedIPsResponse struct {
 }
 
 func (api *API) ListKnownIPs(ctx context.Context, params ListKnownIPsParams) ([]KnownIP, error) {
-	uri := buildURI("/access", nil)
+	uri := buildURI("/access", params)
 
-	res, err := api.makeRequestContext(ctx, http.MethodGet, uri, params)
+	r, err := call[ListKnownIPsResponse](ctx, api, http.MethodGet, uri, nil)
 	if err != nil {
-		return []KnownIP{}, fmt.Errorf("%s: %w", errMakeRequestError, err)
-	}
-	var r ListKnownIPsResponse
-	if err := json.Unmarshal(res, &r); err != nil {
-		return []KnownIP{}, fmt.Errorf("%s: %w", errUnmarshalError, err)
+		return nil, err
 	}
 	return r.Body.IPs, nil
 }
@@ -70,16 +64,9 @@ func (api *API) ListKnownIPs(ctx context.Context, params ListKnownIPsParams) ([]
 func (api *API) LearnNewIPs(ctx context.Context, params LearnNewIPsParams) ([]any, error) {
 	uri := buildURI("/access", nil)
 
-	res, err := api.makeRequestContext(ctx, http.MethodPost, uri, params)
-	if err != nil {
-		return []any{}, fmt.Errorf("%s: %w", errMakeRequestError, err)
-	}
-
-	var r LearnNewIPsResponse
-
-	err = json.Unmarshal(res, &r)
+	r, err := call[LearnNewIPsResponse](ctx, api, http.MethodPost, uri, params)
 	if err != nil {
-		return []any{}, fmt.Errorf("%s: %w", errUnmarshalError, err)
+		return nil, err
 	}
 	return r.Body, nil
 }
@@ -87,16 +74,9 @@ func (api *API) LearnNewIPs(ctx context.Context, params LearnNewIPsParams) ([]an
 func (api *API) DeleteLearnedIPs(ctx context.Context, params DeleteLearnedIPsParams) ([]any, error) {
 	uri := buildURI("/access", nil)
 
-	res, err := api.makeRequestContext(ctx, http.MethodDelete, uri, params)
-	if err != nil {
-		return []any{}, fmt.Errorf("%s: %w", errMakeRequestError, err)
-	}
-
-	var r DeleteLearnedIPsResponse
-
-	err = json.Unmarshal(res, &r)
+	r, err := call[DeleteLearnedIPsResponse](ctx, api, http.MethodDelete, uri, params)
 	if err != nil {
-		return []any{}, fmt.Errorf("%s: %w", errUnmarshalError, err)
+		return nil, err
 	}
 	return r.Body, nil
 }
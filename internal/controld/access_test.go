@@ -0,0 +1,27 @@
+package controld
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListKnownIPs(t *testing.T) {
+	api, requests := setup(t, `{"success":true,"body":{"ips":[{"ip":"1.2.3.4","ts":1700000000,"country":"CA"}]}}`)
+
+	ips, err := api.ListKnownIPs(context.Background(), ListKnownIPsParams{DeviceID: "dev 1"})
+
+	require.NoError(t, err)
+	require.Len(t, ips, 1)
+	assert.Equal(t, "1.2.3.4", ips[0].IP.String())
+
+	require.Len(t, *requests, 1)
+	req := (*requests)[0]
+	assert.Equal(t, http.MethodGet, req.Method)
+	assert.Equal(t, "/access", req.Path)
+	assert.Equal(t, "device_id=dev+1", req.Query)
+	assert.Empty(t, req.Body)
+}
@@ -3,7 +3,6 @@ package controld
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 	"net"
 	"net/http"
 	"time"
@@ -54,13 +53,9 @@ type ListUserResponse struct {
 func (api *API) ListUser(ctx context.Context) (User, error) {
 	uri := buildURI("/users", nil)
 
-	res, err := api.makeRequestContext(ctx, http.MethodGet, uri, nil)
+	r, err := call[ListUserResponse](ctx, api, http.MethodGet, uri, nil)
 	if err != nil {
-		return User{}, fmt.Errorf("%s: %w", errMakeRequestError, err)
-	}
-	var r ListUserResponse
-	if err := json.Unmarshal(res, &r); err != nil {
-		return User{}, fmt.Errorf("%s: %w", errUnmarshalError, err)
+		return User{}, err
 	}
 	return r.Body, nil
 }
@@ -2,8 +2,6 @@ package controld
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
 	"net/http"
 )
 
@@ -39,16 +37,9 @@ type ListStorageRegionsResponse struct {
 func (api *API) ListLogLevels(ctx context.Context) ([]LogLevel, error) {
 	uri := buildURI("/analytics/levels", nil)
 
-	res, err := api.makeRequestContext(ctx, http.MethodGet, uri, nil)
+	r, err := call[ListLogLevelsResponse](ctx, api, http.MethodGet, uri, nil)
 	if err != nil {
-		return []LogLevel{}, fmt.Errorf("%s: %w", errMakeRequestError, err)
-	}
-
-	var r ListLogLevelsResponse
-
-	err = json.Unmarshal(res, &r)
-	if err != nil {
-		return []LogLevel{}, fmt.Errorf("%s: %w", errUnmarshalError, err)
+		return nil, err
 	}
 	return r.Body.Levels, nil
 }
@@ -56,16 +47,9 @@ func (api *API) ListLogLevels(ctx context.Context) ([]LogLevel, error) {
 func (api *API) ListStorageRegions(ctx context.Context) ([]Endpoint, error) {
 	uri := buildURI("/analytics/endpoints", nil)
 
-	res, err := api.makeRequestContext(ctx, http.MethodGet, uri, nil)
-	if err != nil {
-		return []Endpoint{}, fmt.Errorf("%s: %w", errMakeRequestError, err)
-	}
-
-	var r ListStorageRegionsResponse
-
-	err = json.Unmarshal(res, &r)
+	r, err := call[ListStorageRegionsResponse](ctx, api, http.MethodGet, uri, nil)
 	if err != nil {
-		return []Endpoint{}, fmt.Errorf("%s: %w", errUnmarshalError, err)
+		return nil, err
 	}
 	return r.Body.Endpoint, nil
 }
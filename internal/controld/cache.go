@@ -0,0 +1,215 @@
+package controld
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ResponseCache stores the bodies of successful GET responses. Keys identify
+// the account and request; see UsingResponseCache. Implementations must be
+// safe for concurrent use.
+type ResponseCache interface {
+	// Get returns the body stored under key if it is at most maxAge old.
+	Get(key string, maxAge time.Duration) ([]byte, bool)
+	// Set stores body under key.
+	Set(key string, body []byte)
+	// DeleteFunc removes every entry whose key match reports true for.
+	DeleteFunc(match func(key string) bool)
+}
+
+// cacheTTLs is how long each endpoint's responses may be served from the
+// cache. Catalog data that rarely changes is kept longer than account data.
+// Endpoints not listed, such as /ip, are never cached.
+var cacheTTLs = map[string]time.Duration{
+	"ListUser":                   time.Minute,
+	"ListNetwork":                time.Hour,
+	"ListLogLevels":              time.Hour,
+	"ListStorageRegions":         time.Hour,
+	"ListProfiles":               30 * time.Second,
+	"ListProfilesOptions":        time.Hour,
+	"ListProfileNativeFilters":   30 * time.Second,
+	"ListProfileExternalFilters": 30 * time.Second,
+	"ListProfileServices":        30 * time.Second,
+	"ListServiceCategories":      time.Hour,
+	"ListServices":               time.Hour,
+	"ListProfileRuleFolders":     30 * time.Second,
+	"ListProfileCustomRules":     30 * time.Second,
+	"ListProfileDefaultRule":     30 * time.Second,
+	"ListDevices":                30 * time.Second,
+	"ListDeviceType":             time.Hour,
+	"ListKnownIPs":               30 * time.Second,
+}
+
+// cacheInvalidations lists the paths whose cached responses a mutation makes
+// stale. A trailing "/*" also matches every path below. Mutations not listed
+// clear the account's whole cache.
+var cacheInvalidations = map[string][]string{
+	"CreateProfile":            {"/profiles"},
+	"UpdateProfile":            {"/profiles"},
+	"DeleteProfile":            {"/profiles", "/profiles/{profile_id}/*", "/devices"},
+	"UpdateProfilesOption":     {"/profiles"},
+	"UpdateProfileFilter":      {"/profiles", "/profiles/{profile_id}/filters/*"},
+	"UpdateProfileService":     {"/profiles", "/profiles/{profile_id}/services"},
+	"CreateProfileRuleFolder":  {"/profiles", "/profiles/{profile_id}/groups", "/profiles/{profile_id}/rules/*"},
+	"UpdateProfileRuleFolder":  {"/profiles", "/profiles/{profile_id}/groups", "/profiles/{profile_id}/rules/*"},
+	"DeleteProfileRuleFolder":  {"/profiles", "/profiles/{profile_id}/groups", "/profiles/{profile_id}/rules/*"},
+	"CreateProfileCustomRule":  {"/profiles", "/profiles/{profile_id}/groups", "/profiles/{profile_id}/rules/*"},
+	"UpdateProfileCustomRule":  {"/profiles", "/profiles/{profile_id}/groups", "/profiles/{profile_id}/rules/*"},
+	"DeleteProfileCustomRule":  {"/profiles", "/profiles/{profile_id}/groups", "/profiles/{profile_id}/rules/*"},
+	"UpdateProfileDefaultRule": {"/profiles/{profile_id}/default"},
+	"CreateDevice":             {"/devices"},
+	"UpdateDevice":             {"/devices"},
+	"DeleteDevice":             {"/devices", "/access"},
+	"LearnNewIPs":              {"/access"},
+	"DeleteLearnedIPs":         {"/access"},
+}
+
+// cacheScope returns the key prefix for this client's account, so clients
+// with different tokens or base URLs never share entries.
+func (api *API) cacheScope() string {
+	sum := sha256.Sum256([]byte(api.BaseURL + "\x00" + api.APIToken))
+	return hex.EncodeToString(sum[:8]) + " "
+}
+
+// cachedResponse returns the cached response for a GET request, if any.
+func (api *API) cachedResponse(method, uri string) (*APIResponse, bool) {
+	if api.cache == nil || method != http.MethodGet {
+		return nil, false
+	}
+	r, ok := matchRoute(method, uri)
+	if !ok || cacheTTLs[r.operation] == 0 {
+		return nil, false
+	}
+	body, ok := api.cache.Get(api.cacheScope()+uri, cacheTTLs[r.operation])
+	if !ok {
+		return nil, false
+	}
+	api.logger.Printf("Serving %s %s from cache", method, uri)
+	return &APIResponse{Body: body, StatusCode: http.StatusOK, Status: "200 OK"}, true
+}
+
+// updateCache stores a successful GET response, or invalidates the entries a
+// mutation affects. Mutations invalidate even when they fail, since the API
+// may have applied them anyway.
+func (api *API) updateCache(method, uri string, res *APIResponse) {
+	if api.cache == nil || method == http.MethodHead || method == http.MethodOptions {
+		return
+	}
+
+	if method == http.MethodGet {
+		if res == nil {
+			return
+		}
+		if r, ok := matchRoute(method, uri); ok && cacheTTLs[r.operation] > 0 {
+			api.cache.Set(api.cacheScope()+uri, res.Body)
+		}
+		return
+	}
+
+	scope := api.cacheScope()
+	paths, ok := invalidatedPaths(method, uri)
+	api.cache.DeleteFunc(func(key string) bool {
+		rest, inScope := strings.CutPrefix(key, scope)
+		if !inScope {
+			return false
+		}
+		if !ok {
+			return true
+		}
+		path, _, _ := strings.Cut(rest, "?")
+		for _, p := range paths {
+			if sub, found := strings.CutSuffix(p, "/*"); found {
+				if path == sub || strings.HasPrefix(path, sub+"/") {
+					return true
+				}
+			} else if path == p {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// invalidatedPaths returns the paths a mutation makes stale, with the path
+// parameters of the request filled in. It reports false for unknown
+// mutations.
+func invalidatedPaths(method, uri string) ([]string, bool) {
+	r, ok := matchRoute(method, uri)
+	if !ok {
+		return nil, false
+	}
+	templates, ok := cacheInvalidations[r.operation]
+	if !ok {
+		return nil, false
+	}
+
+	path := uri
+	if u, err := url.Parse(uri); err == nil {
+		path = u.Path
+	}
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	replacements := []string{}
+	for i, p := range strings.Split(strings.Trim(r.path, "/"), "/") {
+		if strings.HasPrefix(p, "{") {
+			replacements = append(replacements, p, segments[i])
+		}
+	}
+	replacer := strings.NewReplacer(replacements...)
+
+	paths := make([]string, len(templates))
+	for i, t := range templates {
+		paths[i] = replacer.Replace(t)
+	}
+	return paths, true
+}
+
+// MemoryCache is a ResponseCache that lives for the life of the process.
+type MemoryCache struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+	now     func() time.Time
+}
+
+type memoryEntry struct {
+	body     []byte
+	storedAt time.Time
+}
+
+// NewMemoryCache returns an empty in-memory cache.
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{entries: map[string]memoryEntry{}, now: time.Now}
+}
+
+// Get implements ResponseCache.
+func (c *MemoryCache) Get(key string, maxAge time.Duration) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok || c.now().Sub(e.storedAt) > maxAge {
+		return nil, false
+	}
+	return e.body, true
+}
+
+// Set implements ResponseCache.
+func (c *MemoryCache) Set(key string, body []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = memoryEntry{body: body, storedAt: c.now()}
+}
+
+// DeleteFunc implements ResponseCache.
+func (c *MemoryCache) DeleteFunc(match func(key string) bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key := range c.entries {
+		if match(key) {
+			delete(c.entries, key)
+		}
+	}
+}
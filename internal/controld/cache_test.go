@@ -0,0 +1,124 @@
+package controld
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseCache(t *testing.T) {
+	api, requests := setup(t, `{"success":true,"body":{}}`)
+	cache := NewMemoryCache()
+	require.NoError(t, api.parseOptions(UsingResponseCache(cache)))
+
+	get := func(uri string) {
+		t.Helper()
+		_, err := api.Raw(context.Background(), http.MethodGet, uri, nil, nil)
+		require.NoError(t, err)
+	}
+	count := func() int { return len(*requests) }
+
+	get("/profiles")
+	get("/profiles")
+	get("/profiles/p1/services")
+	get("/profiles/p2/services")
+	get("/access?device_id=d1")
+	get("/access?device_id=d2")
+	assert.Equal(t, 5, count(), "second profiles list is served from cache")
+
+	// /ip is never cached.
+	get("/ip")
+	get("/ip")
+	assert.Equal(t, 7, count())
+
+	// Updating a service invalidates that profile's services and the profile
+	// list, but not other profiles.
+	_, err := api.Raw(context.Background(), http.MethodPut, "/profiles/p1/services/netflix", map[string]int{"do": 0}, nil)
+	require.NoError(t, err)
+	n := count()
+	get("/profiles/p2/services")
+	assert.Equal(t, n, count())
+	get("/profiles/p1/services")
+	get("/profiles")
+	assert.Equal(t, n+2, count())
+
+	// Every query of an invalidated path is dropped.
+	_, err = api.Raw(context.Background(), http.MethodPost, "/access", map[string]string{"device_id": "d1"}, nil)
+	require.NoError(t, err)
+	n = count()
+	get("/access?device_id=d1")
+	get("/access?device_id=d2")
+	assert.Equal(t, n+2, count())
+
+	// Unknown mutations clear everything.
+	_, err = api.Raw(context.Background(), http.MethodPost, "/something/new", nil, nil)
+	require.NoError(t, err)
+	n = count()
+	get("/profiles/p2/services")
+	assert.Equal(t, n+1, count())
+}
+
+func TestResponseCacheTTL(t *testing.T) {
+	api, requests := setup(t, `{"success":true,"body":{}}`)
+	cache := NewMemoryCache()
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+	require.NoError(t, api.parseOptions(UsingResponseCache(cache)))
+
+	for range 2 {
+		_, err := api.Raw(context.Background(), http.MethodGet, "/profiles", nil, nil)
+		require.NoError(t, err)
+		_, err = api.Raw(context.Background(), http.MethodGet, "/services/categories", nil, nil)
+		require.NoError(t, err)
+	}
+	assert.Len(t, *requests, 2)
+
+	// Profiles expire before the service catalog.
+	now = now.Add(time.Minute)
+	_, err := api.Raw(context.Background(), http.MethodGet, "/profiles", nil, nil)
+	require.NoError(t, err)
+	_, err = api.Raw(context.Background(), http.MethodGet, "/services/categories", nil, nil)
+	require.NoError(t, err)
+	assert.Len(t, *requests, 3)
+}
+
+func TestResponseCacheScopedByToken(t *testing.T) {
+	api, requests := setup(t, `{"success":true,"body":{}}`)
+	cache := NewMemoryCache()
+	require.NoError(t, api.parseOptions(UsingResponseCache(cache)))
+
+	_, err := api.Raw(context.Background(), http.MethodGet, "/devices", nil, nil)
+	require.NoError(t, err)
+
+	other, err := New("other-token", BaseURL(api.BaseURL), UsingRateLimit(1000), UsingResponseCache(cache))
+	require.NoError(t, err)
+	_, err = other.Raw(context.Background(), http.MethodGet, "/devices", nil, nil)
+	require.NoError(t, err)
+
+	assert.Len(t, *requests, 2)
+}
+
+func TestResponseCacheSkipsErrors(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = io.WriteString(w, `{"success":false,"error":{"message":"nope","code":400}}`)
+	}))
+	t.Cleanup(server.Close)
+
+	api, err := New("test-token", BaseURL(server.URL), UsingRateLimit(1000), UsingResponseCache(NewMemoryCache()))
+	require.NoError(t, err)
+
+	for range 2 {
+		_, err := api.Raw(context.Background(), http.MethodGet, "/devices", nil, nil)
+		require.Error(t, err)
+	}
+	assert.Equal(t, 2, calls)
+}
@@ -9,10 +9,10 @@ import (
 	"golang.org/x/time/rate"
 	"io"
 	"log"
-	"math"
+	"log/slog"
 	"net/http"
-	"net/http/httputil"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -28,7 +28,13 @@ type API struct {
 	rateLimiter *rate.Limiter
 	retryPolicy RetryPolicy
 	logger      Logger
+	statsHooks  []func(context.Context, RequestStats)
+	middleware  []Middleware
 	Debug       bool
+	dump        DumpLevel
+	debugLog    *slog.Logger
+	redactKeys  []string
+	cache       ResponseCache
 }
 
 // newClient provides shared logic for New and NewWithUserServiceKey.
@@ -125,39 +131,69 @@ func (api *API) makeRequestContextWithHeadersComplete(ctx context.Context, metho
 }
 
 func (api *API) makeRequestWithAuthTypeAndHeadersComplete(ctx context.Context, method, uri string, params interface{}, headers http.Header) (*APIResponse, error) {
-	var err error
+	if res, ok := api.cachedResponse(method, uri); ok && params == nil {
+		return res, nil
+	}
+
+	res, err := api.do(ctx, method, uri, params, headers)
+	api.updateCache(method, uri, res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// do sends a request, retrying it according to the client's retry policy,
+// and returns the response or the API error it describes.
+func (api *API) do(ctx context.Context, method, uri string, params interface{}, headers http.Header) (_ *APIResponse, err error) {
 	var resp *http.Response
 	var respErr error
 	var respBody []byte
 
-	for i := 0; i <= api.retryPolicy.MaxRetries; i++ {
-		var reqBody io.Reader
-		if params != nil {
-			if r, ok := params.(io.Reader); ok {
-				reqBody = r
-			} else if paramBytes, ok := params.([]byte); ok {
-				reqBody = bytes.NewReader(paramBytes)
-			} else {
-				var jsonBody []byte
-				jsonBody, err = json.Marshal(params)
-				if err != nil {
-					return nil, fmt.Errorf("error marshalling params to JSON: %w", err)
-				}
-				reqBody = bytes.NewReader(jsonBody)
+	// GET and HEAD requests carry their parameters in the query string; a
+	// body would be silently ignored by the API.
+	if params != nil && (method == http.MethodGet || method == http.MethodHead) {
+		return nil, fmt.Errorf("%s %s: request body is not allowed, use query parameters", method, uri)
+	}
+
+	// Encode the body once so every attempt sends the same bytes.
+	var body []byte
+	if params != nil {
+		switch p := params.(type) {
+		case []byte:
+			body = p
+		case io.Reader:
+			body, err = io.ReadAll(p)
+			if err != nil {
+				return nil, fmt.Errorf("error reading request body: %w", err)
+			}
+		default:
+			body, err = json.Marshal(params)
+			if err != nil {
+				return nil, fmt.Errorf("error marshalling params to JSON: %w", err)
 			}
 		}
+	}
 
-		if i > 0 {
-			// expect the backoff introduced here on errored requests to dominate the effect of rate limiting
-			// don't need a random component here as the rate limiter should do something similar
-			// nb time duration could truncate an arbitrary float. Since our inputs are all ints, we should be ok
-			sleepDuration := time.Duration(math.Pow(2, float64(i-1)) * float64(api.retryPolicy.MinRetryDelay))
+	stats := RequestStats{Method: method, URI: uri, Start: time.Now()}
+	stats.Operation, stats.Template = Operation(method, uri)
+	defer func() {
+		stats.Duration = time.Since(stats.Start)
+		stats.Err = err
+		if stats.Retries() > 0 {
+			api.logger.Printf("Request %s %s finished after %d retries (waited %s)", method, uri, stats.Retries(), stats.RetryWait)
+		}
+		for _, hook := range api.statsHooks {
+			hook(ctx, stats)
+		}
+	}()
 
-			if sleepDuration > api.retryPolicy.MaxRetryDelay {
-				sleepDuration = api.retryPolicy.MaxRetryDelay
-			}
-			// useful to do some simple logging here, maybe introduce levels later
+	var retryAfter time.Duration
+	for i := 0; i <= api.retryPolicy.MaxRetries; i++ {
+		if i > 0 {
+			sleepDuration := api.retryDelay(i, retryAfter)
 			api.logger.Printf("Sleeping %s before retry attempt number %d for request %s %s", sleepDuration.String(), i, method, uri)
+			stats.RetryWait += sleepDuration
 
 			select {
 			case <-time.After(sleepDuration):
@@ -166,36 +202,63 @@ func (api *API) makeRequestWithAuthTypeAndHeadersComplete(ctx context.Context, m
 			}
 		}
 
+		waitStart := time.Now()
 		err = api.rateLimiter.Wait(ctx)
+		stats.RateLimitWait += time.Since(waitStart)
 		if err != nil {
 			return nil, fmt.Errorf("error caused by request rate limiting: %w", err)
 		}
 
-		resp, respErr = api.request(ctx, method, uri, reqBody, headers)
+		stats.Attempts++
+		resp, respErr = api.request(ctx, method, uri, body, headers, stats.Attempts)
 
-		// short circuit processing on context timeouts
-		if respErr != nil && errors.Is(respErr, context.DeadlineExceeded) {
+		// short circuit processing on context timeouts and cancellation
+		if respErr != nil && ctx.Err() != nil {
 			return nil, respErr
 		}
 
-		// retry if the server is rate limiting us or if it failed
-		// assumes server operations are rolled back on failure
-		if respErr != nil || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
-			if resp != nil && resp.StatusCode == http.StatusTooManyRequests {
-				respErr = errors.New("exceeded available rate limit retries")
-			}
+		if respErr == nil {
+			stats.StatusCode = resp.StatusCode
+		}
 
-			if respErr == nil {
-				respErr = fmt.Errorf("received %s response (HTTP %d), please try again later", strings.ToLower(http.StatusText(resp.StatusCode)), resp.StatusCode)
-			}
-			continue
-		} else {
+		if respErr == nil && !retryableStatus(resp.StatusCode) {
 			respBody, err = io.ReadAll(resp.Body)
-			defer func() { _ = resp.Body.Close() }()
+			_ = resp.Body.Close()
 			if err != nil {
 				return nil, fmt.Errorf("could not read response body: %w", err)
 			}
+			break
+		}
 
+		retry := shouldRetry(method, resp, respErr)
+
+		// retry if the server is rate limiting us or if it failed
+		retryAfter = 0
+		if respErr == nil {
+			retryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+			// Keep the body: gateways and proxies answer 5xx with HTML
+			// pages that tell what is in the way.
+			failBody, _ := io.ReadAll(resp.Body)
+			_ = resp.Body.Close()
+
+			switch {
+			case resp.StatusCode == http.StatusTooManyRequests:
+				respErr = errors.New("exceeded available rate limit retries")
+			case len(bytes.TrimSpace(failBody)) > 0 && !json.Valid(failBody):
+				respErr = newUnexpectedResponseError(resp, failBody)
+			default:
+				respErr = fmt.Errorf("received %s response (HTTP %d), please try again later", strings.ToLower(http.StatusText(resp.StatusCode)), resp.StatusCode)
+			}
+		}
+
+		if !retry {
+			api.logger.Printf("Not retrying %s %s: the request may already have been applied", method, uri)
+			break
+		}
+		// Waiting less than the server asks would only fail again.
+		if retryAfter > api.retryPolicy.MaxRetryDelay && i < api.retryPolicy.MaxRetries {
+			respErr = fmt.Errorf("%w: the server asked to retry after %s, longer than the maximum retry delay of %s",
+				respErr, retryAfter, api.retryPolicy.MaxRetryDelay)
 			break
 		}
 	}
@@ -218,31 +281,20 @@ func (api *API) makeRequestWithAuthTypeAndHeadersComplete(ctx context.Context, m
 		errBody := &Response{}
 		err = json.Unmarshal(respBody, &errBody)
 		if err != nil {
-			return nil, fmt.Errorf(errUnmarshalErrorBody+": %w", err)
+			return nil, newUnexpectedResponseError(resp, respBody)
 		}
 
-		err := &Error{
-			StatusCode: resp.StatusCode,
-			Error:      errBody.Error,
-		}
+		return nil, newAPIError(resp.StatusCode, errBody.Error)
+	}
 
-		switch resp.StatusCode {
-		case http.StatusUnauthorized:
-			err.Type = ErrorTypeAuthorization
-			return nil, &AuthorizationError{controldError: err}
-		case http.StatusForbidden:
-			err.Type = ErrorTypeAuthentication
-			return nil, &AuthenticationError{controldError: err}
-		case http.StatusNotFound:
-			err.Type = ErrorTypeNotFound
-			return nil, &NotFoundError{controldError: err}
-		case http.StatusTooManyRequests:
-			err.Type = ErrorTypeRateLimit
-			return nil, &RatelimitError{controldError: err}
-		default:
-			err.Type = ErrorTypeRequest
-			return nil, &RequestError{controldError: err}
-		}
+	if len(respBody) > 0 && !json.Valid(respBody) {
+		return nil, newUnexpectedResponseError(resp, respBody)
+	}
+
+	// The API also reports some failures with a 2xx status and success set
+	// to false.
+	if info, failed := unsuccessful(respBody); failed {
+		return nil, newAPIError(resp.StatusCode, info)
 	}
 
 	return &APIResponse{
@@ -254,10 +306,11 @@ func (api *API) makeRequestWithAuthTypeAndHeadersComplete(ctx context.Context, m
 }
 
 // request makes a HTTP request to the given API endpoint, returning the raw
-// *http.Response, or an error if one occurred. The caller is responsible for
-// closing the response body.
-func (api *API) request(ctx context.Context, method, uri string, reqBody io.Reader, headers http.Header) (*http.Response, error) {
-	req, err := http.NewRequestWithContext(ctx, method, api.BaseURL+uri, reqBody)
+// *http.Response, or an error if one occurred. The request passes through the
+// client's middleware. The caller is responsible for closing the response
+// body.
+func (api *API) request(ctx context.Context, method, uri string, body []byte, headers http.Header, attempt int) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(api.BaseURL, "/")+uri, nil)
 	if err != nil {
 		return nil, fmt.Errorf("HTTP request creation failed: %w", err)
 	}
@@ -277,26 +330,43 @@ func (api *API) request(ctx context.Context, method, uri string, reqBody io.Read
 		req.Header.Set("Content-Type", "application/json")
 	}
 
-	if api.Debug {
-		dump, err := httputil.DumpRequestOut(req, true)
-		if err != nil {
-			return nil, err
+	return api.chain(api.send)(&Request{
+		HTTP:    req,
+		Method:  method,
+		URI:     uri,
+		Body:    body,
+		Attempt: attempt,
+	})
+}
+
+// send is the innermost Handler: it attaches the body and performs the HTTP
+// round trip.
+func (api *API) send(r *Request) (*http.Response, error) {
+	req := r.HTTP
+	if r.Body != nil {
+		body := r.Body
+		req.ContentLength = int64(len(body))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
 		}
+		req.Body, _ = req.GetBody()
+	}
 
-		log.Printf("\n%s", string(dump))
+	level := api.dumpLevel()
+	if level != DumpNone {
+		api.dumpRequest(req.Context(), level, r)
 	}
 
+	start := time.Now()
 	resp, err := api.httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("HTTP request failed: %w", err)
 	}
 
-	if api.Debug {
-		dump, err := httputil.DumpResponse(resp, true)
-		if err != nil {
+	if level != DumpNone {
+		if err := api.dumpResponse(req.Context(), level, resp, time.Since(start)); err != nil {
 			return resp, err
 		}
-		log.Printf("\n%s", string(dump))
 	}
 
 	return resp, nil
@@ -310,21 +380,49 @@ func copyHeader(target, source http.Header) {
 	}
 }
 
+// DateTime is a timestamp the API sends as an RFC 1123 string, e.g. in error
+// replies. RFC 3339 strings and Unix seconds are accepted too; null and ""
+// decode to the zero time, which encodes as null.
 type DateTime struct {
 	time.Time
 }
 
+// dateTimeLayouts are the formats DateTime accepts, in order of preference.
+var dateTimeLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05",
+}
+
 func (dt DateTime) MarshalJSON() ([]byte, error) {
-	return []byte(dt.Format(time.RFC3339)), nil
+	if dt.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(dt.Format(time.RFC3339))
 }
+
 func (dt *DateTime) UnmarshalJSON(data []byte) error {
-	var dateTimeStr = string(data[1 : len(data)-1])
-	parse, err := time.Parse(time.RFC1123Z, dateTimeStr)
+	value, ok, err := jsonScalar(data)
 	if err != nil {
-		return err
+		return fmt.Errorf("date time: %w", err)
+	}
+	if !ok || value == "" {
+		dt.Time = time.Time{}
+		return nil
+	}
+
+	for _, layout := range dateTimeLayouts {
+		if parse, err := time.Parse(layout, value); err == nil {
+			dt.Time = parse
+			return nil
+		}
 	}
-	dt.Time = parse
-	return nil
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		dt.Time = time.Unix(seconds, 0).UTC()
+		return nil
+	}
+	return fmt.Errorf("date time: unrecognized format %q", value)
 }
 
 // ResponseInfo contains a code and message returned by the API as errors or
@@ -342,6 +440,30 @@ type Response struct {
 	Error   ResponseInfo `json:"error"`
 }
 
+// envelope returns the status part of a response. It is promoted to every
+// typed response, which all embed Response.
+func (r Response) envelope() Response {
+	return r
+}
+
+// call makes a request and decodes the reply into R, one of the typed
+// responses. Replies whose success field is false are turned into errors by
+// do, even when their HTTP status was 200. On error the zero R is returned.
+func call[R interface{ envelope() Response }](ctx context.Context, api *API, method, uri string, params interface{}) (R, error) {
+	var zero R
+
+	res, err := api.makeRequestContext(ctx, method, uri, params)
+	if err != nil {
+		return zero, fmt.Errorf("%s: %w", errMakeRequestError, err)
+	}
+
+	var r R
+	if err := json.Unmarshal(res, &r); err != nil {
+		return zero, fmt.Errorf("%s: %w", errUnmarshalError, err)
+	}
+	return r, nil
+}
+
 // RawResponse keeps the result as JSON form.
 type RawResponse struct {
 	Response
@@ -349,10 +471,23 @@ type RawResponse struct {
 }
 
 // Raw makes an HTTP request with user provided params and returns the
-// result as a RawResponse, which contains the untouched JSON result.
-func (api *API) Raw(ctx context.Context, method, endpoint string, data interface{}, headers http.Header) (RawResponse, error) {
+// result as a RawResponse, which contains the untouched JSON result. Query
+// parameters are passed with WithQueryParam; data is only sent as the body of
+// methods that accept one.
+func (api *API) Raw(ctx context.Context, method, endpoint string, data interface{}, headers http.Header, opts ...ReqOption) (RawResponse, error) {
 	var r RawResponse
-	res, err := api.makeRequestContextWithHeaders(ctx, method, endpoint, data, headers)
+	path, rawQuery, _ := strings.Cut(endpoint, "?")
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return r, fmt.Errorf("invalid query in endpoint %q: %w", endpoint, err)
+	}
+	for key, values := range query {
+		for _, value := range values {
+			opts = append(opts, WithQueryParam(key, value))
+		}
+	}
+	uri := buildURI(path, nil, opts...)
+	res, err := api.makeRequestContextWithHeaders(ctx, method, uri, data, headers)
 	if err != nil {
 		return r, err
 	}
@@ -365,6 +500,8 @@ func (api *API) Raw(ctx context.Context, method, endpoint string, data interface
 
 // RetryPolicy specifies number of retries and min/max retry delays
 // This config is used when the client exponentially backs off after errored requests.
+// Delays are fully jittered, and a longer Retry-After from the server is honored
+// up to MaxRetryDelay. Requests asked to wait longer fail instead.
 type RetryPolicy struct {
 	MaxRetries    int
 	MinRetryDelay time.Duration
@@ -381,6 +518,12 @@ type Logger interface {
 type ReqOption func(opt *reqOption)
 
 type reqOption struct {
-	//nolint:unused
 	params url.Values
 }
+
+// WithQueryParam adds a query string parameter to the request.
+func WithQueryParam(key, value string) ReqOption {
+	return func(opt *reqOption) {
+		opt.params.Add(key, value)
+	}
+}
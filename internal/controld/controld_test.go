@@ -0,0 +1,246 @@
+package controld
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/quick"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordedRequest is what the test server saw for a single request.
+type recordedRequest struct {
+	Method string
+	Path   string
+	Query  string
+	Body   string
+}
+
+// setup starts a test server that records each request and answers with body.
+func setup(t *testing.T, body string) (*API, *[]recordedRequest) {
+	t.Helper()
+
+	var requests []recordedRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		requests = append(requests, recordedRequest{
+			Method: r.Method,
+			Path:   r.URL.Path,
+			Query:  r.URL.RawQuery,
+			Body:   string(data),
+		})
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, body)
+	}))
+	t.Cleanup(server.Close)
+
+	api, err := New("test-token", BaseURL(server.URL+"/"), UsingRateLimit(1000))
+	require.NoError(t, err)
+	return api, &requests
+}
+
+func TestBuildURI(t *testing.T) {
+	type params struct {
+		ProfileID string `url:"-"`
+		DeviceID  string `url:"device_id"`
+		Limit     int    `url:"limit,omitempty"`
+	}
+
+	assert.Equal(t, "/profiles", buildURI("/profiles", nil))
+	assert.Equal(t, "/access?device_id=abc", buildURI("/access", params{ProfileID: "p1", DeviceID: "abc"}))
+	assert.Equal(t, "/access?device_id=abc&limit=5", buildURI("/access", params{DeviceID: "abc", Limit: 5}))
+	assert.Equal(t, "/access?device_id=abc&extra=1", buildURI("/access", nil,
+		WithQueryParam("device_id", "abc"), WithQueryParam("extra", "1")))
+}
+
+func TestGETRejectsBody(t *testing.T) {
+	api, requests := setup(t, `{"success":true}`)
+
+	_, err := api.makeRequestContext(context.Background(), http.MethodGet, "/profiles", map[string]string{"a": "b"})
+
+	assert.ErrorContains(t, err, "request body is not allowed")
+	assert.Empty(t, *requests)
+}
+
+func TestRawQueryParams(t *testing.T) {
+	api, requests := setup(t, `{"success":true,"body":{}}`)
+
+	_, err := api.Raw(context.Background(), http.MethodGet, "/access?device_id=abc", nil, nil, WithQueryParam("limit", "5"))
+
+	require.NoError(t, err)
+	require.Len(t, *requests, 1)
+	assert.Equal(t, "/access", (*requests)[0].Path)
+	assert.Equal(t, "device_id=abc&limit=5", (*requests)[0].Query)
+}
+
+func TestListEndpointsSendNoBody(t *testing.T) {
+	api, requests := setup(t, `{"success":true,"body":{}}`)
+	ctx := context.Background()
+
+	_, _ = api.ListProfileNativeFilters(ctx, ListProfileFiltersParams{ProfileID: "p1"})
+	_, _ = api.ListProfileExternalFilters(ctx, ListProfileFiltersParams{ProfileID: "p1"})
+	_, _ = api.ListProfileServices(ctx, ListProfileServicesParams{ProfileID: "p1"})
+	_, _ = api.ListProfileRuleFolders(ctx, ListProfileRuleFoldersParams{ProfileID: "p1"})
+	_, _ = api.ListProfileCustomRules(ctx, ListProfileCustomRulesParams{ProfileID: "p1", FolderID: "0"})
+	_, _ = api.ListProfileDefaultRule(ctx, ListProfileDefaultRuleParams{ProfileID: "p1"})
+	_, _ = api.ListServices(ctx, ListServicesParams{Category: "audio"})
+
+	want := []string{
+		"/profiles/p1/filters",
+		"/profiles/p1/filters/external",
+		"/profiles/p1/services",
+		"/profiles/p1/groups",
+		"/profiles/p1/rules/0",
+		"/profiles/p1/default",
+		"/services/categories/audio",
+	}
+	require.Len(t, *requests, len(want))
+	for i, req := range *requests {
+		assert.Equal(t, http.MethodGet, req.Method)
+		assert.Equal(t, want[i], req.Path)
+		assert.Empty(t, req.Query, req.Path)
+		assert.Empty(t, req.Body, req.Path)
+	}
+}
+
+func TestUnsuccessfulResponse(t *testing.T) {
+	api, _ := setup(t, `{"success":false,"error":{"message":"Profile limit reached","code":40001}}`)
+	ctx := context.Background()
+
+	profiles, err := api.CreateProfile(ctx, CreateProfileParams{Name: "Extra"})
+	assert.Nil(t, profiles)
+	require.ErrorContains(t, err, "Profile limit reached")
+	var reqErr *RequestError
+	require.ErrorAs(t, err, &reqErr)
+	assert.Equal(t, ErrorTypeRequest, reqErr.Type())
+
+	user, err := api.ListUser(ctx)
+	assert.Error(t, err)
+	assert.Equal(t, User{}, user)
+
+	rule, err := api.ListProfileDefaultRule(ctx, ListProfileDefaultRuleParams{ProfileID: "p1"})
+	assert.Error(t, err)
+	assert.Equal(t, DefaultRule{}, rule)
+}
+
+func TestUnsuccessfulResponseWithoutMessage(t *testing.T) {
+	api, _ := setup(t, `{"success":false}`)
+
+	profiles, err := api.ListProfiles(context.Background())
+	assert.Nil(t, profiles)
+	assert.ErrorContains(t, err, errUnsuccessful)
+}
+
+func TestUnsuccessfulResponseTypedErrors(t *testing.T) {
+	tests := []struct {
+		code  int
+		check func(error) bool
+		typ   ErrorType
+	}{
+		{404, IsNotFound, ErrorTypeNotFound},
+		{40401, IsNotFound, ErrorTypeNotFound},
+		{429, IsRateLimited, ErrorTypeRateLimit},
+		{401, IsAuth, ErrorTypeAuthorization},
+		{40301, IsAuth, ErrorTypeAuthentication},
+		{0, func(err error) bool { var e *RequestError; return errors.As(err, &e) }, ErrorTypeRequest},
+		{500, func(err error) bool { var e *ServiceError; return errors.As(err, &e) }, ErrorTypeService},
+	}
+
+	for _, tt := range tests {
+		api, _ := setup(t, fmt.Sprintf(`{"success":false,"error":{"message":"failed","code":%d}}`, tt.code))
+
+		_, err := api.ListDevices(context.Background())
+		require.Error(t, err, "code %d", tt.code)
+		assert.True(t, tt.check(err), "code %d: got %T", tt.code, errors.Unwrap(err))
+
+		var typed interface{ Type() ErrorType }
+		require.ErrorAs(t, err, &typed)
+		assert.Equal(t, tt.typ, typed.Type(), "code %d", tt.code)
+	}
+}
+
+func TestRawDetectsUnsuccessfulResponse(t *testing.T) {
+	api, _ := setup(t, `{"success":false,"error":{"message":"Device not found","code":404}}`)
+
+	_, err := api.Raw(context.Background(), http.MethodGet, "/devices/d1", nil, nil)
+	assert.True(t, IsNotFound(err), "got %v", err)
+	assert.False(t, IsAuth(err))
+	assert.False(t, IsRateLimited(err))
+
+	var nf *NotFoundError
+	require.ErrorAs(t, err, &nf)
+	assert.True(t, nf.InternalErrorCodeIs(404))
+}
+
+func TestErrorHelpersAcceptValues(t *testing.T) {
+	err := fmt.Errorf("wrapped: %w", NewRatelimitError(&Error{Type: ErrorTypeRateLimit}))
+	assert.True(t, IsRateLimited(err))
+	assert.True(t, IsAuth(NewAuthenticationError(&Error{})))
+	assert.False(t, IsNotFound(errors.New("other")))
+	assert.False(t, IsNotFound(nil))
+}
+
+func TestErrorsReturnNil(t *testing.T) {
+	api, _ := setup(t, `not json`)
+	ctx := context.Background()
+
+	devices, err := api.ListDevices(ctx)
+	assert.Error(t, err)
+	assert.Nil(t, devices)
+
+	rules, err := api.ListProfileCustomRules(ctx, ListProfileCustomRulesParams{})
+	assert.Error(t, err)
+	assert.Nil(t, rules)
+}
+
+func TestDateTimeRoundTrip(t *testing.T) {
+	property := func(seconds int64) bool {
+		want := DateTime{time.Unix(seconds%(1<<35)+1, 0).UTC()}
+
+		data, err := json.Marshal(want)
+		if err != nil || !json.Valid(data) {
+			return false
+		}
+		var got DateTime
+		return json.Unmarshal(data, &got) == nil && got.Equal(want.Time)
+	}
+	require.NoError(t, quick.Check(property, nil))
+}
+
+func TestDateTimeVariants(t *testing.T) {
+	want := time.Date(2024, 3, 5, 10, 30, 0, 0, time.UTC)
+	for _, in := range []string{
+		`"Tue, 05 Mar 2024 10:30:00 +0000"`,
+		`"Tue, 05 Mar 2024 10:30:00 UTC"`,
+		`"2024-03-05T10:30:00Z"`,
+		`"2024-03-05 10:30:00"`,
+		`1709634600`,
+	} {
+		var got DateTime
+		require.NoError(t, json.Unmarshal([]byte(in), &got), in)
+		assert.True(t, want.Equal(got.Time), "%s: got %v", in, got.Time)
+	}
+
+	for _, in := range []string{`null`, `""`} {
+		got := DateTime{want}
+		require.NoError(t, json.Unmarshal([]byte(in), &got), in)
+		assert.True(t, got.IsZero(), in)
+	}
+
+	for _, in := range []string{`"yesterday"`, `{}`, `"`} {
+		var got DateTime
+		assert.Error(t, json.Unmarshal([]byte(in), &got), in)
+	}
+
+	data, err := json.Marshal(ResponseInfo{Message: "m"})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"date":null,"message":"m","code":0}`, string(data))
+}
@@ -0,0 +1,123 @@
+package controldsim
+
+import (
+	"net"
+	"time"
+
+	"github.com/salmonumbrella/controld-cli/internal/controld"
+)
+
+// loadCatalog fills in the account-independent data the real API serves: the
+// current user, network status and the filter, service and option catalogs.
+func (s *Simulator) loadCatalog() {
+	now := s.now().UTC()
+
+	s.user = controld.User{
+		PK:             "user00001",
+		ResolverIP:     net.ParseIP("76.76.2.22"),
+		EmailStatus:    true,
+		Status:         true,
+		Email:          "user@example.com",
+		ResolverUid:    "resolver00001",
+		ResolverStatus: true,
+		Date:           controld.Date{Time: now.AddDate(-1, 0, 0).Truncate(24 * time.Hour)},
+		LastActive:     controld.UnixTime{Time: now.Truncate(time.Second)},
+	}
+
+	s.ip = controld.IP{
+		IP:      net.ParseIP("203.0.113.10"),
+		Type:    "v4",
+		Org:     "Example ISP",
+		Country: "CA",
+		Handler: "mock",
+		Pop:     "YYZ",
+	}
+
+	s.network = []controld.Network{
+		{IataCode: "YYZ", CityName: "Toronto", CountryName: "Canada", Location: controld.Location{Lat: 43.68, Long: -79.63}, Status: controld.Status{API: 1, DNS: 1, Pxy: 1}},
+		{IataCode: "JFK", CityName: "New York", CountryName: "United States", Location: controld.Location{Lat: 40.64, Long: -73.78}, Status: controld.Status{API: 1, DNS: 1, Pxy: 1}},
+		{IataCode: "FRA", CityName: "Frankfurt", CountryName: "Germany", Location: controld.Location{Lat: 50.04, Long: 8.56}, Status: controld.Status{API: 1, DNS: 1, Pxy: 0}},
+	}
+
+	s.options = []controld.ProfilesOption{
+		{PK: "ai_malware", Title: "AI Malware Filter", Description: "Block domains flagged by the AI model", Type: controld.Dropdown, DefaultValue: "0.9"},
+		{PK: "safesearch", Title: "Safe Search", Description: "Enforce safe search on search engines", Type: controld.Toggle, DefaultValue: 0},
+		{PK: "block_rfc1918", Title: "DNS Rebind Protection", Description: "Block private IPs in public DNS answers", Type: controld.Toggle, DefaultValue: 0},
+		{PK: "ttl_blck", Title: "Block TTL", Description: "TTL of blocked responses", Type: controld.Field, DefaultValue: 10},
+	}
+
+	s.filters = []controld.Filter{
+		{PK: "ads", Name: "Ads & Trackers", Description: "Advertising and tracking domains", Levels: []controld.FilterLevel{
+			{Title: "Relaxed", Type: "filter", Name: "ads_small"},
+			{Title: "Balanced", Type: "filter", Name: "ads_medium"},
+			{Title: "Strict", Type: "filter", Name: "ads"},
+		}},
+		{PK: "malware", Name: "Malware", Description: "Known malicious domains"},
+		{PK: "typo", Name: "Phishing", Description: "Typo-squatting and phishing domains"},
+		{PK: "social", Name: "Social", Description: "Social networks"},
+		{PK: "porn", Name: "Adult Content", Description: "Adult websites"},
+		{PK: "gambling", Name: "Gambling", Description: "Online gambling"},
+	}
+	s.external = []controld.Filter{
+		{PK: "x-1hosts-lite", Name: "1Hosts (Lite)", Description: "Third-party ad and tracker list"},
+		{PK: "x-oisd", Name: "OISD", Description: "Third-party blocklist"},
+	}
+
+	s.categories = []controld.Category{
+		{PK: "audio", Name: "Audio", Description: "Music and podcasts"},
+		{PK: "shop", Name: "Shop", Description: "Online shopping"},
+		{PK: "social", Name: "Social", Description: "Social networks"},
+		{PK: "video", Name: "Video", Description: "Video streaming"},
+	}
+	s.services = []controld.Service{
+		{PK: "spotify", Name: "Spotify", Category: "audio", UnlockLocation: "JFK"},
+		{PK: "soundcloud", Name: "SoundCloud", Category: "audio", UnlockLocation: "JFK"},
+		{PK: "facebook", Name: "Facebook", Category: "social", UnlockLocation: "JFK"},
+		{PK: "tiktok", Name: "TikTok", Category: "social", UnlockLocation: "JFK"},
+		{PK: "amazon", Name: "Amazon", Category: "shop", UnlockLocation: "JFK"},
+		{PK: "amazonprime", Name: "Amazon Prime Video", Category: "video", UnlockLocation: "JFK"},
+		{PK: "netflix", Name: "Netflix", Category: "video", UnlockLocation: "JFK"},
+		{PK: "youtube", Name: "YouTube", Category: "video", UnlockLocation: "JFK"},
+	}
+	for i, c := range s.categories {
+		for _, svc := range s.services {
+			if svc.Category == c.PK {
+				s.categories[i].Count++
+			}
+		}
+	}
+}
+
+// Seed adds a small sample account: two profiles with filters, services and
+// rules configured, and a few devices.
+func (s *Simulator) Seed() {
+	home := s.AddProfile("Home")
+	kids := s.AddProfile("Kids")
+
+	s.mu.Lock()
+	p := s.profile(home.PK)
+	p.filters["ads"] = true
+	p.filters["malware"] = true
+	p.services["netflix"] = controld.Action{Do: controld.Bypass, Status: true}
+	p.rules = append(p.rules, controld.Rule{PK: "example.com", Action: controld.Action{Do: controld.Block, Status: true}})
+
+	p = s.profile(kids.PK)
+	p.filters["ads"] = true
+	p.filters["porn"] = true
+	p.filters["gambling"] = true
+	p.services["tiktok"] = controld.Action{Do: controld.Block, Status: true}
+	s.mu.Unlock()
+
+	laptop := s.AddDevice("Laptop", home.PK)
+	s.AddDevice("Phone", home.PK)
+	s.AddDevice("Tablet", kids.PK)
+
+	s.AddKnownIP(laptop.DeviceID, controld.KnownIP{
+		IP:      net.ParseIP("203.0.113.10"),
+		Country: "CA",
+		City:    "Toronto",
+		ISP:     "Example ISP",
+		Asn:     64500,
+		AsName:  "EXAMPLE-AS",
+	})
+}
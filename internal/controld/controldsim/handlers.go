@@ -0,0 +1,710 @@
+package controldsim
+
+import (
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/salmonumbrella/controld-cli/internal/controld"
+)
+
+func (s *Simulator) routes() {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /users", s.getUser)
+	mux.HandleFunc("GET /ip", s.getIP)
+	mux.HandleFunc("GET /network", s.getNetwork)
+	mux.HandleFunc("GET /analytics/levels", s.listLogLevels)
+	mux.HandleFunc("GET /analytics/endpoints", s.listStorageRegions)
+
+	mux.HandleFunc("GET /profiles", s.listProfiles)
+	mux.HandleFunc("POST /profiles", s.createProfile)
+	mux.HandleFunc("PUT /profiles/{profile}", s.updateProfile)
+	mux.HandleFunc("DELETE /profiles/{profile}", s.deleteProfile)
+	mux.HandleFunc("GET /profiles/options", s.listOptions)
+	mux.HandleFunc("PUT /profiles/{profile}/options/{option}", s.updateOption)
+
+	mux.HandleFunc("GET /profiles/{profile}/filters", s.listFilters)
+	mux.HandleFunc("GET /profiles/{profile}/filters/external", s.listExternalFilters)
+	mux.HandleFunc("PUT /profiles/{profile}/filters/filter/{filter}", s.updateFilter)
+
+	mux.HandleFunc("GET /profiles/{profile}/services", s.listProfileServices)
+	mux.HandleFunc("PUT /profiles/{profile}/services/{service}", s.updateProfileService)
+	mux.HandleFunc("GET /services/categories", s.listCategories)
+	mux.HandleFunc("GET /services/categories/{category}", s.listServices)
+
+	mux.HandleFunc("GET /profiles/{profile}/groups", s.listFolders)
+	mux.HandleFunc("POST /profiles/{profile}/groups", s.createFolder)
+	mux.HandleFunc("PUT /profiles/{profile}/groups/{folder}", s.updateFolder)
+	mux.HandleFunc("DELETE /profiles/{profile}/groups/{folder}", s.deleteFolder)
+
+	mux.HandleFunc("GET /profiles/{profile}/rules/{folder}", s.listRules)
+	mux.HandleFunc("POST /profiles/{profile}/rules", s.createRules)
+	mux.HandleFunc("PUT /profiles/{profile}/rules", s.updateRules)
+	mux.HandleFunc("DELETE /profiles/{profile}/rules/{hostname}", s.deleteRule)
+
+	mux.HandleFunc("GET /profiles/{profile}/default", s.getDefaultRule)
+	mux.HandleFunc("PUT /profiles/{profile}/default", s.updateDefaultRule)
+
+	mux.HandleFunc("GET /devices", s.listDevices)
+	mux.HandleFunc("POST /devices", s.createDevice)
+	mux.HandleFunc("GET /devices/types", s.listDeviceTypes)
+	mux.HandleFunc("PUT /devices/{device}", s.updateDevice)
+	mux.HandleFunc("DELETE /devices/{device}", s.deleteDevice)
+
+	mux.HandleFunc("GET /access", s.listKnownIPs)
+	mux.HandleFunc("POST /access", s.learnIPs)
+	mux.HandleFunc("DELETE /access", s.deleteIPs)
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		s.writeError(w, http.StatusNotFound, "Endpoint not found: "+r.Method+" "+r.URL.Path)
+	})
+
+	s.mux = mux
+}
+
+// lookupProfile returns the profile named in the request path, writing a 404
+// response when it does not exist.
+func (s *Simulator) lookupProfile(w http.ResponseWriter, r *http.Request) *profile {
+	p := s.profile(r.PathValue("profile"))
+	if p == nil {
+		s.writeError(w, http.StatusNotFound, "Profile not found")
+	}
+	return p
+}
+
+func (s *Simulator) getUser(w http.ResponseWriter, r *http.Request) {
+	s.writeBody(w, s.user)
+}
+
+func (s *Simulator) getIP(w http.ResponseWriter, r *http.Request) {
+	s.writeBody(w, s.ip)
+}
+
+func (s *Simulator) getNetwork(w http.ResponseWriter, r *http.Request) {
+	s.writeBody(w, map[string]any{
+		"network":     s.network,
+		"time":        controld.UnixTime{Time: s.now().UTC()},
+		"current_pop": s.ip.Pop,
+	})
+}
+
+func (s *Simulator) listLogLevels(w http.ResponseWriter, r *http.Request) {
+	s.writeBody(w, map[string]any{"levels": []controld.LogLevel{
+		{PK: controld.Off, Title: "Off"},
+		{PK: controld.Basic, Title: "Basic"},
+		{PK: controld.Full, Title: "Full"},
+	}})
+}
+
+func (s *Simulator) listStorageRegions(w http.ResponseWriter, r *http.Request) {
+	s.writeBody(w, map[string]any{"endpoints": []controld.Endpoint{
+		{PK: "ca", Title: "Canada", CountryCode: "CA"},
+		{PK: "us", Title: "United States", CountryCode: "US"},
+		{PK: "eu", Title: "Europe", CountryCode: "DE"},
+	}})
+}
+
+func (s *Simulator) listProfiles(w http.ResponseWriter, r *http.Request) {
+	profiles := make([]controld.Profile, 0, len(s.profiles))
+	for _, p := range s.profiles {
+		profile := p.Profile
+		profile.Settings = &controld.ProfileSettings{Options: s.profileOptions(p)}
+		profiles = append(profiles, profile)
+	}
+	s.writeBody(w, map[string]any{"profiles": profiles})
+}
+
+func (s *Simulator) createProfile(w http.ResponseWriter, r *http.Request) {
+	var params controld.CreateProfileParams
+	if !s.decode(w, r, &params) {
+		return
+	}
+	if params.Name == "" {
+		s.writeError(w, http.StatusBadRequest, "Profile name is required")
+		return
+	}
+
+	var src *profile
+	if params.CloneProfileID != nil && *params.CloneProfileID != "" {
+		if src = s.profile(*params.CloneProfileID); src == nil {
+			s.writeError(w, http.StatusNotFound, "Profile to clone not found")
+			return
+		}
+	}
+
+	p := s.addProfile(params.Name)
+	if src != nil {
+		for k, v := range src.filters {
+			p.filters[k] = v
+		}
+		for k, v := range src.services {
+			p.services[k] = v
+		}
+		for k, v := range src.options {
+			p.options[k] = v
+		}
+		p.groups = slices.Clone(src.groups)
+		p.rules = slices.Clone(src.rules)
+		p.nextGroup = src.nextGroup
+		if src.def != nil {
+			def := *src.def
+			p.def = &def
+		}
+	}
+	s.writeBody(w, map[string]any{"profiles": []controld.Profile{p.Profile}})
+}
+
+func (s *Simulator) updateProfile(w http.ResponseWriter, r *http.Request) {
+	p := s.lookupProfile(w, r)
+	if p == nil {
+		return
+	}
+	var params controld.UpdateProfileParams
+	if !s.decode(w, r, &params) {
+		return
+	}
+	if params.Name != nil {
+		p.Name = *params.Name
+	}
+	s.touch(p)
+	s.writeBody(w, map[string]any{"profiles": []controld.Profile{p.Profile}})
+}
+
+func (s *Simulator) deleteProfile(w http.ResponseWriter, r *http.Request) {
+	p := s.lookupProfile(w, r)
+	if p == nil {
+		return
+	}
+	for _, d := range s.devices {
+		if d.Profile.PK == p.PK {
+			s.writeError(w, http.StatusBadRequest, "Profile is in use by device "+d.DeviceID)
+			return
+		}
+	}
+	s.profiles = slices.DeleteFunc(s.profiles, func(q *profile) bool { return q == p })
+	s.writeBody(w, []any{})
+}
+
+// profileOptions returns the options enabled on p, in catalog order.
+func (s *Simulator) profileOptions(p *profile) controld.ProfileOptions {
+	opts := controld.ProfileOptions{Data: []controld.Opt{}}
+	for _, o := range s.options {
+		set, ok := p.options[o.PK]
+		if !ok || !bool(set.Status) {
+			continue
+		}
+		opt := controld.Opt{PK: o.PK, Value: 1}
+		if set.Value != nil {
+			opt.Value = *set.Value
+		}
+		opts.Data = append(opts.Data, opt)
+	}
+	opts.Count = len(opts.Data)
+	return opts
+}
+
+func (s *Simulator) listOptions(w http.ResponseWriter, r *http.Request) {
+	s.writeBody(w, map[string]any{"options": s.options})
+}
+
+func (s *Simulator) updateOption(w http.ResponseWriter, r *http.Request) {
+	p := s.lookupProfile(w, r)
+	if p == nil {
+		return
+	}
+	name := r.PathValue("option")
+	if !slices.ContainsFunc(s.options, func(o controld.ProfilesOption) bool { return o.PK == name }) {
+		s.writeError(w, http.StatusNotFound, "Option not found")
+		return
+	}
+	var params controld.UpdateProfilesOption
+	if !s.decode(w, r, &params) {
+		return
+	}
+	params.ProfileID = p.PK
+	params.Name = name
+	p.options[name] = params
+	s.touch(p)
+	s.writeBody(w, map[string]any{"options": map[string]any{name: params.Status}})
+}
+
+func (s *Simulator) profileFilters(p *profile, catalog []controld.Filter) []controld.Filter {
+	out := make([]controld.Filter, 0, len(catalog))
+	for _, f := range catalog {
+		f.Status = controld.IntBool(p.filters[f.PK])
+		// A filter with levels is enabled by enabling one of them.
+		f.Levels = slices.Clone(f.Levels)
+		for i, l := range f.Levels {
+			f.Levels[i].Status = controld.IntBool(p.filters[l.Name])
+			f.Status = f.Status || f.Levels[i].Status
+		}
+		out = append(out, f)
+	}
+	return out
+}
+
+func (s *Simulator) listFilters(w http.ResponseWriter, r *http.Request) {
+	if p := s.lookupProfile(w, r); p != nil {
+		s.writeBody(w, map[string]any{"filters": s.profileFilters(p, s.filters)})
+	}
+}
+
+func (s *Simulator) listExternalFilters(w http.ResponseWriter, r *http.Request) {
+	if p := s.lookupProfile(w, r); p != nil {
+		s.writeBody(w, map[string]any{"filters": s.profileFilters(p, s.external)})
+	}
+}
+
+func (s *Simulator) updateFilter(w http.ResponseWriter, r *http.Request) {
+	p := s.lookupProfile(w, r)
+	if p == nil {
+		return
+	}
+	name := r.PathValue("filter")
+	known := func(f controld.Filter) bool {
+		return f.PK == name || slices.ContainsFunc(f.Levels, func(l controld.FilterLevel) bool { return l.Name == name })
+	}
+	i := slices.IndexFunc(s.filters, known)
+	if i < 0 && !slices.ContainsFunc(s.external, known) {
+		s.writeError(w, http.StatusNotFound, "Filter not found")
+		return
+	}
+	var params controld.UpdateProfileFilterParams
+	if !s.decode(w, r, &params) {
+		return
+	}
+	// Only one level of a filter is enabled at a time.
+	if i >= 0 {
+		for _, l := range s.filters[i].Levels {
+			delete(p.filters, l.Name)
+		}
+	}
+	p.filters[name] = bool(params.Status)
+	s.touch(p)
+	s.writeBody(w, map[string]any{"filters": map[string]any{name: params.Status}})
+}
+
+func (s *Simulator) listProfileServices(w http.ResponseWriter, r *http.Request) {
+	p := s.lookupProfile(w, r)
+	if p == nil {
+		return
+	}
+	services := []controld.ProfileService{}
+	for _, svc := range s.services {
+		action, ok := p.services[svc.PK]
+		if !ok {
+			continue
+		}
+		services = append(services, controld.ProfileService{
+			PK:             svc.PK,
+			Name:           svc.Name,
+			Category:       svc.Category,
+			UnlockLocation: svc.UnlockLocation,
+			Action:         action,
+		})
+	}
+	s.writeBody(w, map[string]any{"services": services})
+}
+
+func (s *Simulator) updateProfileService(w http.ResponseWriter, r *http.Request) {
+	p := s.lookupProfile(w, r)
+	if p == nil {
+		return
+	}
+	name := r.PathValue("service")
+	if !slices.ContainsFunc(s.services, func(svc controld.Service) bool { return svc.PK == name }) {
+		s.writeError(w, http.StatusNotFound, "Service not found")
+		return
+	}
+	var params controld.UpdateProfileServiceParams
+	if !s.decode(w, r, &params) {
+		return
+	}
+	action := controld.Action{Do: params.Do, Status: params.Status, Via: params.Via, ViaV6: params.ViaV6}
+	p.services[name] = action
+	s.touch(p)
+	s.writeBody(w, map[string]any{"services": []controld.Action{action}})
+}
+
+func (s *Simulator) listCategories(w http.ResponseWriter, r *http.Request) {
+	s.writeBody(w, map[string]any{"categories": s.categories})
+}
+
+func (s *Simulator) listServices(w http.ResponseWriter, r *http.Request) {
+	category := r.PathValue("category")
+	if !slices.ContainsFunc(s.categories, func(c controld.Category) bool { return c.PK == category }) {
+		s.writeError(w, http.StatusNotFound, "Category not found")
+		return
+	}
+	services := []controld.Service{}
+	for _, svc := range s.services {
+		if svc.Category == category {
+			services = append(services, svc)
+		}
+	}
+	s.writeBody(w, map[string]any{"services": services})
+}
+
+func (s *Simulator) folders(p *profile) []controld.Group {
+	groups := make([]controld.Group, 0, len(p.groups))
+	for _, g := range p.groups {
+		g.Count = 0
+		for _, rule := range p.rules {
+			if rule.Group == g.PK {
+				g.Count++
+			}
+		}
+		groups = append(groups, g)
+	}
+	return groups
+}
+
+// lookupFolder returns the index of the folder named in the request path,
+// writing a 404 response when it does not exist.
+func (s *Simulator) lookupFolder(w http.ResponseWriter, r *http.Request, p *profile) int {
+	id, err := strconv.Atoi(r.PathValue("folder"))
+	if err == nil {
+		for i, g := range p.groups {
+			if g.PK == id {
+				return i
+			}
+		}
+	}
+	s.writeError(w, http.StatusNotFound, "Folder not found")
+	return -1
+}
+
+func (s *Simulator) listFolders(w http.ResponseWriter, r *http.Request) {
+	if p := s.lookupProfile(w, r); p != nil {
+		s.writeBody(w, map[string]any{"groups": s.folders(p)})
+	}
+}
+
+func (s *Simulator) createFolder(w http.ResponseWriter, r *http.Request) {
+	p := s.lookupProfile(w, r)
+	if p == nil {
+		return
+	}
+	var params controld.CreateProfileRuleFolderParams
+	if !s.decode(w, r, &params) {
+		return
+	}
+	if params.Name == "" {
+		s.writeError(w, http.StatusBadRequest, "Folder name is required")
+		return
+	}
+	g := controld.Group{PK: p.nextGroup, Group: params.Name, Action: controld.GroupAction{Status: true, Do: params.Do}}
+	if params.Status != nil {
+		g.Action.Status = *params.Status
+	}
+	p.nextGroup++
+	p.groups = append(p.groups, g)
+	s.touch(p)
+	s.writeBody(w, map[string]any{"groups": []controld.Group{g}})
+}
+
+func (s *Simulator) updateFolder(w http.ResponseWriter, r *http.Request) {
+	p := s.lookupProfile(w, r)
+	if p == nil {
+		return
+	}
+	i := s.lookupFolder(w, r, p)
+	if i < 0 {
+		return
+	}
+	var params controld.UpdateProfileRuleFolderParams
+	if !s.decode(w, r, &params) {
+		return
+	}
+	if params.Do != nil {
+		p.groups[i].Action.Do = params.Do
+	}
+	if params.Status != nil {
+		p.groups[i].Action.Status = *params.Status
+	}
+	s.touch(p)
+	s.writeBody(w, map[string]any{"groups": []controld.Group{p.groups[i]}})
+}
+
+func (s *Simulator) deleteFolder(w http.ResponseWriter, r *http.Request) {
+	p := s.lookupProfile(w, r)
+	if p == nil {
+		return
+	}
+	i := s.lookupFolder(w, r, p)
+	if i < 0 {
+		return
+	}
+	id := p.groups[i].PK
+	p.groups = slices.Delete(p.groups, i, i+1)
+	p.rules = slices.DeleteFunc(p.rules, func(rule controld.Rule) bool { return rule.Group == id })
+	s.touch(p)
+	s.writeBody(w, []any{})
+}
+
+func (s *Simulator) listRules(w http.ResponseWriter, r *http.Request) {
+	p := s.lookupProfile(w, r)
+	if p == nil {
+		return
+	}
+	folder, err := strconv.Atoi(r.PathValue("folder"))
+	if err != nil {
+		s.writeError(w, http.StatusNotFound, "Folder not found")
+		return
+	}
+	rules := []controld.Rule{}
+	for _, rule := range p.rules {
+		if rule.Group == folder {
+			rules = append(rules, rule)
+		}
+	}
+	s.writeBody(w, map[string]any{"rules": rules})
+}
+
+func (s *Simulator) createRules(w http.ResponseWriter, r *http.Request) {
+	p := s.lookupProfile(w, r)
+	if p == nil {
+		return
+	}
+	var params controld.CreateProfileCustomRuleParams
+	if !s.decode(w, r, &params) {
+		return
+	}
+	if len(params.Hostnames) == 0 {
+		s.writeError(w, http.StatusBadRequest, "At least one hostname is required")
+		return
+	}
+	group := 0
+	if params.Group != nil {
+		group = *params.Group
+		if group != 0 && !slices.ContainsFunc(p.groups, func(g controld.Group) bool { return g.PK == group }) {
+			s.writeError(w, http.StatusNotFound, "Folder not found")
+			return
+		}
+	}
+	for _, host := range params.Hostnames {
+		if slices.ContainsFunc(p.rules, func(rule controld.Rule) bool { return rule.PK == host }) {
+			s.writeError(w, http.StatusBadRequest, "Rule already exists: "+host)
+			return
+		}
+	}
+
+	action := controld.Action{Do: params.Do, Status: params.Status, Via: params.Via, ViaV6: params.ViaV6}
+	created := make([]controld.CustomRule, 0, len(params.Hostnames))
+	for _, host := range params.Hostnames {
+		p.rules = append(p.rules, controld.Rule{PK: host, Order: len(p.rules) + 1, Group: group, Action: action})
+		created = append(created, controld.CustomRule(action))
+	}
+	s.touch(p)
+	s.writeBody(w, map[string]any{"rules": created})
+}
+
+func (s *Simulator) updateRules(w http.ResponseWriter, r *http.Request) {
+	p := s.lookupProfile(w, r)
+	if p == nil {
+		return
+	}
+	var params controld.UpdateProfileCustomRuleParams
+	if !s.decode(w, r, &params) {
+		return
+	}
+	var indexes []int
+	for _, host := range params.Hostnames {
+		i := slices.IndexFunc(p.rules, func(rule controld.Rule) bool { return rule.PK == host })
+		if i < 0 {
+			s.writeError(w, http.StatusNotFound, "Rule not found: "+host)
+			return
+		}
+		indexes = append(indexes, i)
+	}
+
+	action := controld.Action{Do: params.Do, Status: params.Status, Via: params.Via, ViaV6: params.ViaV6}
+	updated := make([]controld.CustomRule, 0, len(indexes))
+	for _, i := range indexes {
+		p.rules[i].Action = action
+		if params.Group != nil {
+			p.rules[i].Group = *params.Group
+		}
+		updated = append(updated, controld.CustomRule(action))
+	}
+	s.touch(p)
+	s.writeBody(w, map[string]any{"rules": updated})
+}
+
+func (s *Simulator) deleteRule(w http.ResponseWriter, r *http.Request) {
+	p := s.lookupProfile(w, r)
+	if p == nil {
+		return
+	}
+	host := r.PathValue("hostname")
+	i := slices.IndexFunc(p.rules, func(rule controld.Rule) bool { return rule.PK == host })
+	if i < 0 {
+		s.writeError(w, http.StatusNotFound, "Rule not found: "+host)
+		return
+	}
+	p.rules = slices.Delete(p.rules, i, i+1)
+	s.touch(p)
+	s.writeBody(w, []any{})
+}
+
+func (s *Simulator) getDefaultRule(w http.ResponseWriter, r *http.Request) {
+	p := s.lookupProfile(w, r)
+	if p == nil {
+		return
+	}
+	// Like the real API, a default rule that was never modified is returned
+	// as an empty array.
+	if p.def == nil {
+		s.writeBody(w, map[string]any{"default": []any{}})
+		return
+	}
+	s.writeBody(w, map[string]any{"default": p.def})
+}
+
+func (s *Simulator) updateDefaultRule(w http.ResponseWriter, r *http.Request) {
+	p := s.lookupProfile(w, r)
+	if p == nil {
+		return
+	}
+	var params controld.UpdateProfileDefaultRuleParams
+	if !s.decode(w, r, &params) {
+		return
+	}
+	p.def = &controld.DefaultRule{Do: params.Do, Status: params.Status, Via: params.Via}
+	s.touch(p)
+	s.writeBody(w, map[string]any{"default": p.def})
+}
+
+func (s *Simulator) listDevices(w http.ResponseWriter, r *http.Request) {
+	devices := make([]controld.Device, 0, len(s.devices))
+	for _, d := range s.devices {
+		devices = append(devices, *d)
+	}
+	s.writeBody(w, map[string]any{"devices": devices})
+}
+
+func (s *Simulator) createDevice(w http.ResponseWriter, r *http.Request) {
+	var params controld.CreateDeviceParams
+	if !s.decode(w, r, &params) {
+		return
+	}
+	if params.Name == "" {
+		s.writeError(w, http.StatusBadRequest, "Device name is required")
+		return
+	}
+	if s.profile(params.ProfileID) == nil {
+		s.writeError(w, http.StatusNotFound, "Profile not found")
+		return
+	}
+	s.writeBody(w, *s.addDevice(params))
+}
+
+func (s *Simulator) listDeviceTypes(w http.ResponseWriter, r *http.Request) {
+	s.writeBody(w, map[string]any{"types": controld.DeviceTypes{
+		OS:      controld.OS{Name: "Operating System"},
+		Browser: controld.Browser{Name: "Browser"},
+		TV:      controld.TV{Name: "TV"},
+		Router:  controld.Router{Name: "Router"},
+	}})
+}
+
+func (s *Simulator) updateDevice(w http.ResponseWriter, r *http.Request) {
+	d := s.device(r.PathValue("device"))
+	if d == nil {
+		s.writeError(w, http.StatusNotFound, "Device not found")
+		return
+	}
+	var params controld.UpdateDeviceParams
+	if !s.decode(w, r, &params) {
+		return
+	}
+	if params.ProfileID != nil {
+		p := s.profile(*params.ProfileID)
+		if p == nil {
+			s.writeError(w, http.StatusNotFound, "Profile not found")
+			return
+		}
+		d.Profile = p.Profile
+	}
+	if params.Name != nil {
+		d.Name = *params.Name
+	}
+	if params.Desc != nil {
+		d.Desc = *params.Desc
+	}
+	if params.Status != nil {
+		d.Status = *params.Status
+	}
+	if params.LearnIP != nil {
+		d.LearnIP = *params.LearnIP
+	}
+	if params.Stats != nil {
+		d.Stats = params.Stats
+	}
+	if params.Restricted != nil {
+		d.Restricted = params.Restricted
+	}
+	s.writeBody(w, *d)
+}
+
+func (s *Simulator) deleteDevice(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("device")
+	if s.device(id) == nil {
+		s.writeError(w, http.StatusNotFound, "Device not found")
+		return
+	}
+	s.devices = slices.DeleteFunc(s.devices, func(d *controld.Device) bool { return d.DeviceID == id })
+	delete(s.knownIPs, id)
+	s.writeBody(w, []any{})
+}
+
+func (s *Simulator) listKnownIPs(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("device_id")
+	if s.device(id) == nil {
+		s.writeError(w, http.StatusNotFound, "Device not found")
+		return
+	}
+	ips := s.knownIPs[id]
+	if ips == nil {
+		ips = []controld.KnownIP{}
+	}
+	s.writeBody(w, map[string]any{"ips": ips})
+}
+
+func (s *Simulator) learnIPs(w http.ResponseWriter, r *http.Request) {
+	var params controld.LearnNewIPsParams
+	if !s.decode(w, r, &params) {
+		return
+	}
+	if s.device(params.DeviceID) == nil {
+		s.writeError(w, http.StatusNotFound, "Device not found")
+		return
+	}
+	for _, ip := range params.IPs {
+		if slices.ContainsFunc(s.knownIPs[params.DeviceID], func(k controld.KnownIP) bool { return k.IP.Equal(ip) }) {
+			continue
+		}
+		s.knownIPs[params.DeviceID] = append(s.knownIPs[params.DeviceID], controld.KnownIP{
+			IP: ip,
+			Ts: controld.UnixTime{Time: s.now().UTC()},
+		})
+	}
+	s.writeBody(w, []any{})
+}
+
+func (s *Simulator) deleteIPs(w http.ResponseWriter, r *http.Request) {
+	var params controld.DeleteLearnedIPsParams
+	if !s.decode(w, r, &params) {
+		return
+	}
+	if s.device(params.DeviceID) == nil {
+		s.writeError(w, http.StatusNotFound, "Device not found")
+		return
+	}
+	s.knownIPs[params.DeviceID] = slices.DeleteFunc(s.knownIPs[params.DeviceID], func(k controld.KnownIP) bool {
+		return slices.ContainsFunc(params.IPs, func(ip net.IP) bool { return k.IP.Equal(ip) })
+	})
+	s.writeBody(w, []any{})
+}
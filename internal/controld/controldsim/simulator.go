@@ -0,0 +1,237 @@
+// Package controldsim provides an in-memory implementation of the ControlD
+// API. It backs 'controld dev mock-server' for offline development, and the
+// test servers of package controldtest.
+package controldsim
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/salmonumbrella/controld-cli/internal/controld"
+)
+
+// Simulator is an in-memory ControlD API. It implements http.Handler and is
+// safe for concurrent use.
+type Simulator struct {
+	mu  sync.Mutex
+	mux *http.ServeMux
+	now func() time.Time
+	seq int
+
+	token string
+
+	user       controld.User
+	ip         controld.IP
+	network    []controld.Network
+	options    []controld.ProfilesOption
+	filters    []controld.Filter
+	external   []controld.Filter
+	categories []controld.Category
+	services   []controld.Service
+
+	profiles []*profile
+	devices  []*controld.Device
+	knownIPs map[string][]controld.KnownIP
+
+	faults   []*Fault
+	requests []Request
+}
+
+// profile holds a profile and everything configured on it.
+type profile struct {
+	controld.Profile
+	filters   map[string]bool
+	services  map[string]controld.Action
+	groups    []controld.Group
+	rules     []controld.Rule
+	def       *controld.DefaultRule
+	options   map[string]controld.UpdateProfilesOption
+	nextGroup int
+}
+
+// Request is a request received by the simulator.
+type Request struct {
+	Method string
+	Path   string
+	Query  string
+	Body   []byte
+}
+
+// Fault makes matching requests fail with the given status code.
+type Fault struct {
+	// Method matches the request method. Empty matches every method.
+	Method string
+	// Path is a path.Match pattern for the request path, e.g. "/profiles/*".
+	// Empty matches every path.
+	Path string
+	// Status is the HTTP status code returned.
+	Status int
+	// Message is the error message returned. Defaults to the status text.
+	Message string
+	// Times is the number of requests to fail. Zero fails every matching
+	// request until the fault is cleared.
+	Times int
+}
+
+// Option configures a Simulator.
+type Option func(*Simulator)
+
+// WithToken makes the simulator reject requests that do not carry token as
+// their bearer token.
+func WithToken(token string) Option {
+	return func(s *Simulator) {
+		s.token = token
+	}
+}
+
+// WithClock sets the function used to timestamp created resources.
+func WithClock(now func() time.Time) Option {
+	return func(s *Simulator) {
+		s.now = now
+	}
+}
+
+// New returns a simulator with the service, filter and option catalogs
+// populated but no profiles or devices.
+func New(opts ...Option) *Simulator {
+	s := &Simulator{
+		now:      time.Now,
+		knownIPs: make(map[string][]controld.KnownIP),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.loadCatalog()
+	s.routes()
+	return s
+}
+
+// Token returns the API token the simulator requires, or "" when it accepts
+// any token.
+func (s *Simulator) Token() string {
+	return s.token
+}
+
+// Inject adds a fault. Faults are checked in the order they were added.
+func (s *Simulator) Inject(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &f)
+}
+
+// ClearFaults removes every injected fault.
+func (s *Simulator) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = nil
+}
+
+// Requests returns the requests received so far.
+func (s *Simulator) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.requests)
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Simulator) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	body, _ := io.ReadAll(r.Body)
+	s.requests = append(s.requests, Request{
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Query:  r.URL.RawQuery,
+		Body:   body,
+	})
+
+	if f := s.matchFault(r); f != nil {
+		msg := f.Message
+		if msg == "" {
+			msg = http.StatusText(f.Status)
+		}
+		if f.Status == http.StatusTooManyRequests {
+			w.Header().Set("Retry-After", "0")
+		}
+		s.writeError(w, f.Status, msg)
+		return
+	}
+
+	if s.token != "" && r.Header.Get("Authorization") != "Bearer "+s.token {
+		s.writeError(w, http.StatusUnauthorized, "Invalid API token")
+		return
+	}
+
+	r.Body = io.NopCloser(strings.NewReader(string(body)))
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Simulator) matchFault(r *http.Request) *Fault {
+	for i, f := range s.faults {
+		if f.Method != "" && !strings.EqualFold(f.Method, r.Method) {
+			continue
+		}
+		if f.Path != "" {
+			if ok, _ := path.Match(f.Path, r.URL.Path); !ok {
+				continue
+			}
+		}
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.faults = slices.Delete(s.faults, i, i+1)
+			}
+		}
+		return f
+	}
+	return nil
+}
+
+func (s *Simulator) nextID(prefix string) string {
+	s.seq++
+	return fmt.Sprintf("%s%05d", prefix, s.seq)
+}
+
+// writeBody writes a successful API envelope around body.
+func (s *Simulator) writeBody(w http.ResponseWriter, body any) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"success": true,
+		"body":    body,
+	})
+}
+
+// writeError writes a failed API envelope.
+func (s *Simulator) writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]any{
+		"success": false,
+		"error": map[string]any{
+			"date":    s.now().UTC().Format(time.RFC1123Z),
+			"message": message,
+			"code":    status,
+		},
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// decode reads the JSON request body into v, writing a 400 response when it
+// is malformed.
+func (s *Simulator) decode(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil && err != io.EOF {
+		s.writeError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return false
+	}
+	return true
+}
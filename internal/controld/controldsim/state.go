@@ -0,0 +1,156 @@
+package controldsim
+
+import (
+	"fmt"
+	"net"
+	"slices"
+
+	"github.com/salmonumbrella/controld-cli/internal/controld"
+)
+
+// AddProfile creates a profile named name.
+func (s *Simulator) AddProfile(name string) controld.Profile {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addProfile(name).Profile
+}
+
+// AddDevice creates an active device using the given profile.
+func (s *Simulator) AddDevice(name, profileID string) controld.Device {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d := s.addDevice(controld.CreateDeviceParams{Name: name, ProfileID: profileID})
+	d.Status = controld.Active
+	return *d
+}
+
+// AddKnownIP records ip as learned by the device. A zero Ts is set to now.
+func (s *Simulator) AddKnownIP(deviceID string, ip controld.KnownIP) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if ip.Ts.IsZero() {
+		ip.Ts = controld.UnixTime{Time: s.now().UTC()}
+	}
+	s.knownIPs[deviceID] = append(s.knownIPs[deviceID], ip)
+}
+
+// Profiles returns the current profiles.
+func (s *Simulator) Profiles() []controld.Profile {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]controld.Profile, 0, len(s.profiles))
+	for _, p := range s.profiles {
+		out = append(out, p.Profile)
+	}
+	return out
+}
+
+// Devices returns the current devices.
+func (s *Simulator) Devices() []controld.Device {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]controld.Device, 0, len(s.devices))
+	for _, d := range s.devices {
+		out = append(out, *d)
+	}
+	return out
+}
+
+// Device returns the device with the given ID.
+func (s *Simulator) Device(deviceID string) (controld.Device, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d := s.device(deviceID)
+	if d == nil {
+		return controld.Device{}, false
+	}
+	return *d, true
+}
+
+// KnownIPs returns the IPs learned by the device.
+func (s *Simulator) KnownIPs(deviceID string) []controld.KnownIP {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.knownIPs[deviceID])
+}
+
+func (s *Simulator) addProfile(name string) *profile {
+	p := &profile{
+		Profile: controld.Profile{
+			PK:      s.nextID("prof"),
+			Name:    name,
+			Updated: controld.UnixTime{Time: s.now().UTC()},
+		},
+		filters:   make(map[string]bool),
+		services:  make(map[string]controld.Action),
+		options:   make(map[string]controld.UpdateProfilesOption),
+		nextGroup: 1,
+	}
+	s.profiles = append(s.profiles, p)
+	return p
+}
+
+func (s *Simulator) profile(id string) *profile {
+	for _, p := range s.profiles {
+		if p.PK == id {
+			return p
+		}
+	}
+	return nil
+}
+
+func (s *Simulator) addDevice(params controld.CreateDeviceParams) *controld.Device {
+	id := s.nextID("dev")
+	d := &controld.Device{
+		PK:       id,
+		DeviceID: id,
+		Ts:       controld.UnixTime{Time: s.now().UTC()},
+		Name:     params.Name,
+		User:     s.user.PK,
+		Status:   controld.Pending,
+		Resolvers: controld.Resolvers{
+			Uid: id,
+			DoH: fmt.Sprintf("https://dns.controld.com/%s", id),
+			DoT: fmt.Sprintf("%s.dns.controld.com", id),
+			V4:  &[]net.IP{net.ParseIP("76.76.2.22"), net.ParseIP("76.76.10.22")},
+		},
+		LegacyIPv4: controld.LegacyIPv4{Resolver: "76.76.2.22"},
+	}
+	if p := s.profile(params.ProfileID); p != nil {
+		d.Profile = p.Profile
+	}
+	if params.Icon != "" {
+		icon := params.Icon
+		d.Icon = &icon
+	}
+	if params.Desc != nil {
+		d.Desc = *params.Desc
+	}
+	if params.LearnIP != nil {
+		d.LearnIP = *params.LearnIP
+	}
+	d.Stats = params.Stats
+	d.Restricted = params.Restricted
+	s.devices = append(s.devices, d)
+	return d
+}
+
+func (s *Simulator) device(id string) *controld.Device {
+	for _, d := range s.devices {
+		if d.DeviceID == id {
+			return d
+		}
+	}
+	return nil
+}
+
+// touch updates the profile's modification time and the copy embedded in
+// every device using it.
+func (s *Simulator) touch(p *profile) {
+	p.Updated = controld.UnixTime{Time: s.now().UTC()}
+	for _, d := range s.devices {
+		if d.Profile.PK == p.PK {
+			d.Profile = p.Profile
+		}
+	}
+}
@@ -0,0 +1,48 @@
+// Package controldtest runs the in-memory ControlD API of package controldsim
+// on local test servers, so tests can exercise the client and the CLI without
+// network access.
+package controldtest
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/salmonumbrella/controld-cli/internal/controld"
+	"github.com/salmonumbrella/controld-cli/internal/controld/controldsim"
+)
+
+// Server is a Simulator listening on a local test server.
+type Server struct {
+	*controldsim.Simulator
+	*httptest.Server
+}
+
+// NewServer starts a simulator on a local test server that is closed when the
+// test finishes.
+func NewServer(tb testing.TB, opts ...controldsim.Option) *Server {
+	tb.Helper()
+	sim := controldsim.New(opts...)
+	srv := httptest.NewServer(sim)
+	tb.Cleanup(srv.Close)
+	return &Server{Simulator: sim, Server: srv}
+}
+
+// NewClient returns an API client for the server. Rate limiting and retry
+// delays are disabled so tests run quickly.
+func (s *Server) NewClient(tb testing.TB, opts ...controld.Option) *controld.API {
+	tb.Helper()
+	token := s.Token()
+	if token == "" {
+		token = "test-token"
+	}
+	opts = append([]controld.Option{
+		controld.BaseURL(s.URL),
+		controld.UsingRateLimit(1000),
+		controld.UsingRetryPolicy(1, 0, 0),
+	}, opts...)
+	api, err := controld.New(token, opts...)
+	if err != nil {
+		tb.Fatalf("controldtest: %v", err)
+	}
+	return api
+}
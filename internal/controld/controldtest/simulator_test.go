@@ -0,0 +1,212 @@
+package controldtest
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/salmonumbrella/controld-cli/internal/controld"
+	"github.com/salmonumbrella/controld-cli/internal/controld/controldsim"
+)
+
+func TestProfilesAndDevices(t *testing.T) {
+	srv := NewServer(t)
+	client := srv.NewClient(t)
+	ctx := context.Background()
+
+	profiles, err := client.CreateProfile(ctx, controld.CreateProfileParams{Name: "Home"})
+	require.NoError(t, err)
+	require.Len(t, profiles, 1)
+	profileID := profiles[0].PK
+
+	device, err := client.CreateDevice(ctx, controld.CreateDeviceParams{Name: "Laptop", ProfileID: profileID})
+	require.NoError(t, err)
+	assert.Equal(t, "Home", device.Profile.Name)
+	assert.Equal(t, controld.DeviceStatus(controld.Pending), device.Status)
+
+	status := controld.DeviceStatus(controld.Active)
+	name := "Work Laptop"
+	device, err = client.UpdateDevice(ctx, controld.UpdateDeviceParams{DeviceID: device.DeviceID, Name: &name, Status: &status})
+	require.NoError(t, err)
+	assert.Equal(t, "Work Laptop", device.Name)
+
+	devices, err := client.ListDevices(ctx)
+	require.NoError(t, err)
+	require.Len(t, devices, 1)
+	assert.Equal(t, controld.DeviceStatus(controld.Active), devices[0].Status)
+
+	_, err = client.DeleteDevice(ctx, controld.DeleteDeviceParams{DeviceID: device.DeviceID})
+	require.NoError(t, err)
+	assert.Empty(t, srv.Devices())
+}
+
+func TestProfileConfiguration(t *testing.T) {
+	srv := NewServer(t)
+	client := srv.NewClient(t)
+	ctx := context.Background()
+	profileID := srv.AddProfile("Home").PK
+
+	_, err := client.UpdateProfileFilter(ctx, controld.UpdateProfileFilterParams{ProfileID: profileID, Filter: "ads", Status: true})
+	require.NoError(t, err)
+	filters, err := client.ListProfileNativeFilters(ctx, controld.ListProfileFiltersParams{ProfileID: profileID})
+	require.NoError(t, err)
+	for _, f := range filters {
+		assert.Equal(t, f.PK == "ads", bool(f.Status), f.PK)
+	}
+
+	_, err = client.UpdateProfileFilter(ctx, controld.UpdateProfileFilterParams{ProfileID: profileID, Filter: "ads_medium", Status: true})
+	require.NoError(t, err)
+	filters, err = client.ListProfileNativeFilters(ctx, controld.ListProfileFiltersParams{ProfileID: profileID})
+	require.NoError(t, err)
+	require.Equal(t, "ads", filters[0].PK)
+	assert.True(t, bool(filters[0].Status))
+	for _, l := range filters[0].Levels {
+		assert.Equal(t, l.Name == "ads_medium", bool(l.Status), l.Name)
+	}
+
+	_, err = client.UpdateProfileService(ctx, controld.UpdateProfileServiceParams{ProfileID: profileID, Service: "netflix", Do: controld.Bypass, Status: true})
+	require.NoError(t, err)
+	services, err := client.ListProfileServices(ctx, controld.ListProfileServicesParams{ProfileID: profileID})
+	require.NoError(t, err)
+	require.Len(t, services, 1)
+	assert.Equal(t, "Netflix", services[0].Name)
+
+	groups, err := client.CreateProfileRuleFolder(ctx, controld.CreateProfileRuleFolderParams{ProfileID: profileID, Name: "Work"})
+	require.NoError(t, err)
+	folder := groups[0].PK
+	_, err = client.CreateProfileCustomRule(ctx, controld.CreateProfileCustomRuleParams{
+		ProfileID: profileID, Do: controld.Block, Status: true, Group: &folder, Hostnames: []string{"a.example", "b.example"},
+	})
+	require.NoError(t, err)
+	rules, err := client.ListProfileCustomRules(ctx, controld.ListProfileCustomRulesParams{ProfileID: profileID, FolderID: "1"})
+	require.NoError(t, err)
+	assert.Len(t, rules, 2)
+
+	def, err := client.ListProfileDefaultRule(ctx, controld.ListProfileDefaultRuleParams{ProfileID: profileID})
+	require.NoError(t, err)
+	assert.Equal(t, controld.DoType(controld.Bypass), def.Do)
+	_, err = client.UpdateProfileDefaultRule(ctx, controld.UpdateProfileDefaultRuleParams{ProfileID: profileID, Do: controld.Block, Status: true})
+	require.NoError(t, err)
+	def, err = client.ListProfileDefaultRule(ctx, controld.ListProfileDefaultRuleParams{ProfileID: profileID})
+	require.NoError(t, err)
+	assert.Equal(t, controld.DoType(controld.Block), def.Do)
+
+	ttl := "30"
+	_, err = client.UpdateProfilesOption(ctx, controld.UpdateProfilesOption{ProfileID: profileID, Name: "ttl_blck", Status: true, Value: &ttl})
+	require.NoError(t, err)
+	profiles, err := client.ListProfiles(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, profiles[0].Settings)
+	assert.Equal(t, []controld.Opt{{PK: "ttl_blck", Value: "30"}}, profiles[0].Settings.Options.Data)
+}
+
+func TestAccess(t *testing.T) {
+	srv := NewServer(t)
+	client := srv.NewClient(t)
+	ctx := context.Background()
+	device := srv.AddDevice("Laptop", srv.AddProfile("Home").PK)
+
+	_, err := client.LearnNewIPs(ctx, controld.LearnNewIPsParams{DeviceID: device.DeviceID, IPs: []net.IP{net.ParseIP("1.2.3.4"), net.ParseIP("5.6.7.8")}})
+	require.NoError(t, err)
+	_, err = client.DeleteLearnedIPs(ctx, controld.DeleteLearnedIPsParams{DeviceID: device.DeviceID, IPs: []net.IP{net.ParseIP("1.2.3.4")}})
+	require.NoError(t, err)
+
+	ips, err := client.ListKnownIPs(ctx, controld.ListKnownIPsParams{DeviceID: device.DeviceID})
+	require.NoError(t, err)
+	require.Len(t, ips, 1)
+	assert.Equal(t, "5.6.7.8", ips[0].IP.String())
+}
+
+func TestSeed(t *testing.T) {
+	srv := NewServer(t)
+	srv.Seed()
+	client := srv.NewClient(t)
+	ctx := context.Background()
+
+	profiles, err := client.ListProfiles(ctx)
+	require.NoError(t, err)
+	assert.Len(t, profiles, 2)
+
+	user, err := client.ListUser(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "user@example.com", user.Email)
+
+	_, err = client.ListNetwork(ctx)
+	require.NoError(t, err)
+	_, err = client.ListIP(ctx)
+	require.NoError(t, err)
+	_, err = client.ListProfilesOptions(ctx)
+	require.NoError(t, err)
+}
+
+func TestNotFound(t *testing.T) {
+	srv := NewServer(t)
+	client := srv.NewClient(t)
+
+	_, err := client.ListProfileServices(context.Background(), controld.ListProfileServicesParams{ProfileID: "missing"})
+
+	var notFound *controld.NotFoundError
+	assert.True(t, errors.As(err, &notFound), "got %v", err)
+}
+
+func TestToken(t *testing.T) {
+	srv := NewServer(t, controldsim.WithToken("secret"))
+
+	_, err := srv.NewClient(t).ListProfiles(context.Background())
+	require.NoError(t, err)
+
+	bad, err := controld.New("wrong", controld.BaseURL(srv.URL))
+	require.NoError(t, err)
+	_, err = bad.ListProfiles(context.Background())
+	var authErr *controld.AuthorizationError
+	assert.True(t, errors.As(err, &authErr), "got %v", err)
+}
+
+func TestFaults(t *testing.T) {
+	tests := []struct {
+		status int
+		target any
+	}{
+		{http.StatusUnauthorized, new(*controld.AuthorizationError)},
+		{http.StatusForbidden, new(*controld.AuthenticationError)},
+		{http.StatusNotFound, new(*controld.NotFoundError)},
+		{http.StatusTooManyRequests, nil},
+		{http.StatusServiceUnavailable, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			srv := NewServer(t)
+			client := srv.NewClient(t)
+			srv.Inject(controldsim.Fault{Method: http.MethodGet, Path: "/profiles", Status: tt.status})
+
+			_, err := client.ListProfiles(context.Background())
+
+			require.Error(t, err)
+			if tt.target != nil {
+				assert.True(t, errors.As(err, tt.target), "got %v", err)
+			}
+
+			// Other endpoints are unaffected.
+			_, err = client.ListDevices(context.Background())
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestFaultTimes(t *testing.T) {
+	srv := NewServer(t)
+	client := srv.NewClient(t)
+	srv.Inject(controldsim.Fault{Path: "/profiles", Status: http.StatusServiceUnavailable, Times: 1})
+
+	// The client retries once and the second attempt succeeds.
+	_, err := client.ListProfiles(context.Background())
+
+	require.NoError(t, err)
+	assert.Len(t, srv.Requests(), 2)
+}
@@ -4,30 +4,62 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
 	"net"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 )
 
+// UnixTime is a timestamp the API sends as seconds since the Unix epoch. It
+// accepts integers, floats and numeric strings; 0, null and "" decode to the
+// zero time, which encodes as 0.
 type UnixTime struct {
 	time.Time
 }
 
 func (s UnixTime) MarshalJSON() ([]byte, error) {
+	if s.IsZero() {
+		return []byte("0"), nil
+	}
 	return json.Marshal(s.Unix())
 }
 
 func (s *UnixTime) UnmarshalJSON(data []byte) error {
-	seconds, err := strconv.ParseInt(string(data), 10, 64)
+	value, ok, err := jsonScalar(data)
 	if err != nil {
-		return err
+		return fmt.Errorf("unix time: %w", err)
+	}
+	if !ok || value == "" {
+		s.Time = time.Time{}
+		return nil
 	}
 
-	s.Time = time.Unix(seconds, 0).UTC()
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if seconds == 0 {
+			s.Time = time.Time{}
+		} else {
+			s.Time = time.Unix(seconds, 0).UTC()
+		}
+		return nil
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return fmt.Errorf("unix time: invalid value %s", data)
+	}
+	if f == 0 {
+		s.Time = time.Time{}
+		return nil
+	}
+	sec, frac := math.Modf(f)
+	s.Time = time.Unix(int64(sec), int64(math.Round(frac*1e9))).UTC()
 	return nil
 }
 
+// IntBool is a boolean the API sends as 0 or 1. It also accepts JSON
+// booleans and the strings "0", "1", "true" and "false"; null decodes to
+// false. It always encodes as 0 or 1.
 type IntBool bool
 
 func (s IntBool) MarshalJSON() ([]byte, error) {
@@ -39,11 +71,27 @@ func (s IntBool) MarshalJSON() ([]byte, error) {
 }
 
 func (s *IntBool) UnmarshalJSON(data []byte) error {
-	value, err := strconv.ParseInt(string(data), 10, 64)
+	value, ok, err := jsonScalar(data)
 	if err != nil {
-		return err
+		return fmt.Errorf("int bool: %w", err)
+	}
+	if !ok {
+		*s = false
+		return nil
+	}
+
+	switch strings.ToLower(value) {
+	case "true":
+		*s = true
+	case "false", "":
+		*s = false
+	default:
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("int bool: invalid value %s", data)
+		}
+		*s = IntBool(n == 1)
 	}
-	*s = IntBool(value == 1)
 	return nil
 }
 
@@ -282,16 +330,9 @@ type DeleteDeviceResponse struct {
 func (api *API) ListDevices(ctx context.Context) ([]Device, error) {
 	uri := buildURI("/devices", nil)
 
-	res, err := api.makeRequestContext(ctx, http.MethodGet, uri, nil)
-	if err != nil {
-		return []Device{}, fmt.Errorf("%s: %w", errMakeRequestError, err)
-	}
-
-	var r ListDevicesResponse
-
-	err = json.Unmarshal(res, &r)
+	r, err := call[ListDevicesResponse](ctx, api, http.MethodGet, uri, nil)
 	if err != nil {
-		return []Device{}, fmt.Errorf("%s: %w", errUnmarshalError, err)
+		return nil, err
 	}
 	return r.Body.Devices, nil
 }
@@ -299,30 +340,18 @@ func (api *API) ListDevices(ctx context.Context) ([]Device, error) {
 func (api *API) CreateDevice(ctx context.Context, params CreateDeviceParams) (Device, error) {
 	uri := buildURI("/devices", nil)
 
-	res, err := api.makeRequestContext(ctx, http.MethodPost, uri, params)
+	r, err := call[CreateDeviceResponse](ctx, api, http.MethodPost, uri, params)
 	if err != nil {
-		return Device{}, fmt.Errorf("%s: %w", errMakeRequestError, err)
-	}
-
-	var r CreateDeviceResponse
-
-	err = json.Unmarshal(res, &r)
-	if err != nil {
-		return Device{}, fmt.Errorf("%s: %w", errUnmarshalError, err)
+		return Device{}, err
 	}
 	return r.Body, nil
 }
 
 func (api *API) ListDeviceType(ctx context.Context) (DeviceTypes, error) {
 	uri := buildURI("/devices/types", nil)
-	res, err := api.makeRequestContext(ctx, http.MethodGet, uri, nil)
-	if err != nil {
-		return DeviceTypes{}, fmt.Errorf("%s: %w", errMakeRequestError, err)
-	}
-	var r ListDeviceTypesResponse
-	err = json.Unmarshal(res, &r)
+	r, err := call[ListDeviceTypesResponse](ctx, api, http.MethodGet, uri, nil)
 	if err != nil {
-		return DeviceTypes{}, fmt.Errorf("%s: %w", errUnmarshalError, err)
+		return DeviceTypes{}, err
 	}
 	return r.Body.Types, nil
 }
@@ -333,36 +362,24 @@ func (api *API) UpdateDevice(ctx context.Context, params UpdateDeviceParams) (De
 	}
 	baseURL := fmt.Sprintf("/devices/%s", params.DeviceID)
 	uri := buildURI(baseURL, nil)
-	res, err := api.makeRequestContext(ctx, http.MethodPut, uri, params)
+	r, err := call[UpdateDeviceResponse](ctx, api, http.MethodPut, uri, params)
 	if err != nil {
-		return Device{}, fmt.Errorf("%s: %w", errMakeRequestError, err)
-	}
-	var r UpdateDeviceResponse
-	err = json.Unmarshal(res, &r)
-	if err != nil {
-		return Device{}, fmt.Errorf("%s: %w", errUnmarshalError, err)
+		return Device{}, err
 	}
 	return r.Body, nil
 }
 
 func (api *API) DeleteDevice(ctx context.Context, params DeleteDeviceParams) ([]any, error) {
 	if params.DeviceID == "" {
-		return []any{}, fmt.Errorf("delete: no device ID provided")
+		return nil, fmt.Errorf("delete: no device ID provided")
 	}
 
 	baseURL := fmt.Sprintf("/devices/%s", params.DeviceID)
 	uri := buildURI(baseURL, nil)
 
-	res, err := api.makeRequestContext(ctx, http.MethodDelete, uri, params)
-	if err != nil {
-		return []any{}, fmt.Errorf("%s: %w", errMakeRequestError, err)
-	}
-
-	var r DeleteDeviceResponse
-
-	err = json.Unmarshal(res, &r)
+	r, err := call[DeleteDeviceResponse](ctx, api, http.MethodDelete, uri, params)
 	if err != nil {
-		return []any{}, fmt.Errorf("%s: %w", errUnmarshalError, err)
+		return nil, err
 	}
 	return r.Body, nil
 }
@@ -0,0 +1,109 @@
+package controld
+
+import (
+	"encoding/json"
+	"testing"
+	"testing/quick"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnixTimeRoundTrip(t *testing.T) {
+	// Any non-zero second survives encoding and decoding, whichever way the
+	// API chose to send it.
+	property := func(seconds int64) bool {
+		seconds = seconds%(1<<40) + 1
+		if seconds == 0 {
+			seconds = 1
+		}
+		want := time.Unix(seconds, 0).UTC()
+
+		data, err := json.Marshal(UnixTime{want})
+		if err != nil {
+			return false
+		}
+		for _, encoded := range []string{string(data), `"` + string(data) + `"`, string(data) + ".0"} {
+			var got UnixTime
+			if err := json.Unmarshal([]byte(encoded), &got); err != nil || !got.Equal(want) {
+				return false
+			}
+		}
+		return true
+	}
+	require.NoError(t, quick.Check(property, nil))
+}
+
+func TestUnixTimeVariants(t *testing.T) {
+	tests := map[string]time.Time{
+		`1700000000`:       time.Unix(1700000000, 0).UTC(),
+		`"1700000000"`:     time.Unix(1700000000, 0).UTC(),
+		`1700000000.5`:     time.Unix(1700000000, 5e8).UTC(),
+		`" 1700000000.5 "`: time.Unix(1700000000, 5e8).UTC(),
+		`0`:                {},
+		`"0"`:              {},
+		`""`:               {},
+		`null`:             {},
+	}
+	for in, want := range tests {
+		var got UnixTime
+		require.NoError(t, json.Unmarshal([]byte(in), &got), in)
+		assert.True(t, want.Equal(got.Time), "%s: got %v", in, got.Time)
+	}
+
+	for _, in := range []string{`"soon"`, `true`, `{}`, `[1]`} {
+		var got UnixTime
+		assert.Error(t, json.Unmarshal([]byte(in), &got), in)
+	}
+
+	data, err := json.Marshal(struct {
+		Ts UnixTime `json:"ts"`
+	}{})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"ts":0}`, string(data))
+}
+
+func TestIntBoolRoundTrip(t *testing.T) {
+	property := func(b bool) bool {
+		data, err := json.Marshal(IntBool(b))
+		if err != nil {
+			return false
+		}
+		var got IntBool
+		return json.Unmarshal(data, &got) == nil && bool(got) == b
+	}
+	require.NoError(t, quick.Check(property, nil))
+}
+
+func TestIntBoolVariants(t *testing.T) {
+	tests := map[string]IntBool{
+		`1`: true, `0`: false, `true`: true, `false`: false,
+		`"1"`: true, `"0"`: false, `"true"`: true, `"FALSE"`: false,
+		`""`: false, `null`: false, `1.0`: true,
+	}
+	for in, want := range tests {
+		got := IntBool(!want)
+		require.NoError(t, json.Unmarshal([]byte(in), &got), in)
+		assert.Equal(t, want, got, in)
+	}
+
+	for _, in := range []string{`"yes"`, `{}`, `[]`} {
+		var got IntBool
+		assert.Error(t, json.Unmarshal([]byte(in), &got), in)
+	}
+}
+
+func TestProfileJSONRoundTrip(t *testing.T) {
+	in := `{"PK":"p1","updated":"1700000000","name":"Home"}`
+	var p Profile
+	require.NoError(t, json.Unmarshal([]byte(in), &p))
+
+	out, err := json.Marshal(p)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"PK":"p1","updated":1700000000,"name":"Home"}`, string(out))
+
+	var again Profile
+	require.NoError(t, json.Unmarshal(out, &again))
+	assert.Equal(t, p, again)
+}
@@ -1,7 +1,16 @@
 package controld
 
 import (
+	"errors"
+	"fmt"
+	"html"
+	"mime"
 	"net/http"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/goccy/go-json"
 )
 
 const (
@@ -11,7 +20,7 @@ const (
 	errMarshalError         = "error marshalling the object"
 	errUnmarshalError       = "error unmarshalling the JSON response"
 	errTypeError            = "error verifying the type of the response"
-	errUnmarshalErrorBody   = "error unmarshalling the JSON response error body"
+	errUnsuccessful         = "the API did not report success"
 )
 
 type ErrorType string
@@ -22,6 +31,7 @@ const (
 	ErrorTypeAuthorization  ErrorType = "authorization"
 	ErrorTypeNotFound       ErrorType = "not_found"
 	ErrorTypeRateLimit      ErrorType = "rate_limit"
+	ErrorTypeService        ErrorType = "service"
 )
 
 type Error struct {
@@ -53,6 +63,10 @@ func (e RequestError) Type() ErrorType {
 	return e.controldError.Type
 }
 
+func (e RequestError) Code() int {
+	return e.controldError.Code()
+}
+
 func NewRequestError(e *Error) RequestError {
 	return RequestError{
 		controldError: e,
@@ -77,6 +91,10 @@ func (e RatelimitError) Type() ErrorType {
 	return e.controldError.Type
 }
 
+func (e RatelimitError) Code() int {
+	return e.controldError.Code()
+}
+
 func NewRatelimitError(e *Error) RatelimitError {
 	return RatelimitError{
 		controldError: e,
@@ -100,6 +118,10 @@ func (e ServiceError) Type() ErrorType {
 	return e.controldError.Type
 }
 
+func (e ServiceError) Code() int {
+	return e.controldError.Code()
+}
+
 func NewServiceError(e *Error) ServiceError {
 	return ServiceError{
 		controldError: e,
@@ -123,6 +145,10 @@ func (e AuthenticationError) Type() ErrorType {
 	return e.controldError.Type
 }
 
+func (e AuthenticationError) Code() int {
+	return e.controldError.Code()
+}
+
 func NewAuthenticationError(e *Error) AuthenticationError {
 	return AuthenticationError{
 		controldError: e,
@@ -146,6 +172,10 @@ func (e AuthorizationError) Type() ErrorType {
 	return e.controldError.Type
 }
 
+func (e AuthorizationError) Code() int {
+	return e.controldError.Code()
+}
+
 func NewAuthorizationError(e *Error) AuthorizationError {
 	return AuthorizationError{
 		controldError: e,
@@ -169,12 +199,186 @@ func (e NotFoundError) Type() ErrorType {
 	return e.controldError.Type
 }
 
+func (e NotFoundError) Code() int {
+	return e.controldError.Code()
+}
+
 func NewNotFoundError(e *Error) NotFoundError {
 	return NotFoundError{
 		controldError: e,
 	}
 }
 
+// maxSnippet is the number of bytes of an unexpected body kept in
+// UnexpectedResponseError.
+const maxSnippet = 200
+
+// UnexpectedResponseError is returned when a reply is not the JSON the API
+// sends, such as an HTML page from a proxy, a captive portal or a server that
+// is not the ControlD API.
+type UnexpectedResponseError struct {
+	StatusCode  int
+	ContentType string
+	// URL is the requested URL without its query.
+	URL string
+	// Title is the title of an HTML page, if any.
+	Title string
+	// Snippet is the start of the body with whitespace collapsed.
+	Snippet string
+}
+
+func (e *UnexpectedResponseError) Error() string {
+	kind := e.ContentType
+	if kind == "" {
+		kind = "non-JSON"
+	}
+	msg := fmt.Sprintf("unexpected %s response (HTTP %d) from %s", kind, e.StatusCode, e.URL)
+	if e.Title != "" {
+		return fmt.Sprintf("%s: %q", msg, e.Title)
+	}
+	if e.Snippet != "" {
+		return fmt.Sprintf("%s: %s", msg, e.Snippet)
+	}
+	return msg
+}
+
+// IsHTML reports whether the reply was an HTML page.
+func (e *UnexpectedResponseError) IsHTML() bool {
+	return strings.Contains(e.ContentType, "html") ||
+		strings.HasPrefix(strings.ToLower(e.Snippet), "<!doctype html") ||
+		strings.HasPrefix(strings.ToLower(e.Snippet), "<html")
+}
+
+var htmlTitle = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+
+func newUnexpectedResponseError(resp *http.Response, body []byte) *UnexpectedResponseError {
+	e := &UnexpectedResponseError{
+		StatusCode: resp.StatusCode,
+		Snippet:    snippet(string(body), maxSnippet),
+	}
+	if mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type")); err == nil {
+		e.ContentType = mediaType
+	}
+	if resp.Request != nil {
+		u := *resp.Request.URL
+		u.RawQuery = ""
+		e.URL = u.String()
+	}
+	if m := htmlTitle.FindSubmatch(body); m != nil {
+		e.Title = snippet(html.UnescapeString(string(m[1])), maxSnippet)
+	}
+	return e
+}
+
+// snippet collapses whitespace in s and truncates it to at most n bytes
+// without splitting a character.
+func snippet(s string, n int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if len(s) <= n {
+		return s
+	}
+	s = s[:n]
+	for !utf8.ValidString(s) {
+		s = s[:len(s)-1]
+	}
+	return s + "…"
+}
+
+// newAPIError returns the typed error for an error reply. The error is
+// classified by the HTTP status, or for replies with a 2xx status and success
+// set to false, by the code in the reply.
+func newAPIError(status int, info ResponseInfo) error {
+	if info.Message == "" {
+		info.Message = errUnsuccessful
+	}
+	e := &Error{StatusCode: status, Error: info}
+
+	switch code := errorStatus(status, info.Code); code {
+	case http.StatusUnauthorized:
+		e.Type = ErrorTypeAuthorization
+		return &AuthorizationError{controldError: e}
+	case http.StatusForbidden:
+		e.Type = ErrorTypeAuthentication
+		return &AuthenticationError{controldError: e}
+	case http.StatusNotFound:
+		e.Type = ErrorTypeNotFound
+		return &NotFoundError{controldError: e}
+	case http.StatusTooManyRequests:
+		e.Type = ErrorTypeRateLimit
+		return &RatelimitError{controldError: e}
+	default:
+		if code >= http.StatusInternalServerError {
+			e.Type = ErrorTypeService
+			return &ServiceError{controldError: e}
+		}
+		e.Type = ErrorTypeRequest
+		return &RequestError{controldError: e}
+	}
+}
+
+// errorStatus returns the HTTP status an error reply stands for. Replies sent
+// with a 2xx status are classified by their code, which is either an HTTP
+// status or an HTTP status followed by two digits, e.g. 40401.
+func errorStatus(status, code int) int {
+	switch {
+	case status >= http.StatusBadRequest:
+		return status
+	case code >= 400 && code < 600:
+		return code
+	case code >= 40000 && code < 60000:
+		return code / 100
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// unsuccessful reports whether body is a reply with success set to false,
+// and returns its error. Bodies without a success field are not errors.
+func unsuccessful(body []byte) (ResponseInfo, bool) {
+	var r struct {
+		Success *bool `json:"success"`
+		Error   struct {
+			Message string `json:"message"`
+			Code    int    `json:"code"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &r); err != nil || r.Success == nil || *r.Success {
+		return ResponseInfo{}, false
+	}
+
+	info := ResponseInfo{Message: r.Error.Message, Code: r.Error.Code}
+	var full Response
+	if err := json.Unmarshal(body, &full); err == nil {
+		info = full.Error
+	}
+	return info, true
+}
+
+// IsNotFound reports whether err is, or wraps, a NotFoundError.
+func IsNotFound(err error) bool {
+	var p *NotFoundError
+	var v NotFoundError
+	return errors.As(err, &p) || errors.As(err, &v)
+}
+
+// IsRateLimited reports whether err is, or wraps, a RatelimitError.
+func IsRateLimited(err error) bool {
+	var p *RatelimitError
+	var v RatelimitError
+	return errors.As(err, &p) || errors.As(err, &v)
+}
+
+// IsAuth reports whether err is, or wraps, an AuthenticationError or an
+// AuthorizationError: the token is invalid, expired or lacks permission.
+func IsAuth(err error) bool {
+	var authn *AuthenticationError
+	var authz *AuthorizationError
+	var authnV AuthenticationError
+	var authzV AuthorizationError
+	return errors.As(err, &authn) || errors.As(err, &authz) ||
+		errors.As(err, &authnV) || errors.As(err, &authzV)
+}
+
 // ClientError returns a boolean whether or not the raised error was caused by
 // something client side.
 func (e *Error) ClientError() bool {
@@ -188,8 +392,17 @@ func (e *Error) ClientRateLimited() bool {
 	return e.Type == ErrorTypeRateLimit
 }
 
+// Code returns the error code from the reply, or the HTTP status when the
+// reply had none.
+func (e *Error) Code() int {
+	if e.Error.Code != 0 {
+		return e.Error.Code
+	}
+	return e.StatusCode
+}
+
 // InternalErrorCodeIs returns a boolean whether or not the desired internal
 // error code is present in `e.InternalErrorCodes`.
 func (e *Error) InternalErrorCodeIs(code int) bool {
-	return e.StatusCode == code
+	return e.StatusCode == code || e.Error.Code == code
 }
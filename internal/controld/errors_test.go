@@ -0,0 +1,96 @@
+package controld
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func htmlServer(t *testing.T, status int, contentType, body string) *API {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(status)
+		_, _ = io.WriteString(w, body)
+	}))
+	t.Cleanup(server.Close)
+
+	api, err := New("test-token", BaseURL(server.URL), UsingRateLimit(1000), UsingRetryPolicy(0, 0, 0))
+	require.NoError(t, err)
+	return api
+}
+
+func TestUnexpectedResponseError(t *testing.T) {
+	page := `<!DOCTYPE html>
+<html><head><title>Access   Denied &amp; Blocked</title></head>
+<body>` + strings.Repeat("filtered ", 100) + `</body></html>`
+	api := htmlServer(t, http.StatusForbidden, "text/html; charset=utf-8", page)
+
+	_, err := api.ListKnownIPs(context.Background(), ListKnownIPsParams{DeviceID: "d1"})
+	var unexpected *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpected)
+
+	assert.Equal(t, http.StatusForbidden, unexpected.StatusCode)
+	assert.Equal(t, "text/html", unexpected.ContentType)
+	assert.True(t, strings.HasSuffix(unexpected.URL, "/access"), unexpected.URL)
+	assert.Equal(t, "Access Denied & Blocked", unexpected.Title)
+	assert.True(t, unexpected.IsHTML())
+	assert.LessOrEqual(t, len(unexpected.Snippet), maxSnippet+len("…"))
+	assert.True(t, strings.HasPrefix(unexpected.Snippet, "<!DOCTYPE html> <html><head>"), unexpected.Snippet)
+	assert.Contains(t, err.Error(), `unexpected text/html response (HTTP 403)`)
+	assert.Contains(t, err.Error(), `"Access Denied & Blocked"`)
+	assert.False(t, IsAuth(err), "an HTML page is not an API authorization error")
+}
+
+func TestUnexpectedResponseOnSuccessStatus(t *testing.T) {
+	api := htmlServer(t, http.StatusOK, "text/html", "<html><body>Please log in to the Wi-Fi</body></html>")
+
+	_, err := api.ListDevices(context.Background())
+	var unexpected *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpected)
+	assert.Equal(t, http.StatusOK, unexpected.StatusCode)
+	assert.Empty(t, unexpected.Title)
+	assert.Contains(t, err.Error(), "Please log in to the Wi-Fi")
+}
+
+func TestUnexpectedPlainTextError(t *testing.T) {
+	api := htmlServer(t, http.StatusBadGateway, "", "")
+	api.retryPolicy.MaxRetries = 0
+
+	_, err := api.ListDevices(context.Background())
+	require.Error(t, err)
+	assert.False(t, errors.As(err, new(*UnexpectedResponseError)), "5xx replies keep their retry error")
+
+	api = htmlServer(t, http.StatusBadRequest, "text/plain", "bad request\n")
+	_, err = api.ListDevices(context.Background())
+	var unexpected *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpected)
+	assert.Equal(t, "bad request", unexpected.Snippet)
+	assert.False(t, unexpected.IsHTML())
+}
+
+func TestUnexpectedGatewayPage(t *testing.T) {
+	api := htmlServer(t, http.StatusBadGateway, "text/html", "<html><head><title>502 Bad Gateway</title></head></html>")
+	api.retryPolicy.MaxRetries = 1
+
+	// The page from the last attempt is kept after retrying.
+	_, err := api.ListDevices(context.Background())
+	var unexpected *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpected)
+	assert.Equal(t, http.StatusBadGateway, unexpected.StatusCode)
+	assert.Equal(t, "text/html", unexpected.ContentType)
+	assert.Equal(t, "502 Bad Gateway", unexpected.Title)
+}
+
+func TestSnippet(t *testing.T) {
+	assert.Equal(t, "a b c", snippet(" a\n\tb   c ", 10))
+	assert.Equal(t, "abc…", snippet("abcdef", 3))
+	assert.Equal(t, "é…", snippet("éé", 3), "never splits a character")
+}
@@ -0,0 +1,75 @@
+package controld
+
+import (
+	"net/http"
+	"time"
+)
+
+// Request is a single HTTP attempt of an API call, as seen by middleware.
+type Request struct {
+	// HTTP is the outgoing request. Middleware may change its headers or URL,
+	// for example to sign it.
+	HTTP *http.Request
+	// Method and URI are the method and path (with query) of the API call,
+	// relative to the client's base URL.
+	Method string
+	URI    string
+	// Body is the request body. Middleware may replace it; the HTTP request
+	// body is set from it when the request is sent.
+	Body []byte
+	// Attempt is 1 for the first attempt and increases with each retry.
+	Attempt int
+}
+
+// Handler sends a request and returns the response.
+type Handler func(req *Request) (*http.Response, error)
+
+// Middleware wraps the sending of every HTTP attempt. It may inspect or modify
+// the request before calling next, and inspect the response afterwards. A
+// middleware that reads the response body must replace it so later readers
+// still see the full body.
+type Middleware func(next Handler) Handler
+
+// Exchange is the outcome of a single HTTP attempt, passed to observers.
+type Exchange struct {
+	Method     string
+	URI        string
+	Body       []byte
+	Attempt    int
+	StatusCode int
+	Duration   time.Duration
+	Err        error
+}
+
+// Observe returns middleware that calls fn after every HTTP attempt.
+func Observe(fn func(Exchange)) Middleware {
+	return func(next Handler) Handler {
+		return func(req *Request) (*http.Response, error) {
+			start := time.Now()
+			resp, err := next(req)
+
+			ex := Exchange{
+				Method:   req.Method,
+				URI:      req.URI,
+				Body:     req.Body,
+				Attempt:  req.Attempt,
+				Duration: time.Since(start),
+				Err:      err,
+			}
+			if resp != nil {
+				ex.StatusCode = resp.StatusCode
+			}
+			fn(ex)
+			return resp, err
+		}
+	}
+}
+
+// chain wraps h with the client's middleware. The first middleware added is
+// the outermost.
+func (api *API) chain(h Handler) Handler {
+	for i := len(api.middleware) - 1; i >= 0; i-- {
+		h = api.middleware[i](h)
+	}
+	return h
+}
@@ -0,0 +1,107 @@
+package controld
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddlewareOrder(t *testing.T) {
+	api, _ := setup(t, `{"success":true,"body":{"profiles":[]}}`)
+
+	var calls []string
+	trace := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(req *Request) (*http.Response, error) {
+				calls = append(calls, name+" before")
+				resp, err := next(req)
+				calls = append(calls, name+" after")
+				return resp, err
+			}
+		}
+	}
+	require.NoError(t, UsingMiddleware(trace("outer"), trace("inner"))(api))
+
+	_, err := api.ListProfiles(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"outer before", "inner before", "inner after", "outer after"}, calls)
+}
+
+func TestMiddlewareSignsRequest(t *testing.T) {
+	key := []byte("secret")
+	var gotSignature, gotBody string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		gotBody = string(data)
+		gotSignature = r.Header.Get("X-Signature")
+		_, _ = w.Write([]byte(`{"success":true,"body":{"profiles":[]}}`))
+	}))
+	defer srv.Close()
+
+	sign := func(next Handler) Handler {
+		return func(req *Request) (*http.Response, error) {
+			mac := hmac.New(sha256.New, key)
+			mac.Write([]byte(req.Method + " " + req.URI + "\n"))
+			mac.Write(req.Body)
+			req.HTTP.Header.Set("X-Signature", hex.EncodeToString(mac.Sum(nil)))
+			return next(req)
+		}
+	}
+	api, err := New("token", BaseURL(srv.URL), UsingMiddleware(sign))
+	require.NoError(t, err)
+
+	_, err = api.CreateProfile(context.Background(), CreateProfileParams{Name: "Home"})
+	require.NoError(t, err)
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("POST /profiles\n"))
+	mac.Write([]byte(gotBody))
+	assert.Equal(t, hex.EncodeToString(mac.Sum(nil)), gotSignature)
+	assert.Contains(t, gotBody, `"name":"Home"`)
+}
+
+func TestMiddlewareReplacesBody(t *testing.T) {
+	api, requests := setup(t, `{"success":true,"body":{"profiles":[]}}`)
+	rewrite := func(next Handler) Handler {
+		return func(req *Request) (*http.Response, error) {
+			req.Body = []byte(`{"name":"Rewritten"}`)
+			return next(req)
+		}
+	}
+	require.NoError(t, UsingMiddleware(rewrite)(api))
+
+	_, err := api.CreateProfile(context.Background(), CreateProfileParams{Name: "Home"})
+
+	require.NoError(t, err)
+	assert.Equal(t, `{"name":"Rewritten"}`, (*requests)[0].Body)
+}
+
+func TestObserve(t *testing.T) {
+	srv, _ := flakyServer(t, 1, http.StatusServiceUnavailable, nil)
+	var exchanges []Exchange
+	api, err := New("token",
+		BaseURL(srv.URL),
+		UsingRateLimit(1000),
+		UsingRetryPolicy(2, 0, 0),
+		UsingMiddleware(Observe(func(ex Exchange) { exchanges = append(exchanges, ex) })),
+	)
+	require.NoError(t, err)
+
+	_, err = api.ListProfiles(context.Background())
+
+	require.NoError(t, err)
+	require.Len(t, exchanges, 2)
+	assert.Equal(t, Exchange{Method: http.MethodGet, URI: "/profiles", Attempt: 1, StatusCode: http.StatusServiceUnavailable, Duration: exchanges[0].Duration}, exchanges[0])
+	assert.Equal(t, 2, exchanges[1].Attempt)
+	assert.Equal(t, http.StatusOK, exchanges[1].StatusCode)
+	assert.Positive(t, exchanges[1].Duration)
+}
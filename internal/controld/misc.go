@@ -2,8 +2,6 @@ package controld
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
 	"net"
 	"net/http"
 )
@@ -55,13 +53,9 @@ type ListNetworkResponse struct {
 func (api *API) ListIP(ctx context.Context) (IP, error) {
 	uri := buildURI("/ip", nil)
 
-	res, err := api.makeRequestContext(ctx, http.MethodGet, uri, nil)
+	r, err := call[ListIPResponse](ctx, api, http.MethodGet, uri, nil)
 	if err != nil {
-		return IP{}, fmt.Errorf("%s: %w", errMakeRequestError, err)
-	}
-	var r ListIPResponse
-	if err := json.Unmarshal(res, &r); err != nil {
-		return IP{}, fmt.Errorf("%s: %w", errUnmarshalError, err)
+		return IP{}, err
 	}
 	return r.Body, nil
 }
@@ -69,13 +63,9 @@ func (api *API) ListIP(ctx context.Context) (IP, error) {
 func (api *API) ListNetwork(ctx context.Context) ([]Network, error) {
 	uri := buildURI("/network", nil)
 
-	res, err := api.makeRequestContext(ctx, http.MethodGet, uri, nil)
+	r, err := call[ListNetworkResponse](ctx, api, http.MethodGet, uri, nil)
 	if err != nil {
-		return []Network{}, fmt.Errorf("%s: %w", errMakeRequestError, err)
-	}
-	var r ListNetworkResponse
-	if err := json.Unmarshal(res, &r); err != nil {
-		return []Network{}, fmt.Errorf("%s: %w", errUnmarshalError, err)
+		return nil, err
 	}
 	return r.Body.Network, nil
 }
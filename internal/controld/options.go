@@ -1,6 +1,8 @@
 package controld
 
 import (
+	"context"
+	"log/slog"
 	"net/http"
 	"time"
 
@@ -63,6 +65,25 @@ func UsingLogger(logger Logger) Option {
 	}
 }
 
+// UsingStatsHook adds a function that is called with the statistics of every
+// API call once it completes, including the number of retries and the time
+// spent waiting. ctx is the context the call was made with.
+func UsingStatsHook(hook func(ctx context.Context, stats RequestStats)) Option {
+	return func(api *API) error {
+		api.statsHooks = append(api.statsHooks, hook)
+		return nil
+	}
+}
+
+// UsingMiddleware appends middleware that wraps every HTTP attempt made by the
+// client. Middleware runs in the order given, the first being the outermost.
+func UsingMiddleware(middleware ...Middleware) Option {
+	return func(api *API) error {
+		api.middleware = append(api.middleware, middleware...)
+		return nil
+	}
+}
+
 // UserAgent can be set if you want to send a software name and version for HTTP access logs.
 // It is recommended to set it in order to help future Customer Support diagnostics
 // and prevent collateral damage by sharing generic User-Agent string with abusive users.
@@ -82,6 +103,8 @@ func BaseURL(baseURL string) Option {
 	}
 }
 
+// Debug enables dumps of every HTTP request and response, with credentials
+// redacted. Use DebugDump to dump only headers or bodies.
 func Debug(debug bool) Option {
 	return func(api *API) error {
 		api.Debug = debug
@@ -89,6 +112,42 @@ func Debug(debug bool) Option {
 	}
 }
 
+// DebugDump sets which parts of HTTP exchanges are dumped. It overrides Debug.
+func DebugDump(level DumpLevel) Option {
+	return func(api *API) error {
+		api.dump = level
+		return nil
+	}
+}
+
+// UsingDebugLogger sets the logger that receives HTTP dumps. By default they
+// go to slog.Default() at debug level.
+func UsingDebugLogger(logger *slog.Logger) Option {
+	return func(api *API) error {
+		api.debugLog = logger
+		return nil
+	}
+}
+
+// RedactKeys adds JSON keys and query parameters whose values are masked in
+// HTTP dumps, in addition to passwords, tokens and secrets.
+func RedactKeys(keys ...string) Option {
+	return func(api *API) error {
+		api.redactKeys = append(api.redactKeys, keys...)
+		return nil
+	}
+}
+
+// UsingResponseCache serves GET responses from cache while they are fresh.
+// Each endpoint has its own time to live, and mutating calls invalidate the
+// cached responses they affect.
+func UsingResponseCache(cache ResponseCache) Option {
+	return func(api *API) error {
+		api.cache = cache
+		return nil
+	}
+}
+
 // parseOptions parses the supplied options functions and returns a configured
 // *API instance.
 func (api *API) parseOptions(opts ...Option) error {
@@ -2,7 +2,6 @@ package controld
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"net/http"
 )
@@ -11,6 +10,21 @@ type Profile struct {
 	PK      string   `json:"PK"`
 	Updated UnixTime `json:"updated"`
 	Name    string   `json:"name"`
+	// Settings summarizes the profile's configuration. Only ListProfiles
+	// returns it.
+	Settings *ProfileSettings `json:"profile,omitempty"`
+}
+
+// ProfileSettings is the configuration summary ListProfiles returns for each
+// profile.
+type ProfileSettings struct {
+	Options ProfileOptions `json:"opt"`
+}
+
+// ProfileOptions are the options set on a profile.
+type ProfileOptions struct {
+	Count int   `json:"count"`
+	Data  []Opt `json:"data"`
 }
 
 type ListProfilesBody struct {
@@ -110,16 +124,9 @@ type UpdateProfilesOptionResponse struct {
 func (api *API) ListProfiles(ctx context.Context) ([]Profile, error) {
 	uri := buildURI("/profiles", nil)
 
-	res, err := api.makeRequestContext(ctx, http.MethodGet, uri, nil)
-	if err != nil {
-		return []Profile{}, fmt.Errorf("%s: %w", errMakeRequestError, err)
-	}
-
-	var r ListProfilesResponse
-
-	err = json.Unmarshal(res, &r)
+	r, err := call[ListProfilesResponse](ctx, api, http.MethodGet, uri, nil)
 	if err != nil {
-		return []Profile{}, fmt.Errorf("%s: %w", errUnmarshalError, err)
+		return nil, err
 	}
 	return r.Body.Profiles, nil
 }
@@ -127,58 +134,37 @@ func (api *API) ListProfiles(ctx context.Context) ([]Profile, error) {
 func (api *API) CreateProfile(ctx context.Context, params CreateProfileParams) ([]Profile, error) {
 	uri := buildURI("/profiles", nil)
 
-	res, err := api.makeRequestContext(ctx, http.MethodPost, uri, params)
+	r, err := call[CreateProfileResponse](ctx, api, http.MethodPost, uri, params)
 	if err != nil {
-		return []Profile{}, fmt.Errorf("%s: %w", errMakeRequestError, err)
-	}
-
-	var r CreateProfileResponse
-
-	err = json.Unmarshal(res, &r)
-	if err != nil {
-		return []Profile{}, fmt.Errorf("%s: %w", errUnmarshalError, err)
+		return nil, err
 	}
 	return r.Body.Profiles, nil
 }
 
 func (api *API) UpdateProfile(ctx context.Context, params UpdateProfileParams) ([]Profile, error) {
 	if params.ProfileID == "" {
-		return []Profile{}, fmt.Errorf("update: no profile ID provided")
+		return nil, fmt.Errorf("update: no profile ID provided")
 	}
 	baseURL := fmt.Sprintf("/profiles/%s", params.ProfileID)
 	uri := buildURI(baseURL, nil)
 
-	res, err := api.makeRequestContext(ctx, http.MethodPut, uri, params)
+	r, err := call[UpdateProfileResponse](ctx, api, http.MethodPut, uri, params)
 	if err != nil {
-		return []Profile{}, fmt.Errorf("%s: %w", errMakeRequestError, err)
-	}
-
-	var r UpdateProfileResponse
-
-	err = json.Unmarshal(res, &r)
-	if err != nil {
-		return []Profile{}, fmt.Errorf("%s: %w", errUnmarshalError, err)
+		return nil, err
 	}
 	return r.Body.Profiles, nil
 }
 
 func (api *API) DeleteProfile(ctx context.Context, params DeleteProfileParams) ([]any, error) {
 	if params.ProfileID == "" {
-		return []any{}, fmt.Errorf("delete: no profile ID provided")
+		return nil, fmt.Errorf("delete: no profile ID provided")
 	}
 	baseURL := fmt.Sprintf("/profiles/%s", params.ProfileID)
 	uri := buildURI(baseURL, nil)
 
-	res, err := api.makeRequestContext(ctx, http.MethodDelete, uri, params)
+	r, err := call[DeleteProfileResponse](ctx, api, http.MethodDelete, uri, params)
 	if err != nil {
-		return []any{}, fmt.Errorf("%s: %w", errMakeRequestError, err)
-	}
-
-	var r DeleteProfileResponse
-
-	err = json.Unmarshal(res, &r)
-	if err != nil {
-		return []any{}, fmt.Errorf("%s: %w", errUnmarshalError, err)
+		return nil, err
 	}
 	return r.Body, nil
 }
@@ -186,16 +172,9 @@ func (api *API) DeleteProfile(ctx context.Context, params DeleteProfileParams) (
 func (api *API) ListProfilesOptions(ctx context.Context) ([]ProfilesOption, error) {
 	uri := buildURI("/profiles/options", nil)
 
-	res, err := api.makeRequestContext(ctx, http.MethodGet, uri, nil)
-	if err != nil {
-		return []ProfilesOption{}, fmt.Errorf("%s: %w", errMakeRequestError, err)
-	}
-
-	var r ListProfilesOptionsResponse
-
-	err = json.Unmarshal(res, &r)
+	r, err := call[ListProfilesOptionsResponse](ctx, api, http.MethodGet, uri, nil)
 	if err != nil {
-		return []ProfilesOption{}, fmt.Errorf("%s: %w", errUnmarshalError, err)
+		return nil, err
 	}
 	return r.Body.Options, nil
 }
@@ -210,16 +189,9 @@ func (api *API) UpdateProfilesOption(ctx context.Context, params UpdateProfilesO
 	baseURL := fmt.Sprintf("/profiles/%s/options/%s", params.ProfileID, params.Name)
 	uri := buildURI(baseURL, nil)
 
-	res, err := api.makeRequestContext(ctx, http.MethodPut, uri, params)
-	if err != nil {
-		return nil, fmt.Errorf("%s: %w", errMakeRequestError, err)
-	}
-
-	var r UpdateProfilesOptionResponse
-
-	err = json.Unmarshal(res, &r)
+	r, err := call[UpdateProfilesOptionResponse](ctx, api, http.MethodPut, uri, params)
 	if err != nil {
-		return nil, fmt.Errorf("%s: %w", errUnmarshalError, err)
+		return nil, err
 	}
 	return r.Body.Options, nil
 }
@@ -2,7 +2,6 @@ package controld
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"net/http"
 )
@@ -17,8 +16,8 @@ type Rule struct {
 }
 
 type ListProfileCustomRulesParams struct {
-	ProfileID string `json:"profile_id"`
-	FolderID  string `json:"folder_id"`
+	ProfileID string `json:"profile_id" url:"-"`
+	FolderID  string `json:"folder_id" url:"-"`
 }
 
 type ListProfileCustomRulesBody struct {
@@ -80,66 +79,45 @@ type DeleteProfileCustomRuleResponse struct {
 
 func (api *API) ListProfileCustomRules(ctx context.Context, params ListProfileCustomRulesParams) ([]Rule, error) {
 	if params.ProfileID == "" {
-		return []Rule{}, fmt.Errorf("list: no profile ID provided")
+		return nil, fmt.Errorf("list: no profile ID provided")
 	}
 	if params.FolderID == "" {
-		return []Rule{}, fmt.Errorf("list: no folder ID provided")
+		return nil, fmt.Errorf("list: no folder ID provided")
 	}
 	baseURL := fmt.Sprintf("/profiles/%s/rules/%s", params.ProfileID, params.FolderID)
-	uri := buildURI(baseURL, nil)
+	uri := buildURI(baseURL, params)
 
-	res, err := api.makeRequestContext(ctx, http.MethodGet, uri, nil)
+	r, err := call[ListProfileCustomRulesResponse](ctx, api, http.MethodGet, uri, nil)
 	if err != nil {
-		return []Rule{}, fmt.Errorf("%s: %w", errMakeRequestError, err)
-	}
-
-	var r ListProfileCustomRulesResponse
-
-	err = json.Unmarshal(res, &r)
-	if err != nil {
-		return []Rule{}, fmt.Errorf("%s: %w", errUnmarshalError, err)
+		return nil, err
 	}
 	return r.Body.Rules, nil
 }
 
 func (api *API) CreateProfileCustomRule(ctx context.Context, params CreateProfileCustomRuleParams) ([]CustomRule, error) {
 	if params.ProfileID == "" {
-		return []CustomRule{}, fmt.Errorf("create: no profile ID provided")
+		return nil, fmt.Errorf("create: no profile ID provided")
 	}
 	baseURL := fmt.Sprintf("/profiles/%s/rules", params.ProfileID)
 	uri := buildURI(baseURL, nil)
 
-	res, err := api.makeRequestContext(ctx, http.MethodPost, uri, params)
-	if err != nil {
-		return []CustomRule{}, fmt.Errorf("%s: %w", errMakeRequestError, err)
-	}
-
-	var r CreateProfileCustomRuleResponse
-
-	err = json.Unmarshal(res, &r)
+	r, err := call[CreateProfileCustomRuleResponse](ctx, api, http.MethodPost, uri, params)
 	if err != nil {
-		return []CustomRule{}, fmt.Errorf("%s: %w", errUnmarshalError, err)
+		return nil, err
 	}
 	return r.Body.Rules, nil
 }
 
 func (api *API) UpdateProfileCustomRule(ctx context.Context, params UpdateProfileCustomRuleParams) ([]CustomRule, error) {
 	if params.ProfileID == "" {
-		return []CustomRule{}, fmt.Errorf("update: no profile ID provided")
+		return nil, fmt.Errorf("update: no profile ID provided")
 	}
 	baseURL := fmt.Sprintf("/profiles/%s/rules", params.ProfileID)
 	uri := buildURI(baseURL, nil)
 
-	res, err := api.makeRequestContext(ctx, http.MethodPut, uri, params)
-	if err != nil {
-		return []CustomRule{}, fmt.Errorf("%s: %w", errMakeRequestError, err)
-	}
-
-	var r UpdateProfileCustomRuleResponse
-
-	err = json.Unmarshal(res, &r)
+	r, err := call[UpdateProfileCustomRuleResponse](ctx, api, http.MethodPut, uri, params)
 	if err != nil {
-		return []CustomRule{}, fmt.Errorf("%s: %w", errUnmarshalError, err)
+		return nil, err
 	}
 	return r.Body.Rules, nil
 }
@@ -154,16 +132,9 @@ func (api *API) DeleteProfileCustomRule(ctx context.Context, params DeleteProfil
 	baseURL := fmt.Sprintf("/profiles/%s/rules/%s", params.ProfileID, params.Hostname)
 	uri := buildURI(baseURL, nil)
 
-	res, err := api.makeRequestContext(ctx, http.MethodDelete, uri, params)
-	if err != nil {
-		return nil, fmt.Errorf("%s: %w", errMakeRequestError, err)
-	}
-
-	var r DeleteProfileCustomRuleResponse
-
-	err = json.Unmarshal(res, &r)
+	r, err := call[DeleteProfileCustomRuleResponse](ctx, api, http.MethodDelete, uri, params)
 	if err != nil {
-		return nil, fmt.Errorf("%s: %w", errUnmarshalError, err)
+		return nil, err
 	}
 	return r.Body, nil
 }
@@ -11,7 +11,7 @@ import (
 type DefaultRule Action
 
 type ListProfileDefaultRuleParams struct {
-	ProfileID string `json:"profile_id"`
+	ProfileID string `json:"profile_id" url:"-"`
 }
 
 type ListProfileDefaultRuleBody struct {
@@ -44,18 +44,11 @@ func (api *API) ListProfileDefaultRule(ctx context.Context, params ListProfileDe
 		return DefaultRule{}, fmt.Errorf("list: no profile ID provided")
 	}
 	baseURL := fmt.Sprintf("/profiles/%s/default", params.ProfileID)
-	uri := buildURI(baseURL, nil)
-
-	res, err := api.makeRequestContext(ctx, http.MethodGet, uri, nil)
-	if err != nil {
-		return DefaultRule{}, fmt.Errorf("%s: %w", errMakeRequestError, err)
-	}
-
-	var r ListProfileDefaultRuleResponse
+	uri := buildURI(baseURL, params)
 
-	err = json.Unmarshal(res, &r)
+	r, err := call[ListProfileDefaultRuleResponse](ctx, api, http.MethodGet, uri, nil)
 	if err != nil {
-		return DefaultRule{}, fmt.Errorf("%s: %w", errUnmarshalError, err)
+		return DefaultRule{}, err
 	}
 
 	switch rule := r.Body.Default.(type) {
@@ -88,16 +81,9 @@ func (api *API) UpdateProfileDefaultRule(ctx context.Context, params UpdateProfi
 	baseURL := fmt.Sprintf("/profiles/%s/default", params.ProfileID)
 	uri := buildURI(baseURL, nil)
 
-	res, err := api.makeRequestContext(ctx, http.MethodPut, uri, params)
-	if err != nil {
-		return DefaultRule{}, fmt.Errorf("%s: %w", errMakeRequestError, err)
-	}
-
-	var r UpdateProfileDefaultRuleResponse
-
-	err = json.Unmarshal(res, &r)
+	r, err := call[UpdateProfileDefaultRuleResponse](ctx, api, http.MethodPut, uri, params)
 	if err != nil {
-		return DefaultRule{}, fmt.Errorf("%s: %w", errUnmarshalError, err)
+		return DefaultRule{}, err
 	}
 	return r.Body.Default, nil
 }
@@ -2,7 +2,6 @@ package controld
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"net"
 	"net/http"
@@ -38,7 +37,7 @@ type FilterResolvers struct {
 }
 
 type ListProfileFiltersParams struct {
-	ProfileID string `json:"profile_id"`
+	ProfileID string `json:"profile_id" url:"-"`
 }
 
 type ListProfileFiltersBody struct {
@@ -70,18 +69,11 @@ func (api *API) ListProfileNativeFilters(ctx context.Context, params ListProfile
 		return nil, fmt.Errorf("list: no profile ID provided")
 	}
 	baseURL := fmt.Sprintf("/profiles/%s/filters", params.ProfileID)
-	uri := buildURI(baseURL, nil)
+	uri := buildURI(baseURL, params)
 
-	res, err := api.makeRequestContext(ctx, http.MethodGet, uri, nil)
+	r, err := call[ListProfileFiltersResponse](ctx, api, http.MethodGet, uri, nil)
 	if err != nil {
-		return []Filter{}, fmt.Errorf("%s: %w", errMakeRequestError, err)
-	}
-
-	var r ListProfileFiltersResponse
-
-	err = json.Unmarshal(res, &r)
-	if err != nil {
-		return []Filter{}, fmt.Errorf("%s: %w", errUnmarshalError, err)
+		return nil, err
 	}
 	return r.Body.Filters, nil
 }
@@ -91,18 +83,11 @@ func (api *API) ListProfileExternalFilters(ctx context.Context, params ListProfi
 		return nil, fmt.Errorf("list: no profile ID provided")
 	}
 	baseURL := fmt.Sprintf("/profiles/%s/filters/external", params.ProfileID)
-	uri := buildURI(baseURL, nil)
-
-	res, err := api.makeRequestContext(ctx, http.MethodGet, uri, nil)
-	if err != nil {
-		return []Filter{}, fmt.Errorf("%s: %w", errMakeRequestError, err)
-	}
+	uri := buildURI(baseURL, params)
 
-	var r ListProfileFiltersResponse
-
-	err = json.Unmarshal(res, &r)
+	r, err := call[ListProfileFiltersResponse](ctx, api, http.MethodGet, uri, nil)
 	if err != nil {
-		return []Filter{}, fmt.Errorf("%s: %w", errUnmarshalError, err)
+		return nil, err
 	}
 	return r.Body.Filters, nil
 }
@@ -114,16 +99,9 @@ func (api *API) UpdateProfileFilter(ctx context.Context, params UpdateProfileFil
 	baseURL := fmt.Sprintf("/profiles/%s/filters/filter/%s", params.ProfileID, params.Filter)
 	uri := buildURI(baseURL, nil)
 
-	res, err := api.makeRequestContext(ctx, http.MethodPut, uri, params)
-	if err != nil {
-		return nil, fmt.Errorf("%s: %w", errMakeRequestError, err)
-	}
-
-	var r UpdateProfileFilterResponse
-
-	err = json.Unmarshal(res, &r)
+	r, err := call[UpdateProfileFilterResponse](ctx, api, http.MethodPut, uri, params)
 	if err != nil {
-		return nil, fmt.Errorf("%s: %w", errUnmarshalError, err)
+		return nil, err
 	}
 	return r.Body.Filters, nil
 }
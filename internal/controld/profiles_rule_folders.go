@@ -2,7 +2,6 @@ package controld
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"net/http"
 )
@@ -20,7 +19,7 @@ type Group struct {
 }
 
 type ListProfileRuleFoldersParams struct {
-	ProfileID string `json:"profile_id"`
+	ProfileID string `json:"profile_id" url:"-"`
 }
 
 type ListProfileRuleFoldersBody struct {
@@ -78,63 +77,42 @@ type DeleteProfileRuleFolderResponse struct {
 
 func (api *API) ListProfileRuleFolders(ctx context.Context, params ListProfileRuleFoldersParams) ([]Group, error) {
 	if params.ProfileID == "" {
-		return []Group{}, fmt.Errorf("list: no profile ID provided")
+		return nil, fmt.Errorf("list: no profile ID provided")
 	}
 	baseURL := fmt.Sprintf("/profiles/%s/groups", params.ProfileID)
-	uri := buildURI(baseURL, nil)
+	uri := buildURI(baseURL, params)
 
-	res, err := api.makeRequestContext(ctx, http.MethodGet, uri, nil)
+	r, err := call[ListProfileRuleFoldersResponse](ctx, api, http.MethodGet, uri, nil)
 	if err != nil {
-		return []Group{}, fmt.Errorf("%s: %w", errMakeRequestError, err)
-	}
-
-	var r ListProfileRuleFoldersResponse
-
-	err = json.Unmarshal(res, &r)
-	if err != nil {
-		return []Group{}, fmt.Errorf("%s: %w", errUnmarshalError, err)
+		return nil, err
 	}
 	return r.Body.Groups, nil
 }
 
 func (api *API) CreateProfileRuleFolder(ctx context.Context, params CreateProfileRuleFolderParams) ([]Group, error) {
 	if params.ProfileID == "" {
-		return []Group{}, fmt.Errorf("create: no profile ID provided")
+		return nil, fmt.Errorf("create: no profile ID provided")
 	}
 	baseURL := fmt.Sprintf("/profiles/%s/groups", params.ProfileID)
 	uri := buildURI(baseURL, nil)
 
-	res, err := api.makeRequestContext(ctx, http.MethodPost, uri, params)
-	if err != nil {
-		return []Group{}, fmt.Errorf("%s: %w", errMakeRequestError, err)
-	}
-
-	var r CreateProfileRuleFolderResponse
-
-	err = json.Unmarshal(res, &r)
+	r, err := call[CreateProfileRuleFolderResponse](ctx, api, http.MethodPost, uri, params)
 	if err != nil {
-		return []Group{}, fmt.Errorf("%s: %w", errUnmarshalError, err)
+		return nil, err
 	}
 	return r.Body.Groups, nil
 }
 
 func (api *API) UpdateProfileRuleFolder(ctx context.Context, params UpdateProfileRuleFolderParams) ([]Group, error) {
 	if params.ProfileID == "" {
-		return []Group{}, fmt.Errorf("update: no profile ID provided")
+		return nil, fmt.Errorf("update: no profile ID provided")
 	}
 	baseURL := fmt.Sprintf("/profiles/%s/groups/%s", params.ProfileID, params.FolderID)
 	uri := buildURI(baseURL, nil)
 
-	res, err := api.makeRequestContext(ctx, http.MethodPut, uri, params)
-	if err != nil {
-		return []Group{}, fmt.Errorf("%s: %w", errMakeRequestError, err)
-	}
-
-	var r UpdateProfileRuleFolderResponse
-
-	err = json.Unmarshal(res, &r)
+	r, err := call[UpdateProfileRuleFolderResponse](ctx, api, http.MethodPut, uri, params)
 	if err != nil {
-		return []Group{}, fmt.Errorf("%s: %w", errUnmarshalError, err)
+		return nil, err
 	}
 	return r.Body.Groups, nil
 }
@@ -146,16 +124,9 @@ func (api *API) DeleteProfileRuleFolder(ctx context.Context, params DeleteProfil
 	baseURL := fmt.Sprintf("/profiles/%s/groups/%s", params.ProfileID, params.FolderID)
 	uri := buildURI(baseURL, nil)
 
-	res, err := api.makeRequestContext(ctx, http.MethodDelete, uri, params)
-	if err != nil {
-		return nil, fmt.Errorf("%s: %w", errMakeRequestError, err)
-	}
-
-	var r DeleteProfileRuleFolderResponse
-
-	err = json.Unmarshal(res, &r)
+	r, err := call[DeleteProfileRuleFolderResponse](ctx, api, http.MethodDelete, uri, params)
 	if err != nil {
-		return nil, fmt.Errorf("%s: %w", errUnmarshalError, err)
+		return nil, err
 	}
 	return r.Body, nil
 }
@@ -2,7 +2,6 @@ package controld
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"net/http"
 )
@@ -36,7 +35,7 @@ type Action struct {
 }
 
 type ListProfileServicesParams struct {
-	ProfileID string `json:"profile_id"`
+	ProfileID string `json:"profile_id" url:"-"`
 }
 
 type ListProfileServicesBody struct {
@@ -82,45 +81,31 @@ type DeleteProfileServiceResponse struct {
 
 func (api *API) ListProfileServices(ctx context.Context, params ListProfileServicesParams) ([]ProfileService, error) {
 	if params.ProfileID == "" {
-		return []ProfileService{}, fmt.Errorf("list: no profile ID provided")
+		return nil, fmt.Errorf("list: no profile ID provided")
 	}
 	baseURL := fmt.Sprintf("/profiles/%s/services", params.ProfileID)
-	uri := buildURI(baseURL, nil)
-
-	res, err := api.makeRequestContext(ctx, http.MethodGet, uri, nil)
-	if err != nil {
-		return []ProfileService{}, fmt.Errorf("%s: %w", errMakeRequestError, err)
-	}
+	uri := buildURI(baseURL, params)
 
-	var r ListProfileServicesResponse
-
-	err = json.Unmarshal(res, &r)
+	r, err := call[ListProfileServicesResponse](ctx, api, http.MethodGet, uri, nil)
 	if err != nil {
-		return []ProfileService{}, fmt.Errorf("%s: %w", errUnmarshalError, err)
+		return nil, err
 	}
 	return r.Body.Services, nil
 }
 
 func (api *API) UpdateProfileService(ctx context.Context, params UpdateProfileServiceParams) ([]Action, error) {
 	if params.ProfileID == "" {
-		return []Action{}, fmt.Errorf("update: no profile ID provided")
+		return nil, fmt.Errorf("update: no profile ID provided")
 	}
 	if params.Service == "" {
-		return []Action{}, fmt.Errorf("update: no service provided")
+		return nil, fmt.Errorf("update: no service provided")
 	}
 	baseURL := fmt.Sprintf("/profiles/%s/services/%s", params.ProfileID, params.Service)
 	uri := buildURI(baseURL, nil)
 
-	res, err := api.makeRequestContext(ctx, http.MethodPut, uri, params)
-	if err != nil {
-		return []Action{}, fmt.Errorf("%s: %w", errMakeRequestError, err)
-	}
-
-	var r UpdateProfileServiceResponse
-
-	err = json.Unmarshal(res, &r)
+	r, err := call[UpdateProfileServiceResponse](ctx, api, http.MethodPut, uri, params)
 	if err != nil {
-		return []Action{}, fmt.Errorf("%s: %w", errUnmarshalError, err)
+		return nil, err
 	}
 	return r.Body.Services, nil
 }
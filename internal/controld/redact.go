@@ -0,0 +1,224 @@
+package controld
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DumpLevel selects which parts of each HTTP exchange are logged when
+// debugging. Dumps are redacted before they are logged.
+type DumpLevel int
+
+const (
+	// DumpNone disables dumps.
+	DumpNone DumpLevel = 0
+	// DumpHeaders logs the request line, status and headers.
+	DumpHeaders DumpLevel = 1 << (iota - 1)
+	// DumpBody logs the request line, status and bodies.
+	DumpBody
+	// DumpAll logs headers and bodies.
+	DumpAll = DumpHeaders | DumpBody
+)
+
+// redactedValue replaces masked values in dumps.
+const redactedValue = "[REDACTED]"
+
+// defaultRedactKeys are JSON keys and query parameters whose values are always
+// masked. Keys match case-insensitively, ignoring '_' and '-', and also match
+// keys ending in them, so "password" covers "new_password" and "token" covers
+// "access_token".
+var defaultRedactKeys = []string{"password", "token", "secret", "apikey", "authorization"}
+
+// sensitiveHeaders are masked in dumps. For Authorization the scheme is kept.
+var sensitiveHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key"}
+
+// redactor masks credentials in HTTP dumps.
+type redactor struct {
+	keys    []string
+	secrets []string
+}
+
+func (api *API) redactor() redactor {
+	r := redactor{keys: make([]string, 0, len(defaultRedactKeys)+len(api.redactKeys))}
+	for _, k := range append(slices.Clone(defaultRedactKeys), api.redactKeys...) {
+		if k = normalizeKey(k); k != "" {
+			r.keys = append(r.keys, k)
+		}
+	}
+	if api.APIToken != "" {
+		r.secrets = append(r.secrets, api.APIToken)
+	}
+	return r
+}
+
+func normalizeKey(key string) string {
+	return strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(key))
+}
+
+// sensitive reports whether values under key must be masked.
+func (r redactor) sensitive(key string) bool {
+	key = normalizeKey(key)
+	for _, k := range r.keys {
+		if strings.HasSuffix(key, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// text masks any known secret that appears verbatim in s.
+func (r redactor) text(s string) string {
+	for _, secret := range r.secrets {
+		s = strings.ReplaceAll(s, secret, redactedValue)
+	}
+	return s
+}
+
+// url returns u with sensitive query parameters masked.
+func (r redactor) url(u *url.URL) string {
+	masked := *u
+	if q := u.Query(); len(q) > 0 {
+		for key, values := range q {
+			if r.sensitive(key) {
+				for i := range values {
+					values[i] = redactedValue
+				}
+			}
+		}
+		masked.RawQuery = q.Encode()
+	}
+	return r.text(masked.String())
+}
+
+// header returns the headers as log attributes, sorted by name, with
+// credentials masked.
+func (r redactor) header(h http.Header) []any {
+	names := make([]string, 0, len(h))
+	for name := range h {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	attrs := make([]any, 0, len(names))
+	for _, name := range names {
+		value := strings.Join(h.Values(name), ", ")
+		if slices.Contains(sensitiveHeaders, http.CanonicalHeaderKey(name)) {
+			if scheme, _, ok := strings.Cut(value, " "); ok && strings.HasSuffix(name, "Authorization") {
+				value = scheme + " " + redactedValue
+			} else {
+				value = redactedValue
+			}
+		}
+		attrs = append(attrs, slog.String(name, r.text(value)))
+	}
+	return attrs
+}
+
+// body masks sensitive fields of a JSON body. Other bodies only have known
+// secrets masked.
+func (r redactor) body(b []byte) string {
+	if len(b) == 0 {
+		return ""
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil || dec.More() {
+		return r.text(string(b))
+	}
+	out, err := json.Marshal(r.value(v))
+	if err != nil {
+		return r.text(string(b))
+	}
+	return r.text(string(out))
+}
+
+func (r redactor) value(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, field := range v {
+			if r.sensitive(key) && field != nil {
+				v[key] = redactedValue
+			} else {
+				v[key] = r.value(field)
+			}
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = r.value(item)
+		}
+	}
+	return v
+}
+
+// dumpLevel returns the effective dump level. Debug(true) without an explicit
+// level dumps everything.
+func (api *API) dumpLevel() DumpLevel {
+	if api.dump != DumpNone {
+		return api.dump
+	}
+	if api.Debug {
+		return DumpAll
+	}
+	return DumpNone
+}
+
+func (api *API) debugLogger() *slog.Logger {
+	if api.debugLog != nil {
+		return api.debugLog
+	}
+	return slog.Default()
+}
+
+// dumpRequest logs an outgoing request at debug level.
+func (api *API) dumpRequest(ctx context.Context, level DumpLevel, r *Request) {
+	red := api.redactor()
+	attrs := []any{
+		slog.String("method", r.HTTP.Method),
+		slog.String("url", red.url(r.HTTP.URL)),
+		slog.Int("attempt", r.Attempt),
+	}
+	if level&DumpHeaders != 0 {
+		attrs = append(attrs, slog.Group("headers", red.header(r.HTTP.Header)...))
+	}
+	if level&DumpBody != 0 && len(r.Body) > 0 {
+		attrs = append(attrs, slog.String("body", red.body(r.Body)))
+	}
+	api.debugLogger().Log(ctx, slog.LevelDebug, "http request", attrs...)
+}
+
+// dumpResponse logs a response at debug level. When bodies are dumped the
+// response body is read and replaced so callers still see all of it.
+func (api *API) dumpResponse(ctx context.Context, level DumpLevel, resp *http.Response, elapsed time.Duration) error {
+	red := api.redactor()
+	attrs := []any{
+		slog.String("method", resp.Request.Method),
+		slog.String("url", red.url(resp.Request.URL)),
+		slog.Int("status", resp.StatusCode),
+		slog.Duration("duration", elapsed),
+	}
+	if level&DumpHeaders != 0 {
+		attrs = append(attrs, slog.Group("headers", red.header(resp.Header)...))
+	}
+	if level&DumpBody != 0 {
+		body, err := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		if len(body) > 0 {
+			attrs = append(attrs, slog.String("body", red.body(body)))
+		}
+	}
+	api.debugLogger().Log(ctx, slog.LevelDebug, "http response", attrs...)
+	return nil
+}
@@ -0,0 +1,117 @@
+package controld
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dumpClient returns a client whose HTTP dumps are captured as JSON log
+// records.
+func dumpClient(t *testing.T, body string, opts ...Option) (*API, func() []map[string]any) {
+	t.Helper()
+
+	api, _ := setup(t, body)
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	require.NoError(t, api.parseOptions(append([]Option{UsingDebugLogger(logger)}, opts...)...))
+
+	return api, func() []map[string]any {
+		var records []map[string]any
+		dec := json.NewDecoder(&buf)
+		for dec.More() {
+			var rec map[string]any
+			require.NoError(t, dec.Decode(&rec))
+			records = append(records, rec)
+		}
+		return records
+	}
+}
+
+func TestDumpRedactsSecrets(t *testing.T) {
+	api, records := dumpClient(t, `{"success":true,"body":{"token":"srv-secret","name":"Home"}}`,
+		Debug(true), RedactKeys("pin"))
+
+	password := "hunter2"
+	res, err := api.Raw(context.Background(), http.MethodPut, "/profiles/p1?api_key=k1&limit=5", map[string]any{
+		"name":     "Kids",
+		"password": password,
+		"settings": map[string]any{"new_password": "p2", "PIN": 1234, "note": "has test-token inside"},
+	}, http.Header{"Cookie": {"session=abc"}})
+	require.NoError(t, err)
+	assert.Contains(t, string(res.Body), "srv-secret", "callers still see the real response")
+
+	recs := records()
+	require.Len(t, recs, 2)
+
+	req := recs[0]
+	assert.Equal(t, "http request", req["msg"])
+	assert.Equal(t, "DEBUG", req["level"])
+	assert.NotContains(t, req["url"], "k1")
+	assert.Contains(t, req["url"], "limit=5")
+	headers := req["headers"].(map[string]any)
+	assert.Equal(t, "Bearer [REDACTED]", headers["Authorization"])
+	assert.Equal(t, "[REDACTED]", headers["Cookie"])
+	assert.Equal(t, "application/json", headers["Content-Type"])
+
+	var body map[string]any
+	require.NoError(t, json.Unmarshal([]byte(req["body"].(string)), &body))
+	assert.Equal(t, "Kids", body["name"])
+	assert.Equal(t, "[REDACTED]", body["password"])
+	settings := body["settings"].(map[string]any)
+	assert.Equal(t, "[REDACTED]", settings["new_password"])
+	assert.Equal(t, "[REDACTED]", settings["PIN"])
+	assert.Equal(t, "has [REDACTED] inside", settings["note"])
+
+	resp := recs[1]
+	assert.Equal(t, "http response", resp["msg"])
+	assert.EqualValues(t, 200, resp["status"])
+	assert.NotContains(t, resp["body"], "srv-secret")
+	assert.Contains(t, resp["body"], "Home")
+
+	for _, rec := range recs {
+		raw, _ := json.Marshal(rec)
+		assert.NotContains(t, string(raw), "test-token")
+		assert.NotContains(t, string(raw), password)
+	}
+}
+
+func TestDumpLevels(t *testing.T) {
+	tests := []struct {
+		level       DumpLevel
+		headers     bool
+		body        bool
+		wantRecords int
+	}{
+		{DumpNone, false, false, 0},
+		{DumpHeaders, true, false, 2},
+		{DumpBody, false, true, 2},
+		{DumpAll, true, true, 2},
+	}
+
+	for _, tt := range tests {
+		api, records := dumpClient(t, `{"success":true}`, DebugDump(tt.level))
+		_, err := api.Raw(context.Background(), http.MethodPost, "/profiles", map[string]string{"name": "x"}, nil)
+		require.NoError(t, err)
+
+		recs := records()
+		require.Len(t, recs, tt.wantRecords, "level %d", tt.level)
+		for _, rec := range recs {
+			assert.Equal(t, tt.headers, rec["headers"] != nil, "level %d headers", tt.level)
+			assert.Equal(t, tt.body, rec["body"] != nil, "level %d body", tt.level)
+		}
+	}
+}
+
+func TestRedactNonJSONBody(t *testing.T) {
+	r := redactor{keys: []string{"password"}, secrets: []string{"tok"}}
+	assert.Equal(t, "<html>[REDACTED]</html>", r.body([]byte("<html>tok</html>")))
+	assert.Equal(t, `{"password":"[REDACTED]"}`, r.body([]byte(`{"password":"x"}`)))
+	assert.Equal(t, `{"password":null}`, r.body([]byte(`{"password":null}`)))
+}
@@ -0,0 +1,113 @@
+package controld
+
+import (
+	"errors"
+	"math"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RequestStats describes how a single API call was carried out, including any
+// retries. It is passed to the hooks added with UsingStatsHook once the call
+// finishes.
+type RequestStats struct {
+	// Operation is the name of the client method, e.g. "ListProfiles", and
+	// Template the endpoint's path template. See Operation.
+	Operation string
+	Template  string
+	Method    string
+	URI       string
+	// StatusCode is the status of the last response, or zero when no
+	// response was received.
+	StatusCode int
+	// Attempts is the number of HTTP requests sent.
+	Attempts int
+	// RetryWait is the total time spent backing off between attempts.
+	RetryWait time.Duration
+	// RateLimitWait is the total time spent waiting on the client-side
+	// rate limiter.
+	RateLimitWait time.Duration
+	// Start is when the call began and Duration its total wall time.
+	Start    time.Time
+	Duration time.Duration
+	// Err is the error returned to the caller, if any.
+	Err error
+}
+
+// Retries returns the number of attempts after the first.
+func (s RequestStats) Retries() int {
+	return max(s.Attempts-1, 0)
+}
+
+// isIdempotent reports whether repeating a request with method has the same
+// effect as sending it once.
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// shouldRetry reports whether a failed attempt may be repeated. Idempotent
+// requests are retried on transport errors, 429 and 5xx responses. Other
+// requests are only retried when the server cannot have acted on them: a 429
+// response, or a connection that was never established.
+func shouldRetry(method string, resp *http.Response, err error) bool {
+	if err != nil {
+		if isIdempotent(method) {
+			return true
+		}
+		var opErr *net.OpError
+		return errors.As(err, &opErr) && opErr.Op == "dial"
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	return resp.StatusCode >= http.StatusInternalServerError && isIdempotent(method)
+}
+
+// retryableStatus reports whether a response status indicates a failure that
+// may succeed when repeated.
+func retryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+}
+
+// parseRetryAfter parses a Retry-After header, which is either a number of
+// seconds or an HTTP date. It returns zero when the header is absent or
+// invalid.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(secs)*time.Second, 0)
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return max(t.Sub(now), 0)
+	}
+	return 0
+}
+
+// retryDelay returns how long to wait before the given retry attempt. The
+// exponential backoff is capped at MaxRetryDelay and fully jittered; a
+// Retry-After value from the server takes precedence when it is longer, up to
+// MaxRetryDelay as well.
+func (api *API) retryDelay(attempt int, retryAfter time.Duration) time.Duration {
+	backoff := time.Duration(math.Pow(2, float64(attempt-1)) * float64(api.retryPolicy.MinRetryDelay))
+	if backoff > api.retryPolicy.MaxRetryDelay || backoff < 0 {
+		backoff = api.retryPolicy.MaxRetryDelay
+	}
+
+	delay := time.Duration(rand.Int64N(int64(backoff) + 1))
+	if retryAfter > delay {
+		delay = min(retryAfter, api.retryPolicy.MaxRetryDelay)
+	}
+	return delay
+}
@@ -0,0 +1,183 @@
+package controld
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyServer fails the first failures requests with status and then
+// succeeds. It returns a pointer to the number of requests received.
+func flakyServer(t *testing.T, failures, status int, header http.Header) (*httptest.Server, *int) {
+	t.Helper()
+	var count int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count++
+		if count <= failures {
+			for k, v := range header {
+				w.Header()[k] = v
+			}
+			w.WriteHeader(status)
+			return
+		}
+		_, _ = w.Write([]byte(`{"success":true,"body":{"profiles":[]}}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &count
+}
+
+func newRetryClient(t *testing.T, baseURL string, stats *RequestStats, opts ...Option) *API {
+	t.Helper()
+	api, err := New("token", append([]Option{
+		BaseURL(baseURL),
+		UsingRateLimit(1000),
+		UsingRetryPolicy(3, 0, 0),
+		UsingStatsHook(func(_ context.Context, s RequestStats) { *stats = s }),
+	}, opts...)...)
+	require.NoError(t, err)
+	return api
+}
+
+func TestRetryIdempotent(t *testing.T) {
+	srv, count := flakyServer(t, 2, http.StatusServiceUnavailable, nil)
+	var stats RequestStats
+	api := newRetryClient(t, srv.URL, &stats)
+
+	_, err := api.ListProfiles(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, *count)
+	assert.Equal(t, 3, stats.Attempts)
+	assert.Equal(t, 2, stats.Retries())
+	assert.Equal(t, http.StatusOK, stats.StatusCode)
+	assert.NoError(t, stats.Err)
+}
+
+func TestRetryPOSTOnlyWhenSafe(t *testing.T) {
+	t.Run("server error is not retried", func(t *testing.T) {
+		srv, count := flakyServer(t, 1, http.StatusBadGateway, nil)
+		var stats RequestStats
+		api := newRetryClient(t, srv.URL, &stats)
+
+		_, err := api.CreateProfile(context.Background(), CreateProfileParams{Name: "x"})
+
+		assert.Error(t, err)
+		assert.Equal(t, 1, *count)
+		assert.Equal(t, http.StatusBadGateway, stats.StatusCode)
+		assert.Error(t, stats.Err)
+	})
+
+	t.Run("rate limit is retried", func(t *testing.T) {
+		srv, count := flakyServer(t, 1, http.StatusTooManyRequests, nil)
+		var stats RequestStats
+		api := newRetryClient(t, srv.URL, &stats)
+
+		_, err := api.CreateProfile(context.Background(), CreateProfileParams{Name: "x"})
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, *count)
+	})
+
+	t.Run("body is resent on retry", func(t *testing.T) {
+		var bodies []string
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			data := make([]byte, r.ContentLength)
+			_, _ = r.Body.Read(data)
+			bodies = append(bodies, string(data))
+			if len(bodies) == 1 {
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			_, _ = w.Write([]byte(`{"success":true,"body":{"profiles":[]}}`))
+		}))
+		defer srv.Close()
+		var stats RequestStats
+		api := newRetryClient(t, srv.URL, &stats)
+
+		_, err := api.CreateProfile(context.Background(), CreateProfileParams{Name: "x"})
+
+		require.NoError(t, err)
+		require.Len(t, bodies, 2)
+		assert.Equal(t, bodies[0], bodies[1])
+		assert.Contains(t, bodies[1], `"name":"x"`)
+	})
+}
+
+func TestRetryHonorsRetryAfter(t *testing.T) {
+	srv, count := flakyServer(t, 1, http.StatusTooManyRequests, http.Header{"Retry-After": {"1"}})
+	var stats RequestStats
+	api := newRetryClient(t, srv.URL, &stats, UsingRetryPolicy(3, 0, 2))
+
+	_, err := api.ListProfiles(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, *count)
+	assert.Equal(t, time.Second, stats.RetryWait)
+	assert.GreaterOrEqual(t, stats.Duration, time.Second)
+}
+
+func TestRetryAfterTooLong(t *testing.T) {
+	srv, count := flakyServer(t, 1, http.StatusTooManyRequests, http.Header{"Retry-After": {"86400"}})
+	var stats RequestStats
+	api := newRetryClient(t, srv.URL, &stats, UsingRetryPolicy(3, 0, 2))
+
+	_, err := api.ListProfiles(context.Background())
+
+	assert.ErrorContains(t, err, "longer than the maximum retry delay")
+	assert.Equal(t, 1, *count)
+	assert.Zero(t, stats.RetryWait)
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	srv, count := flakyServer(t, 10, http.StatusInternalServerError, nil)
+	var stats RequestStats
+	api := newRetryClient(t, srv.URL, &stats)
+
+	_, err := api.ListProfiles(context.Background())
+
+	assert.ErrorContains(t, err, "HTTP 500")
+	assert.Equal(t, 4, *count)
+	assert.Equal(t, 3, stats.Retries())
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, time.Duration(0), parseRetryAfter("", now))
+	assert.Equal(t, 5*time.Second, parseRetryAfter("5", now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("-5", now))
+	assert.Equal(t, 30*time.Second, parseRetryAfter(now.Add(30*time.Second).Format(http.TimeFormat), now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter(now.Add(-time.Minute).Format(http.TimeFormat), now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("soon", now))
+}
+
+func TestRetryDelay(t *testing.T) {
+	api := &API{retryPolicy: RetryPolicy{MinRetryDelay: time.Second, MaxRetryDelay: 4 * time.Second}}
+
+	for attempt := 1; attempt <= 6; attempt++ {
+		ceiling := min(time.Duration(1<<(attempt-1))*time.Second, 4*time.Second)
+		for range 50 {
+			d := api.retryDelay(attempt, 0)
+			assert.GreaterOrEqual(t, d, time.Duration(0))
+			assert.LessOrEqual(t, d, ceiling)
+		}
+	}
+
+	assert.Equal(t, 3*time.Second, api.retryDelay(1, 3*time.Second))
+	assert.Equal(t, 4*time.Second, api.retryDelay(1, 10*time.Second))
+}
+
+func TestShouldRetry(t *testing.T) {
+	resp := func(code int) *http.Response { return &http.Response{StatusCode: code} }
+
+	assert.True(t, shouldRetry(http.MethodGet, resp(503), nil))
+	assert.True(t, shouldRetry(http.MethodPut, resp(500), nil))
+	assert.True(t, shouldRetry(http.MethodDelete, resp(429), nil))
+	assert.False(t, shouldRetry(http.MethodPost, resp(503), nil))
+	assert.True(t, shouldRetry(http.MethodPost, resp(429), nil))
+}
@@ -0,0 +1,107 @@
+package controld
+
+import (
+	"net/url"
+	"strings"
+)
+
+// route describes an API endpoint. Path segments in braces match any value.
+type route struct {
+	method    string
+	path      string
+	operation string
+}
+
+// routes lists every endpoint used by the client. Literal paths come before
+// patterns that could also match them.
+var routes = []route{
+	{"GET", "/users", "ListUser"},
+	{"GET", "/ip", "ListIP"},
+	{"GET", "/network", "ListNetwork"},
+	{"GET", "/analytics/levels", "ListLogLevels"},
+	{"GET", "/analytics/endpoints", "ListStorageRegions"},
+
+	{"GET", "/profiles", "ListProfiles"},
+	{"POST", "/profiles", "CreateProfile"},
+	{"GET", "/profiles/options", "ListProfilesOptions"},
+	{"PUT", "/profiles/{profile_id}", "UpdateProfile"},
+	{"DELETE", "/profiles/{profile_id}", "DeleteProfile"},
+	{"PUT", "/profiles/{profile_id}/options/{option}", "UpdateProfilesOption"},
+
+	{"GET", "/profiles/{profile_id}/filters", "ListProfileNativeFilters"},
+	{"GET", "/profiles/{profile_id}/filters/external", "ListProfileExternalFilters"},
+	{"PUT", "/profiles/{profile_id}/filters/filter/{filter}", "UpdateProfileFilter"},
+
+	{"GET", "/profiles/{profile_id}/services", "ListProfileServices"},
+	{"PUT", "/profiles/{profile_id}/services/{service}", "UpdateProfileService"},
+	{"GET", "/services/categories", "ListServiceCategories"},
+	{"GET", "/services/categories/{category}", "ListServices"},
+
+	{"GET", "/profiles/{profile_id}/groups", "ListProfileRuleFolders"},
+	{"POST", "/profiles/{profile_id}/groups", "CreateProfileRuleFolder"},
+	{"PUT", "/profiles/{profile_id}/groups/{folder}", "UpdateProfileRuleFolder"},
+	{"DELETE", "/profiles/{profile_id}/groups/{folder}", "DeleteProfileRuleFolder"},
+
+	{"GET", "/profiles/{profile_id}/rules/{folder}", "ListProfileCustomRules"},
+	{"POST", "/profiles/{profile_id}/rules", "CreateProfileCustomRule"},
+	{"PUT", "/profiles/{profile_id}/rules", "UpdateProfileCustomRule"},
+	{"DELETE", "/profiles/{profile_id}/rules/{hostname}", "DeleteProfileCustomRule"},
+
+	{"GET", "/profiles/{profile_id}/default", "ListProfileDefaultRule"},
+	{"PUT", "/profiles/{profile_id}/default", "UpdateProfileDefaultRule"},
+
+	{"GET", "/devices", "ListDevices"},
+	{"POST", "/devices", "CreateDevice"},
+	{"GET", "/devices/types", "ListDeviceType"},
+	{"PUT", "/devices/{device_id}", "UpdateDevice"},
+	{"DELETE", "/devices/{device_id}", "DeleteDevice"},
+
+	{"GET", "/access", "ListKnownIPs"},
+	{"POST", "/access", "LearnNewIPs"},
+	{"DELETE", "/access", "DeleteLearnedIPs"},
+}
+
+// matchRoute returns the route for a request, or false for endpoints the
+// client does not know, such as those called through Raw.
+func matchRoute(method, uri string) (route, bool) {
+	path := uri
+	if u, err := url.Parse(uri); err == nil {
+		path = u.Path
+	}
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+
+	for _, r := range routes {
+		if r.method != method {
+			continue
+		}
+		pattern := strings.Split(strings.Trim(r.path, "/"), "/")
+		if len(pattern) != len(segments) {
+			continue
+		}
+		match := true
+		for i, p := range pattern {
+			if !strings.HasPrefix(p, "{") && p != segments[i] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return r, true
+		}
+	}
+	return route{}, false
+}
+
+// Operation returns the name of the client method that calls the endpoint,
+// e.g. "ListProfiles", and the endpoint's path template. Unknown endpoints
+// are named after their method and path.
+func Operation(method, uri string) (name, template string) {
+	if r, ok := matchRoute(method, uri); ok {
+		return r.operation, r.path
+	}
+	path := uri
+	if u, err := url.Parse(uri); err == nil {
+		path = u.Path
+	}
+	return method + " " + path, path
+}
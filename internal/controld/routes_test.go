@@ -0,0 +1,30 @@
+package controld
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOperation(t *testing.T) {
+	tests := []struct {
+		method, uri    string
+		name, template string
+	}{
+		{"GET", "/profiles", "ListProfiles", "/profiles"},
+		{"GET", "/profiles/options", "ListProfilesOptions", "/profiles/options"},
+		{"PUT", "/profiles/p1", "UpdateProfile", "/profiles/{profile_id}"},
+		{"GET", "/profiles/p1/filters/external", "ListProfileExternalFilters", "/profiles/{profile_id}/filters/external"},
+		{"DELETE", "/profiles/p1/rules/example.com", "DeleteProfileCustomRule", "/profiles/{profile_id}/rules/{hostname}"},
+		{"GET", "/access?device_id=d1", "ListKnownIPs", "/access"},
+		{"GET", "/devices/types", "ListDeviceType", "/devices/types"},
+		{"PATCH", "/devices/d1", "PATCH /devices/d1", "/devices/d1"},
+		{"GET", "/unknown", "GET /unknown", "/unknown"},
+	}
+
+	for _, tt := range tests {
+		name, template := Operation(tt.method, tt.uri)
+		assert.Equal(t, tt.name, name, tt.uri)
+		assert.Equal(t, tt.template, template, tt.uri)
+	}
+}
@@ -2,7 +2,6 @@ package controld
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"net/http"
 )
@@ -24,7 +23,7 @@ type ListServiceCategoriesResponse struct {
 }
 
 type ListServicesParams struct {
-	Category string `json:"category"`
+	Category string `json:"category" url:"-"`
 }
 
 type Service struct {
@@ -47,37 +46,23 @@ type ListServicesResponse struct {
 func (api *API) ListServiceCategories(ctx context.Context) ([]Category, error) {
 	uri := buildURI("/services/categories", nil)
 
-	res, err := api.makeRequestContext(ctx, http.MethodGet, uri, nil)
+	r, err := call[ListServiceCategoriesResponse](ctx, api, http.MethodGet, uri, nil)
 	if err != nil {
-		return []Category{}, fmt.Errorf("%s: %w", errMakeRequestError, err)
-	}
-
-	var r ListServiceCategoriesResponse
-
-	err = json.Unmarshal(res, &r)
-	if err != nil {
-		return []Category{}, fmt.Errorf("%s: %w", errUnmarshalError, err)
+		return nil, err
 	}
 	return r.Body.Categories, nil
 }
 
 func (api *API) ListServices(ctx context.Context, params ListServicesParams) ([]Service, error) {
 	if params.Category == "" {
-		return []Service{}, fmt.Errorf("list: no category provided")
+		return nil, fmt.Errorf("list: no category provided")
 	}
 	baseURL := fmt.Sprintf("/services/categories/%s", params.Category)
-	uri := buildURI(baseURL, nil)
-
-	res, err := api.makeRequestContext(ctx, http.MethodGet, uri, nil)
-	if err != nil {
-		return []Service{}, fmt.Errorf("%s: %w", errMakeRequestError, err)
-	}
-
-	var r ListServicesResponse
+	uri := buildURI(baseURL, params)
 
-	err = json.Unmarshal(res, &r)
+	r, err := call[ListServicesResponse](ctx, api, http.MethodGet, uri, nil)
 	if err != nil {
-		return []Service{}, fmt.Errorf("%s: %w", errUnmarshalError, err)
+		return nil, err
 	}
 	return r.Body.Services, nil
 }
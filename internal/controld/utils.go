@@ -1,13 +1,52 @@
 package controld
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"net/url"
+	"strings"
 
 	"github.com/google/go-querystring/query"
 )
 
-// buildURI assembles the base path and queries.
-func buildURI(path string, options interface{}) string {
+// buildURI assembles the base path and queries. Fields of options are encoded
+// using their `url` struct tags; query parameters set through opts are added
+// on top.
+func buildURI(path string, options interface{}, opts ...ReqOption) string {
 	v, _ := query.Values(options)
+
+	opt := reqOption{params: url.Values{}}
+	for _, fn := range opts {
+		fn(&opt)
+	}
+	for key, values := range opt.params {
+		for _, value := range values {
+			v.Add(key, value)
+		}
+	}
+
 	return (&url.URL{Path: path, RawQuery: v.Encode()}).String()
 }
+
+// jsonScalar returns the value of a JSON string, number or boolean as text,
+// with strings unquoted. It reports false for null.
+func jsonScalar(data []byte) (string, bool, error) {
+	data = bytes.TrimSpace(data)
+	switch {
+	case len(data) == 0:
+		return "", false, errors.New("empty value")
+	case string(data) == "null":
+		return "", false, nil
+	case data[0] == '"':
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return "", false, err
+		}
+		return strings.TrimSpace(s), true, nil
+	case data[0] == '{' || data[0] == '[':
+		return "", false, errors.New("unexpected " + string(data[0]) + " in scalar value")
+	default:
+		return string(data), true, nil
+	}
+}
@@ -2,12 +2,41 @@ package debug
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"os"
+	"strings"
 )
 
 type ctxKey struct{}
 
+// Level selects what HTTP debug output includes.
+type Level string
+
+const (
+	LevelOff     Level = ""
+	LevelHeaders Level = "headers"
+	LevelBody    Level = "body"
+	LevelAll     Level = "all"
+)
+
+// ParseLevel parses a --debug value. "true" is accepted as an alias for all,
+// and "false" or "off" disable debugging.
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "off", "false", "none":
+		return LevelOff, nil
+	case "headers":
+		return LevelHeaders, nil
+	case "body":
+		return LevelBody, nil
+	case "all", "true":
+		return LevelAll, nil
+	default:
+		return LevelOff, fmt.Errorf("invalid debug level %q: must be 'headers', 'body' or 'all'", s)
+	}
+}
+
 func SetupLogger(enabled bool) {
 	level := slog.LevelInfo
 	if enabled {
@@ -18,10 +47,21 @@ func SetupLogger(enabled bool) {
 }
 
 func WithDebug(ctx context.Context, enabled bool) context.Context {
-	return context.WithValue(ctx, ctxKey{}, enabled)
+	if enabled {
+		return WithLevel(ctx, LevelAll)
+	}
+	return WithLevel(ctx, LevelOff)
 }
 
 func IsDebug(ctx context.Context) bool {
-	v, _ := ctx.Value(ctxKey{}).(bool)
+	return LevelFromContext(ctx) != LevelOff
+}
+
+func WithLevel(ctx context.Context, level Level) context.Context {
+	return context.WithValue(ctx, ctxKey{}, level)
+}
+
+func LevelFromContext(ctx context.Context) Level {
+	v, _ := ctx.Value(ctxKey{}).(Level)
 	return v
 }
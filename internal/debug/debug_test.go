@@ -34,3 +34,29 @@ func TestSetupLogger(t *testing.T) {
 		SetupLogger(false)
 	})
 }
+
+func TestParseLevel(t *testing.T) {
+	for in, want := range map[string]Level{
+		"":        LevelOff,
+		"off":     LevelOff,
+		"false":   LevelOff,
+		"headers": LevelHeaders,
+		"BODY":    LevelBody,
+		"all":     LevelAll,
+		"true":    LevelAll,
+	} {
+		got, err := ParseLevel(in)
+		assert.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+
+	_, err := ParseLevel("verbose")
+	assert.Error(t, err)
+}
+
+func TestWithLevel(t *testing.T) {
+	ctx := WithLevel(context.Background(), LevelHeaders)
+	assert.Equal(t, LevelHeaders, LevelFromContext(ctx))
+	assert.True(t, IsDebug(ctx))
+	assert.Equal(t, LevelAll, LevelFromContext(WithDebug(context.Background(), true)))
+}
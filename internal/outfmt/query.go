@@ -0,0 +1,224 @@
+package outfmt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jmespath/go-jmespath"
+)
+
+type queryKey struct{}
+type fieldsKey struct{}
+
+// WithQuery stores a JMESPath expression that Write applies to the JSON form
+// of every result before rendering it.
+func WithQuery(ctx context.Context, expr string) context.Context {
+	return context.WithValue(ctx, queryKey{}, expr)
+}
+
+// WithFields stores the columns, in order, that text, csv and tsv output
+// show. A field is either a column of the command's table or a dotted path
+// into the JSON form of each item, such as "profile.name".
+func WithFields(ctx context.Context, fields []string) context.Context {
+	return context.WithValue(ctx, fieldsKey{}, fields)
+}
+
+func getQuery(ctx context.Context) string {
+	expr, _ := ctx.Value(queryKey{}).(string)
+	return expr
+}
+
+func getFields(ctx context.Context) []string {
+	fields, _ := ctx.Value(fieldsKey{}).([]string)
+	return fields
+}
+
+// ValidateQuery reports whether expr is a valid JMESPath expression.
+func ValidateQuery(expr string) error {
+	if expr == "" {
+		return nil
+	}
+	if _, err := jmespath.Compile(expr); err != nil {
+		return fmt.Errorf("invalid query %q: %w", expr, err)
+	}
+	return nil
+}
+
+// toJSONValue returns v as decoded JSON, so queries and field paths see the
+// same names and values as json output.
+func toJSONValue(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+func applyQuery(expr string, v any) (any, error) {
+	doc, err := toJSONValue(v)
+	if err != nil {
+		return nil, err
+	}
+	result, err := jmespath.Search(expr, doc)
+	if err != nil {
+		return nil, fmt.Errorf("query %q: %w", expr, err)
+	}
+	return result, nil
+}
+
+// valueTable builds a table from decoded JSON, such as a query result. Lists
+// of objects get one column per key, objects a detail table, and scalars or
+// lists of scalars a single headerless column.
+func valueTable(doc any) *Table {
+	items, isList := doc.([]any)
+	if !isList {
+		items = []any{doc}
+	}
+
+	var keys []string
+	for _, item := range items {
+		obj, ok := item.(map[string]any)
+		if !ok {
+			keys = nil
+			break
+		}
+		for k := range obj {
+			if !slices.Contains(keys, k) {
+				keys = append(keys, k)
+			}
+		}
+	}
+
+	if keys == nil {
+		t := &Table{Columns: []Column{{Name: "value"}}, plain: true}
+		for _, item := range items {
+			if item != nil {
+				t.AddRow(formatValue(item))
+			}
+		}
+		return t
+	}
+
+	slices.Sort(keys)
+	t := &Table{Detail: !isList}
+	for _, k := range keys {
+		t.Columns = append(t.Columns, Column{Name: k})
+	}
+	for _, item := range items {
+		obj := item.(map[string]any)
+		row := make([]string, len(keys))
+		for i, k := range keys {
+			row[i] = formatValue(obj[k])
+		}
+		t.AddRow(row...)
+	}
+	return t
+}
+
+// selectFields returns a table with the given columns. Fields naming one of
+// t's columns use its cells; others are looked up in the JSON form of each
+// item of v, or of t.Items when set.
+func selectFields(v any, t *Table, fields []string) (*Table, error) {
+	if t != nil && t.Items != nil {
+		v = t.Items
+	}
+	doc, err := toJSONValue(v)
+	if err != nil {
+		return nil, err
+	}
+	items, isList := doc.([]any)
+	if !isList || (t != nil && t.Detail && t.Items == nil) {
+		items = []any{doc}
+	}
+
+	// Table cells can only be used when rows line up with items.
+	aligned := t != nil && len(t.Rows) == len(items)
+
+	out := &Table{}
+	if t != nil {
+		out.Detail = t.Detail
+		out.Empty = t.Empty
+	}
+	for range items {
+		out.Rows = append(out.Rows, make([]string, len(fields)))
+	}
+
+	for f, field := range fields {
+		out.Columns = append(out.Columns, Column{Name: field})
+		if aligned {
+			if c := t.column(field); c >= 0 {
+				out.Columns[f].Status = t.Columns[c].Status
+				for i, row := range t.Rows {
+					out.Rows[i][f] = cell(row, c)
+				}
+				continue
+			}
+		}
+
+		found := false
+		for i, item := range items {
+			if value, ok := lookup(item, field); ok {
+				out.Rows[i][f] = formatValue(value)
+				found = true
+			}
+		}
+		if !found && len(items) > 0 {
+			return nil, fmt.Errorf("unknown field %q", field)
+		}
+	}
+	return out, nil
+}
+
+// lookup follows a dotted path of object keys, matching keys
+// case-insensitively when there is no exact match.
+func lookup(doc any, path string) (any, bool) {
+	for _, key := range strings.Split(path, ".") {
+		obj, ok := doc.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		value, ok := obj[key]
+		if !ok {
+			for k, v := range obj {
+				if strings.EqualFold(k, key) {
+					value, ok = v, true
+					break
+				}
+			}
+		}
+		if !ok {
+			return nil, false
+		}
+		doc = value
+	}
+	return doc, true
+}
+
+// formatValue renders a decoded JSON value as a table cell. Objects and lists
+// are shown as compact JSON.
+func formatValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(data)
+	}
+}
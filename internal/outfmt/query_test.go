@@ -0,0 +1,101 @@
+package outfmt
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type profile struct {
+	Name string `json:"name"`
+}
+
+type device struct {
+	ID      string  `json:"device_id"`
+	Name    string  `json:"name"`
+	Status  int     `json:"status"`
+	Profile profile `json:"profile"`
+}
+
+func deviceFixture() ([]device, *Table) {
+	devices := []device{
+		{ID: "d1", Name: "Laptop", Status: 1, Profile: profile{Name: "Home"}},
+		{ID: "d2", Name: "Tablet", Status: 2, Profile: profile{Name: "Kids"}},
+	}
+	t := &Table{Columns: []Column{{Name: "device_id"}, {Name: "name"}, {Name: "status"}}}
+	t.AddRow("d1", "Laptop", "active")
+	t.AddRow("d2", "Tablet", "disabled")
+	return devices, t
+}
+
+func renderWith(t *testing.T, ctx context.Context, v any, table *Table) string {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, Write(ctx, &buf, v, table))
+	return buf.String()
+}
+
+func TestQuery(t *testing.T) {
+	devices, table := deviceFixture()
+	ctx := WithQuery(context.Background(), "[?status==`1`].name")
+
+	assert.Equal(t, "Laptop\n", renderWith(t, ctx, devices, table))
+	assert.JSONEq(t, `["Laptop"]`, renderWith(t, WithFormat(ctx, FormatJSON), devices, table))
+
+	ctx = WithQuery(context.Background(), "[].{id: device_id, profile: profile.name}")
+	assert.Equal(t, "ID  PROFILE\nd1  Home\nd2  Kids\n", renderWith(t, ctx, devices, table))
+	assert.Equal(t, "id,profile\nd1,Home\nd2,Kids\n", renderWith(t, WithFormat(ctx, FormatCSV), devices, table))
+
+	ctx = WithQuery(context.Background(), "[0]")
+	assert.Equal(t, "device_id  d1\nname       Laptop\nprofile    {\"name\":\"Home\"}\nstatus     1\n",
+		renderWith(t, ctx, devices, table))
+}
+
+func TestQueryMakesValueRender(t *testing.T) {
+	assert.False(t, IsText(WithQuery(context.Background(), "name")))
+}
+
+func TestValidateQuery(t *testing.T) {
+	assert.NoError(t, ValidateQuery(""))
+	assert.NoError(t, ValidateQuery("[].name"))
+	assert.ErrorContains(t, ValidateQuery("[?"), "invalid query")
+}
+
+func TestFields(t *testing.T) {
+	devices, table := deviceFixture()
+	ctx := WithFields(context.Background(), []string{"name", "profile.name", "STATUS"})
+
+	assert.Equal(t, "NAME    PROFILE.NAME  STATUS\nLaptop  Home          active\nTablet  Kids          disabled\n",
+		renderWith(t, ctx, devices, table))
+	assert.Equal(t, "name,profile.name,STATUS\nLaptop,Home,active\nTablet,Kids,disabled\n",
+		renderWith(t, WithFormat(ctx, FormatCSV), devices, table))
+
+	detail := &Table{Columns: []Column{{Name: "name"}}, Detail: true}
+	detail.AddRow("Laptop")
+	assert.Equal(t, "device_id  d1\nname       Laptop\n",
+		renderWith(t, WithFields(context.Background(), []string{"device_id", "name"}), devices[0], detail))
+
+	var buf bytes.Buffer
+	err := Write(WithFields(context.Background(), []string{"nope"}), &buf, devices, table)
+	assert.ErrorContains(t, err, `unknown field "nope"`)
+
+	empty := &Table{Columns: []Column{{Name: "name"}}, Empty: "none"}
+	assert.Equal(t, "none\n", renderWith(t, WithFields(context.Background(), []string{"nope"}), []device{}, empty))
+}
+
+func TestFieldsWithItems(t *testing.T) {
+	devices, _ := deviceFixture()
+	// The written value has one entry per group while the table has a row
+	// per device, as when results are grouped by account.
+	grouped := []map[string]any{{"account": "work", "devices": devices}}
+	table := &Table{Columns: []Column{{Name: "account"}, {Name: "name"}}, Items: Elements(devices)}
+	table.AddRow("work", "Laptop")
+	table.AddRow("work", "Tablet")
+
+	ctx := WithFields(context.Background(), []string{"account", "name", "profile.name"})
+	assert.Equal(t, "ACCOUNT  NAME    PROFILE.NAME\nwork     Laptop  Home\nwork     Tablet  Kids\n",
+		renderWith(t, ctx, grouped, table))
+}
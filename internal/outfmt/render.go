@@ -0,0 +1,198 @@
+package outfmt
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats accepted by --output. A template is given as
+// "template=<text/template>".
+const (
+	FormatText   = "text"
+	FormatJSON   = "json"
+	FormatYAML   = "yaml"
+	FormatCSV    = "csv"
+	FormatTSV    = "tsv"
+	FormatNDJSON = "ndjson"
+
+	templatePrefix = "template="
+)
+
+// ValidateFormat reports whether format is a supported --output value.
+func ValidateFormat(format string) error {
+	switch format {
+	case "", FormatText, FormatJSON, FormatYAML, FormatCSV, FormatTSV, FormatNDJSON:
+		return nil
+	}
+	if text, ok := strings.CutPrefix(format, templatePrefix); ok {
+		if _, err := parseTemplate(text); err != nil {
+			return fmt.Errorf("invalid output template: %w", err)
+		}
+		return nil
+	}
+	return fmt.Errorf("invalid output format %q: must be 'text', 'json', 'yaml', 'csv', 'tsv', 'ndjson' or 'template=<go template>'", format)
+}
+
+// Format returns the output format stored in ctx, defaulting to text.
+func Format(ctx context.Context) string {
+	format, _ := ctx.Value(formatKey{}).(string)
+	if format == "" {
+		return FormatText
+	}
+	return format
+}
+
+// IsText reports whether output is the human-readable text format. Commands
+// that print a confirmation instead of a value in text mode use it to decide
+// whether to render the value. A --query always renders the value.
+func IsText(ctx context.Context) bool {
+	return Format(ctx) == FormatText && getQuery(ctx) == ""
+}
+
+// Write renders v to w in the output format stored in ctx. t is the tabular
+// form of v used for text, csv and tsv output. Any query and field selection
+// in ctx are applied first.
+func Write(ctx context.Context, w io.Writer, v any, t *Table) error {
+	if expr := getQuery(ctx); expr != "" {
+		result, err := applyQuery(expr, v)
+		if err != nil {
+			return err
+		}
+		v, t = result, valueTable(result)
+	}
+	if fields := getFields(ctx); len(fields) > 0 {
+		var err error
+		if t, err = selectFields(v, t, fields); err != nil {
+			return err
+		}
+	}
+
+	opts := getTableOptions(ctx)
+	if t != nil {
+		var err error
+		if t, err = t.layout(opts); err != nil {
+			return err
+		}
+	}
+
+	format := Format(ctx)
+	if text, ok := strings.CutPrefix(format, templatePrefix); ok {
+		return writeTemplate(w, text, v)
+	}
+
+	switch format {
+	case FormatJSON:
+		return WriteJSON(w, v)
+	case FormatYAML:
+		return writeYAML(w, v)
+	case FormatNDJSON:
+		return writeNDJSON(w, v)
+	case FormatCSV:
+		return writeDelimited(w, ',', t, opts)
+	case FormatTSV:
+		return writeDelimited(w, '\t', t, opts)
+	default:
+		return writeText(ctx, w, t, opts)
+	}
+}
+
+// writeNDJSON writes each element of a slice as one compact JSON line. Other
+// values are written as a single line.
+func writeNDJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	items, ok := elements(v)
+	if !ok {
+		return enc.Encode(v)
+	}
+	for _, item := range items {
+		if err := enc.Encode(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeYAML writes v as YAML. The value goes through its JSON encoding first
+// so YAML keys, field order and value formats match the json output.
+func writeYAML(w io.Writer, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	resetStyle(&doc)
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// resetStyle drops the flow and quoting styles YAML infers from JSON input,
+// so the output uses block style and only quotes where needed.
+func resetStyle(n *yaml.Node) {
+	n.Style = 0
+	for _, c := range n.Content {
+		resetStyle(c)
+	}
+}
+
+func parseTemplate(text string) (*template.Template, error) {
+	return template.New("output").Option("missingkey=error").Parse(text)
+}
+
+// writeTemplate executes a text/template against v, or against each element
+// when v is a slice. Each execution ends with a newline.
+func writeTemplate(w io.Writer, text string, v any) error {
+	tmpl, err := parseTemplate(text)
+	if err != nil {
+		return fmt.Errorf("invalid output template: %w", err)
+	}
+	items, ok := elements(v)
+	if !ok {
+		items = []any{v}
+	}
+
+	for _, item := range items {
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, item); err != nil {
+			return fmt.Errorf("executing output template: %w", err)
+		}
+		if !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+			buf.WriteByte('\n')
+		}
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// elements returns the elements of v if it is a slice or array.
+func elements(v any) ([]any, bool) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, false
+	}
+	if rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8 {
+		return nil, false
+	}
+	items := make([]any, rv.Len())
+	for i := range items {
+		items[i] = rv.Index(i).Interface()
+	}
+	return items, true
+}
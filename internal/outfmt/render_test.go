@@ -0,0 +1,74 @@
+package outfmt
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type item struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+	Tags  []string
+}
+
+func render(t *testing.T, format string, v any, table *Table) string {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, Write(WithFormat(context.Background(), format), &buf, v, table))
+	return buf.String()
+}
+
+func TestWrite(t *testing.T) {
+	items := []item{{Name: "a", Count: 1, Tags: []string{"x"}}, {Name: "b, c", Count: 2}}
+	table := &Table{Columns: []Column{{Name: "name"}, {Name: "count"}}, Empty: "none"}
+	table.AddRow("a", "1")
+	table.AddRow("b, c", "2")
+
+	assert.Equal(t, "NAME  COUNT\na     1\nb, c  2\n", render(t, "", items, table))
+	assert.Equal(t, "name,count\na,1\n\"b, c\",2\n", render(t, FormatCSV, items, table))
+	assert.Equal(t, "name\tcount\na\t1\nb, c\t2\n", render(t, FormatTSV, items, table))
+	assert.Equal(t, "{\"name\":\"a\",\"count\":1,\"Tags\":[\"x\"]}\n{\"name\":\"b, c\",\"count\":2,\"Tags\":null}\n",
+		render(t, FormatNDJSON, items, table))
+	assert.Equal(t, "- name: a\n  count: 1\n  Tags:\n    - x\n- name: b, c\n  count: 2\n  Tags: null\n",
+		render(t, FormatYAML, items, table))
+	assert.Equal(t, "a=1\nb, c=2\n", render(t, "template={{.Name}}={{.Count}}", items, table))
+	assert.Equal(t, "a\n", render(t, "template={{.Name}}", items[0], nil))
+}
+
+func TestWriteDetail(t *testing.T) {
+	table := &Table{Columns: []Column{{Name: "name"}, {Name: "count"}}, Detail: true}
+	table.AddRow("a", "1")
+
+	assert.Equal(t, "name   a\ncount  1\n", render(t, FormatText, item{}, table))
+	assert.Equal(t, "name,count\na,1\n", render(t, FormatCSV, item{}, table))
+}
+
+func TestWriteEmpty(t *testing.T) {
+	table := &Table{Columns: []Column{{Name: "name"}}, Empty: "none"}
+	assert.Equal(t, "none\n", render(t, FormatText, []item{}, table))
+	assert.Equal(t, "name\n", render(t, FormatCSV, []item{}, table))
+	assert.Equal(t, "", render(t, FormatNDJSON, []item{}, table))
+}
+
+func TestYAMLQuotesAmbiguousStrings(t *testing.T) {
+	out := render(t, FormatYAML, map[string]string{"a": "true", "b": "123", "c": ""}, nil)
+	assert.Equal(t, "a: \"true\"\nb: \"123\"\nc: \"\"\n", out)
+}
+
+func TestValidateFormat(t *testing.T) {
+	for _, f := range []string{"", "text", "json", "yaml", "csv", "tsv", "ndjson", "template={{.Name}}"} {
+		assert.NoError(t, ValidateFormat(f), f)
+	}
+	assert.ErrorContains(t, ValidateFormat("xml"), "invalid output format")
+	assert.ErrorContains(t, ValidateFormat("template={{.Name"), "invalid output template")
+}
+
+func TestIsText(t *testing.T) {
+	assert.True(t, IsText(context.Background()))
+	assert.True(t, IsText(WithFormat(context.Background(), "text")))
+	assert.False(t, IsText(WithFormat(context.Background(), "yaml")))
+}
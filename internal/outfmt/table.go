@@ -0,0 +1,288 @@
+package outfmt
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/salmonumbrella/controld-cli/internal/ui"
+)
+
+// Column describes a table column. Names are lowercase snake_case; text output
+// upper-cases them for the header row.
+type Column struct {
+	Name string
+	// Wide columns are only shown with --wide.
+	Wide bool
+	// Status cells are colored by the state they name in text output.
+	Status bool
+}
+
+// Table is the tabular form of a value, used for text, csv and tsv output.
+// Rows hold a cell for every column, including wide ones.
+type Table struct {
+	Columns []Column
+	Rows    [][]string
+	// Detail renders the single row as one "column<TAB>value" line per
+	// column in text output, as get commands do.
+	Detail bool
+	// Empty is printed instead of a table in text output when there are no
+	// rows.
+	Empty string
+	// Items, when set, hold the value behind each row. Field selection
+	// looks paths up in them instead of in the written value, for tables
+	// whose rows are not that value's items.
+	Items []any
+
+	// plain tables hold bare values and have no header row in text output.
+	plain bool
+}
+
+// Elements returns the items of a slice, or v itself for other values: the
+// values a table built from v has a row for.
+func Elements(v any) []any {
+	if items, ok := elements(v); ok {
+		return items
+	}
+	return []any{v}
+}
+
+// AddRow appends a row to the table.
+func (t *Table) AddRow(cells ...string) {
+	t.Rows = append(t.Rows, cells)
+}
+
+// TableOptions control how tables are laid out.
+type TableOptions struct {
+	// SortBy names the column rows are sorted by.
+	SortBy string
+	// Wide shows wide columns.
+	Wide bool
+	// NoHeaders omits the header row.
+	NoHeaders bool
+	// Width is the terminal width text tables are truncated to fit, or 0 to
+	// never truncate.
+	Width int
+}
+
+type tableOptionsKey struct{}
+
+func WithTableOptions(ctx context.Context, opts TableOptions) context.Context {
+	return context.WithValue(ctx, tableOptionsKey{}, opts)
+}
+
+func getTableOptions(ctx context.Context) TableOptions {
+	opts, _ := ctx.Value(tableOptionsKey{}).(TableOptions)
+	return opts
+}
+
+const (
+	// columnGap separates text columns, matching NewTabWriter's padding.
+	columnGap = 2
+	// minColumnWidth is the narrowest a column is truncated to.
+	minColumnWidth = 8
+)
+
+// layout sorts t's rows and drops the columns opts hide. The returned table
+// is a copy.
+func (t *Table) layout(opts TableOptions) (*Table, error) {
+	out := *t
+	out.Rows = slices.Clone(t.Rows)
+
+	if opts.SortBy != "" && !t.Detail {
+		c := t.column(opts.SortBy)
+		if c < 0 {
+			return nil, fmt.Errorf("unknown sort column %q", opts.SortBy)
+		}
+		slices.SortStableFunc(out.Rows, func(a, b []string) int {
+			return compareCells(cell(a, c), cell(b, c))
+		})
+	}
+
+	if opts.Wide || !slices.ContainsFunc(t.Columns, func(c Column) bool { return c.Wide }) {
+		return &out, nil
+	}
+	out.Columns = nil
+	var keep []int
+	for i, c := range t.Columns {
+		if !c.Wide {
+			out.Columns = append(out.Columns, c)
+			keep = append(keep, i)
+		}
+	}
+	for r, row := range out.Rows {
+		cells := make([]string, len(keep))
+		for i, c := range keep {
+			cells[i] = cell(row, c)
+		}
+		out.Rows[r] = cells
+	}
+	return &out, nil
+}
+
+// column returns the index of the named column, ignoring case, or -1.
+func (t *Table) column(name string) int {
+	return slices.IndexFunc(t.Columns, func(c Column) bool { return strings.EqualFold(c.Name, name) })
+}
+
+// compareCells orders numbers numerically and everything else
+// case-insensitively.
+func compareCells(a, b string) int {
+	x, errX := strconv.ParseFloat(a, 64)
+	y, errY := strconv.ParseFloat(b, 64)
+	if errX == nil && errY == nil {
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+}
+
+func writeText(ctx context.Context, w io.Writer, t *Table, opts TableOptions) error {
+	if t == nil {
+		return nil
+	}
+	if len(t.Rows) == 0 && t.Empty != "" {
+		_, err := fmt.Fprintln(w, t.Empty)
+		return err
+	}
+	u := ui.FromContext(ctx)
+
+	var lines [][]string
+	var status []bool
+	switch {
+	case t.plain:
+		lines = t.Rows
+		status = make([]bool, len(t.Columns))
+	case t.Detail:
+		for _, row := range t.Rows {
+			for i, col := range t.Columns {
+				lines = append(lines, []string{col.Name, cell(row, i)})
+			}
+		}
+		status = []bool{false, false}
+	default:
+		if !opts.NoHeaders {
+			headers := make([]string, len(t.Columns))
+			for i, col := range t.Columns {
+				headers[i] = strings.ToUpper(col.Name)
+			}
+			lines = append(lines, headers)
+		}
+		for _, row := range t.Rows {
+			cells := make([]string, len(t.Columns))
+			for i := range t.Columns {
+				cells[i] = cell(row, i)
+			}
+			lines = append(lines, cells)
+		}
+		status = make([]bool, len(t.Columns))
+		for i, col := range t.Columns {
+			status[i] = col.Status
+		}
+	}
+
+	widths := make([]int, len(status))
+	for _, line := range lines {
+		for i := range widths {
+			widths[i] = max(widths[i], utf8.RuneCountInString(cell(line, i)))
+		}
+	}
+	if opts.Width > 0 {
+		fit(widths, opts.Width)
+	}
+
+	headerRow := !t.plain && !t.Detail && !opts.NoHeaders
+	var b strings.Builder
+	for n, line := range lines {
+		for i, width := range widths {
+			s := truncate(cell(line, i), width)
+			padding := width - utf8.RuneCountInString(s)
+			if status[i] && !(headerRow && n == 0) {
+				s = u.Status(s)
+			}
+			b.WriteString(s)
+			if i < len(widths)-1 {
+				b.WriteString(strings.Repeat(" ", padding+columnGap))
+			}
+		}
+		b.WriteByte('\n')
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// fit narrows the widest columns until the row fits in width or every column
+// is at most minColumnWidth wide.
+func fit(widths []int, width int) {
+	total := columnGap * (len(widths) - 1)
+	for _, w := range widths {
+		total += w
+	}
+	for total > width {
+		widest := 0
+		for i, w := range widths {
+			if w > widths[widest] {
+				widest = i
+			}
+		}
+		if widths[widest] <= minColumnWidth {
+			return
+		}
+		widths[widest]--
+		total--
+	}
+}
+
+// truncate shortens s to width runes, marking the cut with an ellipsis.
+func truncate(s string, width int) string {
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:width-1]) + "…"
+}
+
+func writeDelimited(w io.Writer, sep rune, t *Table, opts TableOptions) error {
+	if t == nil {
+		return fmt.Errorf("this command has no tabular output")
+	}
+	cw := csv.NewWriter(w)
+	cw.Comma = sep
+	if !opts.NoHeaders {
+		headers := make([]string, len(t.Columns))
+		for i, col := range t.Columns {
+			headers[i] = col.Name
+		}
+		if err := cw.Write(headers); err != nil {
+			return err
+		}
+	}
+	for _, row := range t.Rows {
+		cells := make([]string, len(t.Columns))
+		for i := range t.Columns {
+			cells[i] = cell(row, i)
+		}
+		if err := cw.Write(cells); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func cell(row []string, i int) string {
+	if i < len(row) {
+		return row[i]
+	}
+	return ""
+}
@@ -0,0 +1,80 @@
+package outfmt
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/salmonumbrella/controld-cli/internal/ui"
+)
+
+func tableFixture() *Table {
+	t := &Table{Columns: []Column{
+		{Name: "name"},
+		{Name: "count"},
+		{Name: "status", Status: true},
+		{Name: "note", Wide: true},
+	}}
+	t.AddRow("tablet", "10", "active", "kids")
+	t.AddRow("Laptop", "9", "disabled", "work")
+	t.AddRow("phone", "100", "pending", "")
+	return t
+}
+
+func renderTable(t *testing.T, opts TableOptions, format string, table *Table) string {
+	t.Helper()
+	ctx := WithTableOptions(WithFormat(context.Background(), format), opts)
+	var buf bytes.Buffer
+	require.NoError(t, Write(ctx, &buf, nil, table))
+	return buf.String()
+}
+
+func TestTableLayout(t *testing.T) {
+	assert.Equal(t,
+		"NAME    COUNT  STATUS\ntablet  10     active\nLaptop  9      disabled\nphone   100    pending\n",
+		renderTable(t, TableOptions{}, FormatText, tableFixture()))
+
+	assert.Equal(t,
+		"NAME    COUNT  STATUS    NOTE\ntablet  10     active    kids\nLaptop  9      disabled  work\nphone   100    pending   \n",
+		renderTable(t, TableOptions{Wide: true}, FormatText, tableFixture()))
+
+	assert.Equal(t,
+		"Laptop,9,disabled\nphone,100,pending\ntablet,10,active\n",
+		renderTable(t, TableOptions{SortBy: "NAME", NoHeaders: true}, FormatCSV, tableFixture()))
+
+	assert.Equal(t,
+		"Laptop  9    disabled\ntablet  10   active\nphone   100  pending\n",
+		renderTable(t, TableOptions{SortBy: "count", NoHeaders: true}, FormatText, tableFixture()))
+}
+
+func TestTableSortUnknownColumn(t *testing.T) {
+	ctx := WithTableOptions(context.Background(), TableOptions{SortBy: "nope"})
+	err := Write(ctx, &bytes.Buffer{}, nil, tableFixture())
+	assert.ErrorContains(t, err, `unknown sort column "nope"`)
+}
+
+func TestTableTruncatesToWidth(t *testing.T) {
+	table := &Table{Columns: []Column{{Name: "id"}, {Name: "description"}}}
+	table.AddRow("1", "a rather long description that does not fit")
+
+	out := renderTable(t, TableOptions{Width: 30}, FormatText, table)
+	assert.Equal(t, "ID  DESCRIPTION\n1   a rather long description…\n", out)
+
+	// CSV is never truncated.
+	out = renderTable(t, TableOptions{Width: 30}, FormatCSV, table)
+	assert.Contains(t, out, "does not fit")
+}
+
+func TestTableColorsStatus(t *testing.T) {
+	ctx := ui.WithUI(context.Background(), ui.New("always"))
+	var buf bytes.Buffer
+	require.NoError(t, Write(ctx, &buf, nil, tableFixture()))
+
+	out := buf.String()
+	assert.Contains(t, out, "STATUS\n", "headers are not colored")
+	assert.Contains(t, out, "\033[32mactive\033[0m\n")
+	assert.Contains(t, out, "9      \033[31mdisabled\033[0m\n", "padding ignores escape codes")
+}
@@ -0,0 +1,127 @@
+package schedule
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/salmonumbrella/controld-cli/internal/config"
+	"github.com/salmonumbrella/controld-cli/internal/controld"
+)
+
+const fileName = "schedule.json"
+
+// Entry records a device status change made from the CLI, and the status the
+// device should return to once Until has passed.
+type Entry struct {
+	DeviceID  string                `json:"device_id"`
+	Name      string                `json:"name"`
+	Status    controld.DeviceStatus `json:"status"`
+	Restore   controld.DeviceStatus `json:"restore"`
+	Until     time.Time             `json:"until,omitzero"`
+	Reason    string                `json:"reason,omitempty"`
+	CreatedAt time.Time             `json:"created_at"`
+}
+
+// Due reports whether the entry has an expiry that has passed.
+func (e Entry) Due(now time.Time) bool {
+	return !e.Until.IsZero() && !now.Before(e.Until)
+}
+
+// Schedule is the on-disk list of scheduled device status changes. It is keyed
+// by device ID; there is at most one entry per device.
+type Schedule struct {
+	path    string
+	entries map[string]Entry
+}
+
+// Open loads the schedule from the CLI config directory. A missing file is
+// treated as an empty schedule.
+func Open() (*Schedule, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config directory: %w", err)
+	}
+	return Load(filepath.Join(dir, fileName))
+}
+
+// Load reads a schedule from path.
+func Load(path string) (*Schedule, error) {
+	s := &Schedule{path: path, entries: make(map[string]Entry)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("invalid schedule file %s: %w", path, err)
+	}
+	for _, e := range entries {
+		s.entries[e.DeviceID] = e
+	}
+	return s, nil
+}
+
+// Put adds or replaces the entry for e.DeviceID.
+func (s *Schedule) Put(e Entry) {
+	s.entries[e.DeviceID] = e
+}
+
+// Get returns the entry for deviceID, if any.
+func (s *Schedule) Get(deviceID string) (Entry, bool) {
+	e, ok := s.entries[deviceID]
+	return e, ok
+}
+
+// Remove deletes the entry for deviceID.
+func (s *Schedule) Remove(deviceID string) {
+	delete(s.entries, deviceID)
+}
+
+// Entries returns all entries ordered by expiry, with open-ended entries last.
+func (s *Schedule) Entries() []Entry {
+	out := make([]Entry, 0, len(s.entries))
+	for _, e := range s.entries {
+		out = append(out, e)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.Until.IsZero() != b.Until.IsZero() {
+			return b.Until.IsZero()
+		}
+		if !a.Until.Equal(b.Until) {
+			return a.Until.Before(b.Until)
+		}
+		return a.DeviceID < b.DeviceID
+	})
+	return out
+}
+
+// Due returns the entries whose expiry has passed.
+func (s *Schedule) Due(now time.Time) []Entry {
+	var out []Entry
+	for _, e := range s.Entries() {
+		if e.Due(now) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// Save writes the schedule back to disk.
+func (s *Schedule) Save() error {
+	data, err := json.MarshalIndent(s.Entries(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, data, 0600)
+}
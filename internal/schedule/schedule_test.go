@@ -0,0 +1,106 @@
+package schedule
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/salmonumbrella/controld-cli/internal/controld"
+)
+
+func TestLoadMissingFile(t *testing.T) {
+	s, err := Load(filepath.Join(t.TempDir(), "schedule.json"))
+
+	require.NoError(t, err)
+	assert.Empty(t, s.Entries())
+}
+
+func TestLoadInvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schedule.json")
+	require.NoError(t, os.WriteFile(path, []byte("{not json"), 0600))
+
+	_, err := Load(path)
+
+	assert.Error(t, err)
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schedule.json")
+	until := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	s, err := Load(path)
+	require.NoError(t, err)
+	s.Put(Entry{
+		DeviceID: "dev1",
+		Name:     "Laptop",
+		Status:   controld.SoftDisabled,
+		Restore:  controld.Active,
+		Until:    until,
+		Reason:   "homework",
+	})
+	require.NoError(t, s.Save())
+
+	loaded, err := Load(path)
+	require.NoError(t, err)
+
+	e, ok := loaded.Get("dev1")
+	require.True(t, ok)
+	assert.Equal(t, "Laptop", e.Name)
+	assert.Equal(t, controld.DeviceStatus(controld.SoftDisabled), e.Status)
+	assert.Equal(t, controld.DeviceStatus(controld.Active), e.Restore)
+	assert.True(t, until.Equal(e.Until))
+	assert.Equal(t, "homework", e.Reason)
+}
+
+func TestPutReplaces(t *testing.T) {
+	s, err := Load(filepath.Join(t.TempDir(), "schedule.json"))
+	require.NoError(t, err)
+
+	s.Put(Entry{DeviceID: "dev1", Reason: "first"})
+	s.Put(Entry{DeviceID: "dev1", Reason: "second"})
+
+	entries := s.Entries()
+	require.Len(t, entries, 1)
+	assert.Equal(t, "second", entries[0].Reason)
+
+	s.Remove("dev1")
+	assert.Empty(t, s.Entries())
+}
+
+func TestDue(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	s, err := Load(filepath.Join(t.TempDir(), "schedule.json"))
+	require.NoError(t, err)
+	s.Put(Entry{DeviceID: "past", Until: now.Add(-time.Hour)})
+	s.Put(Entry{DeviceID: "now", Until: now})
+	s.Put(Entry{DeviceID: "future", Until: now.Add(time.Hour)})
+	s.Put(Entry{DeviceID: "open"})
+
+	due := s.Due(now)
+
+	require.Len(t, due, 2)
+	assert.Equal(t, "past", due[0].DeviceID)
+	assert.Equal(t, "now", due[1].DeviceID)
+}
+
+func TestEntriesOrder(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	s, err := Load(filepath.Join(t.TempDir(), "schedule.json"))
+	require.NoError(t, err)
+	s.Put(Entry{DeviceID: "open"})
+	s.Put(Entry{DeviceID: "later", Until: now.Add(2 * time.Hour)})
+	s.Put(Entry{DeviceID: "sooner", Until: now.Add(time.Hour)})
+
+	var ids []string
+	for _, e := range s.Entries() {
+		ids = append(ids, e.DeviceID)
+	}
+
+	assert.Equal(t, []string{"sooner", "later", "open"}, ids)
+}
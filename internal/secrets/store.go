@@ -3,8 +3,6 @@ package secrets
 import (
 	"encoding/json"
 	"fmt"
-	"os"
-	"path/filepath"
 	"strings"
 	"time"
 
@@ -15,6 +13,9 @@ import (
 
 type Store interface {
 	Set(name string, token string) error
+	// Save stores creds under creds.Name, replacing any existing entry in a
+	// single write.
+	Save(creds Credentials) error
 	Get(name string) (Credentials, error)
 	Delete(name string) error
 	List() ([]Credentials, error)
@@ -25,11 +26,27 @@ type Credentials struct {
 	Name      string    `json:"name"`
 	Token     string    `json:"-"`
 	CreatedAt time.Time `json:"created_at"`
+	// Email and AccountPK identify the ControlD user the token belongs to,
+	// as reported by the API when the token was stored.
+	Email     string `json:"email,omitempty"`
+	AccountPK string `json:"account_pk,omitempty"`
+	// Label is the token's name in the ControlD dashboard.
+	Label string `json:"label,omitempty"`
+	// Scope is "read" or "write", when known.
+	Scope string `json:"scope,omitempty"`
+	// ExpiresAt is when the token stops working, if it was created with an
+	// expiry.
+	ExpiresAt time.Time `json:"expires_at,omitzero"`
 }
 
 type storedCredentials struct {
 	Token     string    `json:"token"`
 	CreatedAt time.Time `json:"created_at"`
+	Email     string    `json:"email,omitempty"`
+	AccountPK string    `json:"account_pk,omitempty"`
+	Label     string    `json:"label,omitempty"`
+	Scope     string    `json:"scope,omitempty"`
+	ExpiresAt time.Time `json:"expires_at,omitzero"`
 }
 
 type KeyringStore struct {
@@ -37,7 +54,7 @@ type KeyringStore struct {
 }
 
 func OpenDefault() (Store, error) {
-	configDir, err := getConfigDir()
+	configDir, err := config.Dir()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get config directory: %w", err)
 	}
@@ -60,25 +77,9 @@ func OpenDefault() (Store, error) {
 	return &KeyringStore{ring: ring}, nil
 }
 
-func getConfigDir() (string, error) {
-	// Use XDG_CONFIG_HOME if set, otherwise ~/.config
-	configHome := os.Getenv("XDG_CONFIG_HOME")
-	if configHome == "" {
-		home, err := os.UserHomeDir()
-		if err != nil {
-			return "", err
-		}
-		configHome = filepath.Join(home, ".config")
-	}
-
-	configDir := filepath.Join(configHome, config.AppName)
-
-	// Ensure the directory exists
-	if err := os.MkdirAll(configDir, 0700); err != nil {
-		return "", err
-	}
-
-	return configDir, nil
+// NewKeyringStore returns a store backed by ring.
+func NewKeyringStore(ring keyring.Keyring) *KeyringStore {
+	return &KeyringStore{ring: ring}
 }
 
 func (s *KeyringStore) Keys() ([]string, error) {
@@ -86,17 +87,30 @@ func (s *KeyringStore) Keys() ([]string, error) {
 }
 
 func (s *KeyringStore) Set(name string, token string) error {
-	name = normalize(name)
+	return s.Save(Credentials{Name: name, Token: token})
+}
+
+// Save stores creds, stamping CreatedAt with the current time if it is zero.
+func (s *KeyringStore) Save(creds Credentials) error {
+	name := normalize(creds.Name)
 	if name == "" {
 		return fmt.Errorf("missing account name")
 	}
-	if token == "" {
+	if creds.Token == "" {
 		return fmt.Errorf("missing token")
 	}
+	if creds.CreatedAt.IsZero() {
+		creds.CreatedAt = time.Now().UTC()
+	}
 
 	payload, err := json.Marshal(storedCredentials{
-		Token:     token,
-		CreatedAt: time.Now().UTC(),
+		Token:     creds.Token,
+		CreatedAt: creds.CreatedAt,
+		Email:     creds.Email,
+		AccountPK: creds.AccountPK,
+		Label:     creds.Label,
+		Scope:     creds.Scope,
+		ExpiresAt: creds.ExpiresAt,
 	})
 	if err != nil {
 		return err
@@ -128,6 +142,11 @@ func (s *KeyringStore) Get(name string) (Credentials, error) {
 		Name:      name,
 		Token:     stored.Token,
 		CreatedAt: stored.CreatedAt,
+		Email:     stored.Email,
+		AccountPK: stored.AccountPK,
+		Label:     stored.Label,
+		Scope:     stored.Scope,
+		ExpiresAt: stored.ExpiresAt,
 	}, nil
 }
 
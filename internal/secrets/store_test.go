@@ -2,8 +2,11 @@ package secrets
 
 import (
 	"testing"
+	"time"
 
+	"github.com/99designs/keyring"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCredentialKey(t *testing.T) {
@@ -70,3 +73,35 @@ func TestNormalize(t *testing.T) {
 		})
 	}
 }
+
+func TestSaveMetadata(t *testing.T) {
+	store := NewKeyringStore(keyring.NewArrayKeyring(nil))
+	expires := time.Date(2027, 1, 31, 0, 0, 0, 0, time.UTC)
+
+	require.NoError(t, store.Save(Credentials{
+		Name:      "Work",
+		Token:     "api.secret",
+		Email:     "user@example.com",
+		AccountPK: "user00001",
+		Label:     "laptop",
+		Scope:     "write",
+		ExpiresAt: expires,
+	}))
+
+	creds, err := store.Get("work")
+	require.NoError(t, err)
+	assert.Equal(t, "api.secret", creds.Token)
+	assert.Equal(t, "user@example.com", creds.Email)
+	assert.Equal(t, "user00001", creds.AccountPK)
+	assert.Equal(t, "laptop", creds.Label)
+	assert.Equal(t, "write", creds.Scope)
+	assert.True(t, creds.ExpiresAt.Equal(expires))
+	assert.False(t, creds.CreatedAt.IsZero())
+
+	// Set replaces the entry, metadata included.
+	require.NoError(t, store.Set("work", "api.other"))
+	creds, err = store.Get("work")
+	require.NoError(t, err)
+	assert.Equal(t, "api.other", creds.Token)
+	assert.Empty(t, creds.Email)
+}
@@ -0,0 +1,235 @@
+// Package telemetry exports OpenTelemetry traces and metrics for ControlD API
+// calls made by the CLI.
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/salmonumbrella/controld-cli/internal/config"
+	"github.com/salmonumbrella/controld-cli/internal/controld"
+)
+
+const (
+	instrumentationName = "github.com/salmonumbrella/controld-cli/internal/controld"
+	serviceName         = config.AppName
+)
+
+// endpointEnv lists the standard variables that enable OTLP export.
+var endpointEnv = []string{
+	"OTEL_EXPORTER_OTLP_ENDPOINT",
+	"OTEL_EXPORTER_OTLP_TRACES_ENDPOINT",
+	"OTEL_EXPORTER_OTLP_METRICS_ENDPOINT",
+}
+
+// Config configures export.
+type Config struct {
+	// Endpoint is the base URL of an OTLP/HTTP collector, e.g.
+	// http://localhost:4318. When empty the standard OTEL_* variables are
+	// used.
+	Endpoint string
+	// Version is reported as the service version.
+	Version string
+}
+
+// Enabled reports whether telemetry should be exported: an endpoint was given
+// explicitly, or one is configured through the environment and the SDK has not
+// been disabled with OTEL_SDK_DISABLED.
+func Enabled(endpoint string) bool {
+	if endpoint != "" {
+		return true
+	}
+	if strings.EqualFold(os.Getenv("OTEL_SDK_DISABLED"), "true") {
+		return false
+	}
+	for _, key := range endpointEnv {
+		if os.Getenv(key) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Provider records API calls as spans and metrics.
+type Provider struct {
+	tracer   trace.Tracer
+	meter    metric.Meter
+	shutdown []func(context.Context) error
+}
+
+// New returns a provider that records to the given tracer and meter
+// providers. The caller owns the providers.
+func New(tp trace.TracerProvider, mp metric.MeterProvider) *Provider {
+	return &Provider{
+		tracer: tp.Tracer(instrumentationName),
+		meter:  mp.Meter(instrumentationName),
+	}
+}
+
+// Setup creates OTLP/HTTP exporters for traces and metrics. Call Shutdown to
+// flush them before the process exits.
+func Setup(ctx context.Context, cfg Config) (*Provider, error) {
+	var traceOpts []otlptracehttp.Option
+	var metricOpts []otlpmetrichttp.Option
+	if cfg.Endpoint != "" {
+		base := strings.TrimSuffix(cfg.Endpoint, "/")
+		traceOpts = append(traceOpts, otlptracehttp.WithEndpointURL(base+"/v1/traces"))
+		metricOpts = append(metricOpts, otlpmetrichttp.WithEndpointURL(base+"/v1/metrics"))
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithAttributes(
+			attribute.String("service.name", serviceName),
+			attribute.String("service.version", cfg.Version),
+		),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	traceExporter, err := otlptracehttp.New(ctx, traceOpts...)
+	if err != nil {
+		return nil, err
+	}
+	metricExporter, err := otlpmetrichttp.New(ctx, metricOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(traceExporter),
+		sdktrace.WithResource(res),
+	)
+	mp := sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(metricExporter)),
+		sdkmetric.WithResource(res),
+	)
+
+	p := New(tp, mp)
+	p.shutdown = []func(context.Context) error{tp.Shutdown, mp.Shutdown}
+	return p, nil
+}
+
+// Shutdown flushes and stops the exporters created by Setup.
+func (p *Provider) Shutdown(ctx context.Context) error {
+	var errs []error
+	for _, fn := range p.shutdown {
+		errs = append(errs, fn(ctx))
+	}
+	return errors.Join(errs...)
+}
+
+// ClientOptions returns API client options that record every call.
+func (p *Provider) ClientOptions() ([]controld.Option, error) {
+	requests, err := p.meter.Int64Counter("controld.client.requests",
+		metric.WithDescription("Number of ControlD API calls"),
+		metric.WithUnit("{call}"))
+	if err != nil {
+		return nil, err
+	}
+	duration, err := p.meter.Float64Histogram("controld.client.duration",
+		metric.WithDescription("Duration of ControlD API calls, including retries"),
+		metric.WithUnit("s"))
+	if err != nil {
+		return nil, err
+	}
+	retries, err := p.meter.Int64Counter("controld.client.retries",
+		metric.WithDescription("Number of retried ControlD API requests"),
+		metric.WithUnit("{retry}"))
+	if err != nil {
+		return nil, err
+	}
+	rateLimitWait, err := p.meter.Float64Histogram("controld.client.rate_limit_wait",
+		metric.WithDescription("Time spent waiting on the client-side rate limiter"),
+		metric.WithUnit("s"))
+	if err != nil {
+		return nil, err
+	}
+
+	hook := func(ctx context.Context, s controld.RequestStats) {
+		attrs := []attribute.KeyValue{
+			attribute.String("controld.operation", s.Operation),
+			attribute.String("http.request.method", s.Method),
+			attribute.String("url.template", s.Template),
+		}
+		if s.StatusCode != 0 {
+			attrs = append(attrs, attribute.Int("http.response.status_code", s.StatusCode))
+		}
+		if s.Err != nil {
+			attrs = append(attrs, attribute.String("error.type", errorType(s)))
+		}
+
+		_, span := p.tracer.Start(ctx, s.Operation,
+			trace.WithTimestamp(s.Start),
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(attrs...),
+			trace.WithAttributes(
+				attribute.Int("controld.attempts", s.Attempts),
+				attribute.Int("controld.retries", s.Retries()),
+				attribute.Int64("controld.retry_wait_ms", s.RetryWait.Milliseconds()),
+				attribute.Int64("controld.rate_limit_wait_ms", s.RateLimitWait.Milliseconds()),
+			),
+		)
+		if s.Err != nil {
+			span.RecordError(s.Err)
+			span.SetStatus(codes.Error, s.Err.Error())
+		}
+		span.End(trace.WithTimestamp(s.Start.Add(s.Duration)))
+
+		set := metric.WithAttributes(attrs...)
+		requests.Add(ctx, 1, set)
+		duration.Record(ctx, s.Duration.Seconds(), set)
+		rateLimitWait.Record(ctx, s.RateLimitWait.Seconds(), set)
+		if n := s.Retries(); n > 0 {
+			retries.Add(ctx, int64(n), set)
+		}
+	}
+
+	return []controld.Option{controld.UsingStatsHook(hook)}, nil
+}
+
+// errorType classifies a failed call by the API's error code, as an
+// unexpected response when the reply was not the API's JSON, by its status
+// code otherwise, or as a transport error when no response was received.
+func errorType(s controld.RequestStats) string {
+	var apiErr interface{ Code() int }
+	var unexpected *controld.UnexpectedResponseError
+	switch {
+	case errors.As(s.Err, &unexpected):
+		return "unexpected_response"
+	case errors.As(s.Err, &apiErr):
+		return strconv.Itoa(apiErr.Code())
+	case s.StatusCode != 0:
+		return strconv.Itoa(s.StatusCode)
+	default:
+		return "transport"
+	}
+}
+
+type ctxKey struct{}
+
+// WithProvider returns a context carrying p.
+func WithProvider(ctx context.Context, p *Provider) context.Context {
+	return context.WithValue(ctx, ctxKey{}, p)
+}
+
+// FromContext returns the provider stored in ctx, or nil.
+func FromContext(ctx context.Context) *Provider {
+	p, _ := ctx.Value(ctxKey{}).(*Provider)
+	return p
+}
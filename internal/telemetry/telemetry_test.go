@@ -0,0 +1,183 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/salmonumbrella/controld-cli/internal/controld"
+	"github.com/salmonumbrella/controld-cli/internal/controld/controldsim"
+	"github.com/salmonumbrella/controld-cli/internal/controld/controldtest"
+)
+
+func setup(t *testing.T) (*controldtest.Server, *controld.API, *tracetest.InMemoryExporter, *sdkmetric.ManualReader) {
+	t.Helper()
+
+	spans := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(spans))
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	opts, err := New(tp, mp).ClientOptions()
+	require.NoError(t, err)
+
+	srv := controldtest.NewServer(t)
+	return srv, srv.NewClient(t, opts...), spans, reader
+}
+
+func attrs(kvs []attribute.KeyValue) map[string]any {
+	m := make(map[string]any, len(kvs))
+	for _, kv := range kvs {
+		m[string(kv.Key)] = kv.Value.AsInterface()
+	}
+	return m
+}
+
+func TestSpans(t *testing.T) {
+	srv, client, spans, _ := setup(t)
+	srv.Inject(controldsim.Fault{Path: "/profiles", Status: http.StatusServiceUnavailable, Times: 1})
+
+	_, err := client.ListProfiles(context.Background())
+	require.NoError(t, err)
+	_, err = client.ListProfileServices(context.Background(), controld.ListProfileServicesParams{ProfileID: "missing"})
+	require.Error(t, err)
+
+	got := spans.GetSpans()
+	require.Len(t, got, 2)
+
+	list := got[0]
+	assert.Equal(t, "ListProfiles", list.Name)
+	assert.Equal(t, codes.Unset, list.Status.Code)
+	a := attrs(list.Attributes)
+	assert.Equal(t, "GET", a["http.request.method"])
+	assert.Equal(t, "/profiles", a["url.template"])
+	assert.Equal(t, int64(200), a["http.response.status_code"])
+	assert.Equal(t, int64(1), a["controld.retries"])
+	assert.Equal(t, int64(2), a["controld.attempts"])
+	assert.Contains(t, a, "controld.rate_limit_wait_ms")
+
+	failed := got[1]
+	assert.Equal(t, "ListProfileServices", failed.Name)
+	assert.Equal(t, codes.Error, failed.Status.Code)
+	a = attrs(failed.Attributes)
+	assert.Equal(t, "/profiles/{profile_id}/services", a["url.template"])
+	assert.Equal(t, "404", a["error.type"])
+}
+
+func TestErrorType(t *testing.T) {
+	unsuccessful := controld.NewRequestError(&controld.Error{StatusCode: http.StatusOK, Error: controld.ResponseInfo{Code: 40001}})
+	assert.Equal(t, "40001", errorType(controld.RequestStats{StatusCode: http.StatusOK, Err: unsuccessful}))
+	notFound := controld.NewNotFoundError(&controld.Error{StatusCode: http.StatusNotFound})
+	assert.Equal(t, "404", errorType(controld.RequestStats{StatusCode: http.StatusNotFound, Err: &notFound}))
+	unexpected := &controld.UnexpectedResponseError{StatusCode: http.StatusOK}
+	assert.Equal(t, "unexpected_response", errorType(controld.RequestStats{StatusCode: http.StatusOK, Err: unexpected}))
+	assert.Equal(t, "transport", errorType(controld.RequestStats{Err: errors.New("connection refused")}))
+}
+
+func TestSpanParent(t *testing.T) {
+	_, client, spans, _ := setup(t)
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(spans))
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "command")
+
+	_, err := client.ListDevices(ctx)
+	require.NoError(t, err)
+	parent.End()
+
+	got := spans.GetSpans()
+	require.Len(t, got, 2)
+	assert.Equal(t, "ListDevices", got[0].Name)
+	assert.Equal(t, parent.SpanContext().SpanID(), got[0].Parent.SpanID())
+}
+
+func TestMetrics(t *testing.T) {
+	srv, client, _, reader := setup(t)
+	srv.Inject(controldsim.Fault{Path: "/devices", Status: http.StatusServiceUnavailable, Times: 1})
+
+	_, err := client.ListDevices(context.Background())
+	require.NoError(t, err)
+	_, err = client.ListDevices(context.Background())
+	require.NoError(t, err)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+
+	metrics := map[string]metricdata.Aggregation{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			metrics[m.Name] = m.Data
+		}
+	}
+
+	requests := metrics["controld.client.requests"].(metricdata.Sum[int64])
+	require.Len(t, requests.DataPoints, 1)
+	assert.Equal(t, int64(2), requests.DataPoints[0].Value)
+	op, _ := requests.DataPoints[0].Attributes.Value("controld.operation")
+	assert.Equal(t, "ListDevices", op.AsString())
+
+	retries := metrics["controld.client.retries"].(metricdata.Sum[int64])
+	require.Len(t, retries.DataPoints, 1)
+	assert.Equal(t, int64(1), retries.DataPoints[0].Value)
+
+	duration := metrics["controld.client.duration"].(metricdata.Histogram[float64])
+	require.Len(t, duration.DataPoints, 1)
+	assert.Equal(t, uint64(2), duration.DataPoints[0].Count)
+
+	assert.Contains(t, metrics, "controld.client.rate_limit_wait")
+}
+
+func TestSetupExportsToEndpoint(t *testing.T) {
+	var mu sync.Mutex
+	paths := map[string]int{}
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths[r.URL.Path]++
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer collector.Close()
+
+	p, err := Setup(context.Background(), Config{Endpoint: collector.URL, Version: "test"})
+	require.NoError(t, err)
+	opts, err := p.ClientOptions()
+	require.NoError(t, err)
+
+	srv := controldtest.NewServer(t)
+	_, err = srv.NewClient(t, opts...).ListProfiles(context.Background())
+	require.NoError(t, err)
+
+	require.NoError(t, p.Shutdown(context.Background()))
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 1, paths["/v1/traces"])
+	assert.GreaterOrEqual(t, paths["/v1/metrics"], 1)
+}
+
+func TestEnabled(t *testing.T) {
+	for _, key := range endpointEnv {
+		t.Setenv(key, "")
+	}
+	t.Setenv("OTEL_SDK_DISABLED", "")
+
+	assert.False(t, Enabled(""))
+	assert.True(t, Enabled("http://localhost:4318"))
+
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://collector:4318")
+	assert.True(t, Enabled(""))
+
+	t.Setenv("OTEL_SDK_DISABLED", "true")
+	assert.False(t, Enabled(""))
+	assert.True(t, Enabled("http://localhost:4318"))
+}
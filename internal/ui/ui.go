@@ -68,3 +68,22 @@ func (u *UI) Warn(msg string) {
 		fmt.Fprintf(os.Stderr, "! %s\n", msg)
 	}
 }
+
+// Status returns s colored by the state it names: green for healthy states
+// such as "active" and "enabled", yellow for "pending" and red for disabled
+// or broken ones. Other values are returned unchanged.
+func (u *UI) Status(s string) string {
+	if !u.useColor() {
+		return s
+	}
+	switch s {
+	case "active", "enabled", "valid":
+		return "\033[32m" + s + "\033[0m"
+	case "pending":
+		return "\033[33m" + s + "\033[0m"
+	case "disabled", "soft-disabled", "hard-disabled", "expired", "revoked", "invalid":
+		return "\033[31m" + s + "\033[0m"
+	default:
+		return s
+	}
+}
@@ -89,3 +89,13 @@ func TestOutputMethods(t *testing.T) {
 		u.Warn("test warning")
 	})
 }
+
+func TestStatus(t *testing.T) {
+	assert.Equal(t, "active", New("never").Status("active"))
+
+	u := New("always")
+	assert.Equal(t, "\033[32mactive\033[0m", u.Status("active"))
+	assert.Equal(t, "\033[33mpending\033[0m", u.Status("pending"))
+	assert.Equal(t, "\033[31msoft-disabled\033[0m", u.Status("soft-disabled"))
+	assert.Equal(t, "other", u.Status("other"))
+}